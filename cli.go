@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/llm"
+	"mumu-bot/internal/logger"
+	"mumu-bot/internal/memory"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+// cliCommands 命令行子命令
+var cliCommands = map[string]func(mgr *memory.Manager, args []string) error{
+	"export":  cmdExport,
+	"import":  cmdImport,
+	"reindex": cmdReindex,
+	"purge":   cmdPurge,
+}
+
+// reindexCheckpoint 向量重建的断点文件，保存已处理的最大记忆 ID
+const reindexCheckpoint = ".reindex-checkpoint"
+
+// runCLI 执行命令行子命令，返回进程退出码；不是子命令时返回 -1
+func runCLI(configPath string, args []string) int {
+	if len(args) == 0 {
+		return -1
+	}
+	if args[0] == "migrate" {
+		return runMigrate(configPath, args[1:])
+	}
+	handler, ok := cliCommands[args[0]]
+	if !ok {
+		fmt.Printf("未知命令: %s\n可用命令: export [文件], import <文件>, reindex [--reset], purge <群号>, migrate <up|down [步数]|status>\n", args[0])
+		return 2
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+	logger.Init(cfg.App.LogLevel, cfg.App.Debug)
+
+	embeddingClient, err := llm.NewEmbeddingClient(cfg)
+	if err != nil {
+		zap.L().Warn("Embedding 客户端创建失败，导入的记忆不会生成向量", zap.Error(err))
+		embeddingClient = nil
+	}
+	memoryMgr, err := memory.NewManager(cfg, embeddingClient)
+	if err != nil {
+		fmt.Printf("记忆管理器创建失败: %v\n", err)
+		return 1
+	}
+	defer memoryMgr.Close()
+
+	if err := handler(memoryMgr, args[1:]); err != nil {
+		fmt.Printf("%s 失败: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// cmdExport 导出人格资产到 JSON 文件
+func cmdExport(mgr *memory.Manager, args []string) error {
+	path := fmt.Sprintf("mumu-backup-%s.json", time.Now().Format("20060102-150405"))
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	backup, err := mgr.Export()
+	if err != nil {
+		return err
+	}
+	data, err := sonic.ConfigStd.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("已导出到 %s：记忆 %d 条，黑话 %d 条，表达方式 %d 条，用户档案 %d 条，成员画像 %d 条\n", path,
+		len(backup.Memories), len(backup.Jargons), len(backup.Expressions), len(backup.UserProfiles), len(backup.MemberProfiles))
+	return nil
+}
+
+// cmdImport 从 JSON 文件导入人格资产
+func cmdImport(mgr *memory.Manager, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定要导入的文件")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	// 定时备份生成的是 gzip 压缩文件
+	if strings.HasSuffix(args[0], ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("解压备份文件失败: %w", err)
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("解压备份文件失败: %w", err)
+		}
+	}
+	var backup memory.Backup
+	if err := sonic.Unmarshal(data, &backup); err != nil {
+		return fmt.Errorf("解析备份文件失败: %w", err)
+	}
+
+	result, err := mgr.Import(context.Background(), &backup)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("导入完成：记忆 %d 条（跳过重复 %d 条），黑话 %d 条，表达方式 %d 条，用户档案 %d 条，成员画像 %d 条\n",
+		result.Memories, result.Skipped, result.Jargons, result.Expressions, result.UserProfiles, result.MemberProfiles)
+	return nil
+}
+
+// cmdReindex 遍历全部记忆重新生成向量写入 Milvus
+// 中断后再次执行会从断点继续；--reset 会删除并重建集合后从头开始（更换 embedding 模型时使用）
+func cmdReindex(mgr *memory.Manager, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var fromID uint
+	if slices.Contains(args, "--reset") {
+		if err := mgr.ResetVectors(ctx); err != nil {
+			return err
+		}
+		_ = os.Remove(reindexCheckpoint)
+		fmt.Println("已重建向量集合")
+	} else if data, err := os.ReadFile(reindexCheckpoint); err == nil {
+		if id, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			fromID = uint(id)
+			fmt.Printf("从断点继续：记忆 ID > %d\n", fromID)
+		}
+	}
+
+	err := mgr.Reindex(ctx, fromID, 50, func(p memory.ReindexProgress) {
+		_ = os.WriteFile(reindexCheckpoint, []byte(strconv.FormatUint(uint64(p.LastID), 10)), 0o644)
+		fmt.Printf("\r进度 %d/%d（失败 %d，最后 ID %d）", p.Done, p.Total, p.Failed, p.LastID)
+	})
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("%w（已保存断点，再次执行 reindex 可继续）", err)
+	}
+	_ = os.Remove(reindexCheckpoint)
+	fmt.Println("向量重建完成")
+	return nil
+}
+
+// cmdPurge 删除某个群的全部数据，群必须已经从配置中移除且没有在运行时启用
+func cmdPurge(mgr *memory.Manager, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定群号")
+	}
+	groupID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("无效的群号: %s", args[0])
+	}
+	result, err := mgr.PurgeGroup(context.Background(), groupID)
+	if errors.Is(err, memory.ErrGroupStillEnabled) {
+		return fmt.Errorf("群 %d 仍在启用中（配置文件或运行时启用），请先移除或停用后再清理", groupID)
+	}
+	if err != nil {
+		return err
+	}
+	var total int64
+	for table, n := range result {
+		if n > 0 {
+			fmt.Printf("  %s: %d\n", table, n)
+		}
+		total += n
+	}
+	fmt.Printf("已清理群 %d 的数据，共 %d 行\n", groupID, total)
+	return nil
+}
+
+// runMigrate 执行数据库迁移子命令，不启动记忆管理器（避免自动迁移）
+func runMigrate(configPath string, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("用法: migrate <up|down [步数]|status>")
+		return 2
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+	logger.Init(cfg.App.LogLevel, cfg.App.Debug)
+
+	mg, err := memory.NewMigrator(cfg)
+	if err != nil {
+		fmt.Printf("连接数据库失败: %v\n", err)
+		return 1
+	}
+	defer mg.Close()
+
+	switch args[0] {
+	case "up":
+		n, err := mg.Up()
+		if err != nil {
+			fmt.Printf("migrate up 失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已执行 %d 个迁移\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Printf("无效的步数: %s\n", args[1])
+				return 2
+			}
+		}
+		n, err := mg.Down(steps)
+		if err != nil {
+			fmt.Printf("migrate down 失败（已回滚 %d 个）: %v\n", n, err)
+			return 1
+		}
+		fmt.Printf("已回滚 %d 个迁移\n", n)
+	case "status":
+		status, err := mg.Status()
+		if err != nil {
+			fmt.Printf("查询迁移状态失败: %v\n", err)
+			return 1
+		}
+		for _, st := range status {
+			state := "未执行"
+			if st.Applied {
+				state = "已执行 " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("  %03d_%-24s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		fmt.Printf("未知的迁移操作: %s\n", args[0])
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"mumu-bot/internal/memory"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	callbackutils "github.com/cloudwego/eino/utils/callbacks"
+	"go.uber.org/zap"
+)
+
+// 超预算后的处理方式
+const (
+	budgetActionReduce = "reduce" // 降低发言频率
+	budgetActionSwitch = "switch" // 切换到轻量模型
+)
+
+// usageCounter 一次思考中累计的 token 用量
+type usageCounter struct {
+	prompt     atomic.Int64
+	completion atomic.Int64
+	total      atomic.Int64
+}
+
+// handler 创建统计 ChatModel token 用量的回调
+func (c *usageCounter) handler() callbacks.Handler {
+	return callbackutils.NewHandlerHelper().ChatModel(&callbackutils.ModelCallbackHandler{
+		OnEnd: func(ctx context.Context, _ *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+			if output != nil && output.TokenUsage != nil {
+				c.prompt.Add(int64(output.TokenUsage.PromptTokens))
+				c.completion.Add(int64(output.TokenUsage.CompletionTokens))
+				c.total.Add(int64(output.TokenUsage.TotalTokens))
+			}
+			return ctx
+		},
+	}).Handler()
+}
+
+// recordUsage 保存一次思考的 token 用量
+func (a *Agent) recordUsage(groupID int64, modelName string, c *usageCounter) {
+	if c.total.Load() == 0 {
+		return
+	}
+	usage := &memory.TokenUsage{
+		GroupID:          groupID,
+		Model:            modelName,
+		PromptTokens:     c.prompt.Load(),
+		CompletionTokens: c.completion.Load(),
+		TotalTokens:      c.total.Load(),
+	}
+	if err := a.memory.RecordTokenUsage(usage); err != nil {
+		zap.L().Warn("记录 token 用量失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+}
+
+// recordResponseUsage 保存直接调用模型（不经过 ReAct）时响应中的 token 用量
+func (a *Agent) recordResponseUsage(groupID int64, modelName string, resp *schema.Message) {
+	if resp == nil || resp.ResponseMeta == nil || resp.ResponseMeta.Usage == nil {
+		return
+	}
+	c := &usageCounter{}
+	c.prompt.Add(int64(resp.ResponseMeta.Usage.PromptTokens))
+	c.completion.Add(int64(resp.ResponseMeta.Usage.CompletionTokens))
+	c.total.Add(int64(resp.ResponseMeta.Usage.TotalTokens))
+	a.recordUsage(groupID, modelName, c)
+}
+
+// overBudget 检查群或全局今日 token 用量是否已超出预算
+func (a *Agent) overBudget(groupID int64) bool {
+	cfg := a.cfg.Budget
+	if !cfg.Enabled {
+		return false
+	}
+	today := time.Now().Format(time.DateOnly)
+
+	if cfg.DailyTokens > 0 {
+		if used, err := a.memory.GetDailyTokenUsage(today, 0); err == nil && used >= cfg.DailyTokens {
+			return true
+		}
+	}
+
+	limit := cfg.GroupDailyTokens
+	if gc := a.cfg.GetGroupConfig(groupID); gc != nil && gc.DailyTokens > 0 {
+		limit = gc.DailyTokens
+	}
+	if limit > 0 {
+		if used, err := a.memory.GetDailyTokenUsage(today, groupID); err == nil && used >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// budgetSpeakFactor 超预算且处理方式为 reduce 时，发言概率的乘数
+func (a *Agent) budgetSpeakFactor(groupID int64) float64 {
+	if a.cfg.Budget.Action == budgetActionSwitch && a.lightReact != nil {
+		return 1
+	}
+	if !a.overBudget(groupID) {
+		return 1
+	}
+	factor := a.cfg.Budget.ReduceFactor
+	if factor <= 0 || factor > 1 {
+		factor = 0.3
+	}
+	return factor
+}
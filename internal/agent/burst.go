@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"time"
+)
+
+// sparseThinkDelay 零星消息时的思考延迟
+const sparseThinkDelay = time.Second
+
+// burstState 群内等待中的合并思考
+type burstState struct {
+	timer *time.Timer
+	first time.Time // 本轮第一条消息的时间
+}
+
+// scheduleBurstThink 收到新消息后安排一次合并思考
+// 消息速率高（突发）时等群里安静一会儿再思考，把一波消息合并成一次思考，但最多等待 max_wait；
+// 零星消息则很快思考，不必等思考周期
+func (a *Agent) scheduleBurstThink(groupID int64) {
+	cfg := a.cfg.Agent.Burst
+	quietGap := time.Duration(cfg.QuietGap) * time.Second
+	if quietGap <= 0 {
+		quietGap = 4 * time.Second
+	}
+	maxWait := time.Duration(cfg.MaxWait) * time.Second
+	if maxWait <= 0 {
+		maxWait = 20 * time.Second
+	}
+
+	delay := sparseThinkDelay
+	if a.isBursting(groupID) {
+		delay = quietGap
+	}
+
+	now := time.Now()
+	a.burstsMu.Lock()
+	defer a.burstsMu.Unlock()
+
+	st := a.bursts[groupID]
+	if st == nil {
+		st = &burstState{first: now}
+		st.timer = time.AfterFunc(delay, func() { a.fireBurstThink(groupID, st) })
+		a.bursts[groupID] = st
+		return
+	}
+	// 已有等待中的思考：在最长等待时间内推迟，合并新消息
+	if waited := now.Sub(st.first); waited < maxWait && st.timer.Stop() {
+		st.timer.Reset(min(delay, maxWait-waited))
+	}
+}
+
+// fireBurstThink 合并等待结束，执行一次思考判断
+func (a *Agent) fireBurstThink(groupID int64, st *burstState) {
+	defer recoverPanic("burstThink")
+	a.burstsMu.Lock()
+	if a.bursts[groupID] == st {
+		delete(a.bursts, groupID)
+	}
+	a.burstsMu.Unlock()
+
+	a.considerThinking(groupID, a.moodSpeakFactor())
+}
+
+// isBursting 判断群内最近是否处于消息突发状态
+func (a *Agent) isBursting(groupID int64) bool {
+	cfg := a.cfg.Agent.Burst
+	window := time.Duration(cfg.Window) * time.Second
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+
+	count := 0
+	for _, m := range a.getBuffer(groupID) {
+		if time.Since(m.Time) <= window {
+			count++
+		}
+	}
+	return count >= threshold
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/calendar"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// calendarInfo 生成某群当天的节日和群友生日描述，没有时返回空字符串
+func (a *Agent) calendarInfo(groupID int64, now time.Time) string {
+	var lines []string
+	if festivals := calendar.Festivals(now); len(festivals) > 0 {
+		lines = append(lines, "- 今天是"+strings.Join(festivals, "、"))
+	}
+
+	profiles, err := a.memory.GetBirthdayMembers(groupID, now.Format("01-02"))
+	if err != nil {
+		zap.L().Warn("获取群友生日失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+	for _, p := range profiles {
+		lines = append(lines, fmt.Sprintf("- 今天是 %s(%d) 的生日", p.Nickname, p.UserID))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// greetingLoop 节日/生日祝福定时任务：每天到点后对有特别日子的群触发一次思考
+func (a *Agent) greetingLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	greetingTime := a.cfg.Calendar.GreetingTime
+	if greetingTime == "" {
+		greetingTime = "09:00"
+	}
+	lastDate := ""
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			today := now.Format(time.DateOnly)
+			if lastDate == today || now.Format("15:04") < greetingTime {
+				continue
+			}
+			lastDate = today
+			a.sendGreetings(now)
+		}
+	}
+}
+
+// sendGreetings 对每个启用的群检查当天的特别日子并触发祝福
+func (a *Agent) sendGreetings(now time.Time) {
+	for _, gc := range a.cfg.GetGroups() {
+		if !gc.Enabled {
+			continue
+		}
+		info := a.calendarInfo(gc.GroupID, now)
+		if info == "" {
+			continue
+		}
+		zap.L().Info("触发节日/生日祝福", zap.Int64("group_id", gc.GroupID))
+		hint := fmt.Sprintf("注意：今天是特别的日子：\n%s\n你可以用自己的风格在群里说句祝福（过生日的群友可以在 mentions 中 @ TA），不想说也可以不说。", info)
+		go a.think(gc.GroupID, thinkTrigger{hint: hint, source: "calendar"})
+	}
+}
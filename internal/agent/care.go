@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// careLoop 主动私聊关心熟人的定时任务
+func (a *Agent) careLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	careTime := a.cfg.Chat.ProactivePrivate.Time
+	if careTime == "" {
+		careTime = "20:00"
+	}
+	lastDate := ""
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			today := now.Format(time.DateOnly)
+			if lastDate == today || now.Format("15:04") < careTime {
+				continue
+			}
+			lastDate = today
+			a.careForIntimates(now)
+		}
+	}
+}
+
+// careForIntimates 找出亲密度高但多天没发言的群友，主动私聊关心（每天数量有硬限制）
+func (a *Agent) careForIntimates(now time.Time) {
+	cfg := a.cfg.Chat.ProactivePrivate
+	minIntimacy := cfg.MinIntimacy
+	if minIntimacy <= 0 {
+		minIntimacy = 0.8
+	}
+	inactiveDays := cfg.InactiveDays
+	if inactiveDays <= 0 {
+		inactiveDays = 3
+	}
+	maxPerDay := cfg.MaxPerDay
+	if maxPerDay <= 0 {
+		maxPerDay = 1
+	}
+	interval := cfg.UserInterval
+	if interval <= 0 {
+		interval = 14
+	}
+
+	profiles, err := a.memory.GetInactiveIntimates(minIntimacy,
+		now.AddDate(0, 0, -inactiveDays), now.AddDate(0, 0, -interval), maxPerDay)
+	if err != nil {
+		zap.L().Warn("获取需要关心的群友失败", zap.Error(err))
+		return
+	}
+
+	selfID := a.bot.GetSelfID()
+	for i := range profiles {
+		if profiles[i].UserID == selfID {
+			continue
+		}
+		if err := a.careFor(&profiles[i], now); err != nil {
+			zap.L().Warn("主动私聊失败", zap.Int64("user_id", profiles[i].UserID), zap.Error(err))
+		}
+	}
+}
+
+// careFor 基于画像和相关记忆生成一条私聊消息并发送
+func (a *Agent) careFor(profile *memory.GlobalUserProfile, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var memLines []string
+	if mems, err := a.memory.GetUserMemories(ctx, profile.UserID, 5); err == nil {
+		for _, m := range mems {
+			memLines = append(memLines, "- "+m.Content)
+		}
+	}
+	memText := "（没有特别的记忆）"
+	if len(memLines) > 0 {
+		memText = strings.Join(memLines, "\n")
+	}
+
+	days := int(now.Sub(profile.LastSpeak).Hours() / 24)
+	prompt := fmt.Sprintf(`你的群友 %s 已经 %d 天没在群里说话了，你们关系不错（亲密度 %.2f）。
+TA 的说话风格：%s
+TA 的兴趣：%s
+你记得的关于 TA 的事：
+%s
+
+你想私聊 TA 关心一下。写一句自然、简短的私聊消息（30字以内），像朋友随口问候，不要提"亲密度""记忆"等字眼，不要太肉麻，不要用 markdown。
+如果你觉得现在不适合打扰 TA，只输出 SKIP。直接输出消息内容。`,
+		profile.Nickname, days, profile.Intimacy, profile.SpeakStyle, profile.Interests, memText)
+
+	resp, err := a.model.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(a.persona.GetSystemPrompt()),
+		schema.UserMessage(prompt),
+	})
+	if err != nil {
+		return err
+	}
+	a.recordResponseUsage(0, a.cfg.LLM.Model, resp)
+
+	content := strings.TrimSpace(resp.Content)
+	if content == "" || strings.HasPrefix(strings.ToUpper(content), "SKIP") {
+		return a.memory.MarkMemberCared(profile.UserID, now)
+	}
+
+	// 私聊同样经过内容过滤
+	res := a.speakFilter.Apply(0, content)
+	if res.Blocked {
+		return fmt.Errorf("私聊内容被过滤规则拦截")
+	}
+	if _, err := a.bot.SendPrivateMessage(profile.UserID, res.Content); err != nil {
+		return err
+	}
+	if err := a.memory.MarkMemberCared(profile.UserID, now); err != nil {
+		return err
+	}
+	zap.L().Named("audit").Info("主动私聊关心群友",
+		zap.Int64("user_id", profile.UserID),
+		zap.String("nickname", profile.Nickname),
+		zap.String("content", res.Content))
+	return nil
+}
+
+// doSpeakPrivate 在群聊中决定私聊某个群友，内容同样经过内容过滤并记审计日志
+func (a *Agent) doSpeakPrivate(groupID, userID int64, content string) (int64, error) {
+	res := a.speakFilter.Apply(groupID, content)
+	if res.Blocked {
+		return 0, fmt.Errorf("这句话包含不适合发送的内容（命中规则「%s」），换个说法", res.Hits[len(res.Hits)-1].Rule)
+	}
+
+	msgID, err := a.bot.SendPrivateMessage(userID, res.Content)
+	if err != nil {
+		zap.L().Error("发送私聊失败", zap.Int64("user_id", userID), zap.Error(err))
+		return 0, fmt.Errorf("发送失败: %w", err)
+	}
+	zap.L().Named("audit").Info("私聊群友",
+		zap.Int64("group_id", groupID),
+		zap.Int64("user_id", userID),
+		zap.String("content", res.Content))
+	return msgID, nil
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// commandHandler 群内命令处理函数，返回回复内容
+type commandHandler func(a *Agent, msg *onebot.GroupMessage, args string) string
+
+// commandInfo 命令定义
+type commandInfo struct {
+	usage     string
+	desc      string
+	handler   commandHandler
+	adminOnly bool // 仅限 command.admins 中的QQ号使用
+	anyGroup  bool // 在未启用的群中也可使用
+}
+
+// commands 已注册的群内命令（在 init 中注册，避免与 cmdHelp 形成初始化循环）
+var commands map[string]commandInfo
+
+func init() {
+	commands = map[string]commandInfo{
+		"mute":   {usage: "mute [分钟]", desc: "暂停发言（不填时长则一直暂停）", handler: cmdMute},
+		"unmute": {usage: "unmute", desc: "恢复发言", handler: cmdUnmute},
+		"status": {usage: "status", desc: "查看当前状态", handler: cmdStatus},
+		"forget": {usage: "forget 关键词", desc: "删除本群包含关键词的记忆", handler: cmdForget},
+		"help":   {usage: "help", desc: "查看命令列表", handler: cmdHelp},
+		"enable": {usage: "enable", desc: "在本群启用（仅限超级管理员）", handler: cmdEnable, adminOnly: true, anyGroup: true},
+	}
+}
+
+// handleCommand 处理群内管理命令
+// 返回 true 表示消息是命令且已处理，不再进入对话上下文
+func (a *Agent) handleCommand(msg *onebot.GroupMessage) bool {
+	cfg := a.cfg.Command
+	if !cfg.Enabled || msg.UserID == a.bot.GetSelfID() {
+		return false
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "#"
+	}
+
+	text := strings.TrimSpace(msg.Content)
+	if !strings.HasPrefix(text, prefix) {
+		return false
+	}
+	name, args, _ := strings.Cut(strings.TrimPrefix(text, prefix), " ")
+	cmd, ok := commands[strings.ToLower(name)]
+	if !ok || !a.isCommandAdmin(msg) {
+		return false
+	}
+	if !cmd.anyGroup && !a.cfg.IsGroupEnabled(msg.GroupID) {
+		return false
+	}
+	if cmd.adminOnly && !slices.Contains(a.cfg.Command.Admins, msg.UserID) {
+		return false
+	}
+
+	reply := cmd.handler(a, msg, strings.TrimSpace(args))
+	zap.L().Info("执行群内命令", zap.Int64("group_id", msg.GroupID), zap.Int64("user_id", msg.UserID), zap.String("command", name), zap.String("args", args))
+	if reply != "" {
+		if _, err := a.bot.SendGroupMessage(msg.GroupID, reply, msg.MessageID, nil); err != nil {
+			zap.L().Warn("命令回复失败", zap.Int64("group_id", msg.GroupID), zap.Error(err))
+		}
+	}
+	return true
+}
+
+// isCommandAdmin 检查发送者是否有权限使用命令
+func (a *Agent) isCommandAdmin(msg *onebot.GroupMessage) bool {
+	if msg.SenderRole == "owner" {
+		return true
+	}
+	if a.cfg.Command.AllowGroupAdmin && msg.SenderRole == "admin" {
+		return true
+	}
+	return slices.Contains(a.cfg.Command.Admins, msg.UserID)
+}
+
+// ==================== 命令实现 ====================
+
+func cmdMute(a *Agent, msg *onebot.GroupMessage, args string) string {
+	minutes, _ := strconv.Atoi(args)
+	a.PauseFor(msg.GroupID, time.Duration(minutes)*time.Minute)
+	if minutes > 0 {
+		return fmt.Sprintf("好，我闭嘴 %d 分钟", minutes)
+	}
+	return "好，我先不说话了"
+}
+
+func cmdUnmute(a *Agent, msg *onebot.GroupMessage, _ string) string {
+	a.Resume(msg.GroupID)
+	return "我回来了"
+}
+
+func cmdStatus(a *Agent, msg *onebot.GroupMessage, _ string) string {
+	var lines []string
+
+	state := "正常"
+	if a.IsPaused(msg.GroupID) {
+		state = "暂停中"
+	} else if a.bot.IsSelfMuted(msg.GroupID) {
+		state = "被禁言"
+	}
+	a.processingMu.RLock()
+	thinking := a.processing[msg.GroupID]
+	lastTime := a.lastProcessedTime[msg.GroupID]
+	a.processingMu.RUnlock()
+	if thinking {
+		state += "（思考中）"
+	}
+	lines = append(lines, "状态: "+state)
+	lines = append(lines, fmt.Sprintf("人格: %s", a.personaFor(msg.GroupID).GetName()))
+	lines = append(lines, fmt.Sprintf("发言概率: %.2f（情绪系数 %.2f）", a.getSpeakProbability(msg.GroupID), a.moodSpeakFactor()))
+	lines = append(lines, fmt.Sprintf("发言冷却: %s", a.EffectiveCooldown(msg.GroupID).Round(time.Second)))
+	lines = append(lines, fmt.Sprintf("缓冲消息: %d", len(a.getBuffer(msg.GroupID))))
+	if !lastTime.IsZero() {
+		lines = append(lines, "上次思考: "+lastTime.Format(time.DateTime))
+	}
+	if mood, err := a.memory.GetMoodState(); err == nil {
+		lines = append(lines, fmt.Sprintf("情绪: 心情=%.2f 精力=%.2f 社交意愿=%.2f", mood.Valence, mood.Energy, mood.Sociability))
+	}
+	stats := a.memory.GetStats()
+	lines = append(lines, fmt.Sprintf("记忆: %d 条，黑话: %d 条，表达: %d 条", stats["memories"], stats["jargons"], stats["expressions"]))
+	return strings.Join(lines, "\n")
+}
+
+func cmdForget(a *Agent, msg *onebot.GroupMessage, args string) string {
+	if args == "" {
+		return "要忘掉什么？用法: forget 关键词"
+	}
+	n, err := a.memory.ForgetMemories(context.Background(), msg.GroupID, args)
+	if err != nil {
+		zap.L().Warn("删除记忆失败", zap.Int64("group_id", msg.GroupID), zap.Error(err))
+		return "删除失败了"
+	}
+	if n == 0 {
+		return "没有相关的记忆"
+	}
+	return fmt.Sprintf("已忘掉 %d 条相关记忆", n)
+}
+
+func cmdHelp(a *Agent, _ *onebot.GroupMessage, _ string) string {
+	prefix := a.cfg.Command.Prefix
+	if prefix == "" {
+		prefix = "#"
+	}
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s%s - %s", prefix, commands[name].usage, commands[name].desc))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cmdEnable(a *Agent, msg *onebot.GroupMessage, _ string) string {
+	if err := a.EnableGroup(msg.GroupID); err != nil {
+		return "本群已经启用啦"
+	}
+	return "收到，以后我也在这个群玩啦"
+}
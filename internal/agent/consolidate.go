@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"mumu-bot/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// consolidateMemoryTypes 参与巩固的记忆类型（话题摘要和日记不合并）
+var consolidateMemoryTypes = []memory.MemoryType{
+	memory.MemoryTypeGroupFact,
+	memory.MemoryTypeConversation,
+	memory.MemoryTypeSelfExperience,
+}
+
+// consolidateResult LLM 输出的单条合并结果
+type consolidateResult struct {
+	IDs        []uint  `json:"ids"`
+	Content    string  `json:"content"`
+	Importance float64 `json:"importance"`
+}
+
+// consolidateLoop 记忆巩固定时任务
+func (a *Agent) consolidateLoop() {
+	defer a.wg.Done()
+	interval := a.cfg.Memory.Consolidation.IntervalHours
+	if interval <= 0 {
+		interval = 24
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			for _, gc := range a.cfg.GetGroups() {
+				if !gc.Enabled {
+					continue
+				}
+				for _, memType := range consolidateMemoryTypes {
+					if err := a.consolidateMemories(gc.GroupID, memType); err != nil {
+						zap.L().Warn("记忆巩固失败", zap.Int64("group_id", gc.GroupID), zap.String("type", string(memType)), zap.Error(err))
+					}
+				}
+			}
+		}
+	}
+}
+
+// consolidateMemories 把群内同一主题的碎片记忆用 LLM 合并成一条，旧记录软删除
+func (a *Agent) consolidateMemories(groupID int64, memType memory.MemoryType) error {
+	cfg := a.cfg.Memory.Consolidation
+	minMemories := cfg.MinMemories
+	if minMemories <= 0 {
+		minMemories = 20
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+	ctx = memory.WithPersona(ctx, a.personaKey(groupID))
+
+	mems, err := a.memory.ListConsolidationCandidates(ctx, groupID, memType, batchSize)
+	if err != nil {
+		return err
+	}
+	if len(mems) < minMemories {
+		return nil
+	}
+
+	var list strings.Builder
+	byID := make(map[uint]*memory.Memory, len(mems))
+	for i := range mems {
+		list.WriteString(fmt.Sprintf("#%d %s\n", mems[i].ID, mems[i].Content))
+		byID[mems[i].ID] = &mems[i]
+	}
+
+	prompt := fmt.Sprintf(`下面是你的一批长期记忆，#后面的数字是记忆ID。请找出描述同一件事、同一个人的同一方面或同一主题的记忆，把每组合并成一条完整、不丢失信息的记忆。
+只合并确实重复或高度相关的记忆，不相关的不要强行合并；有矛盾时以较新的（ID 较大的）为准。
+只输出 JSON 数组，不要输出其他内容，没有需要合并的就输出 []，格式：
+[{"ids":[被合并的记忆ID，至少两个],"content":"合并后的记忆（100字以内）","importance":合并后的重要性0-1}]
+
+%s`, list.String())
+
+	summaryModel, modelName := a.model, a.cfg.LLM.Model
+	if a.lightModel != nil {
+		summaryModel, modelName = a.lightModel, a.cfg.LightLLM.Model
+	}
+	resp, err := summaryModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return err
+	}
+	a.recordResponseUsage(groupID, modelName, resp)
+
+	var results []consolidateResult
+	if err := sonic.UnmarshalString(utils.ExtractJSON(resp.Content), &results); err != nil {
+		return fmt.Errorf("解析巩固结果失败: %w", err)
+	}
+
+	merged, removed := 0, 0
+	used := make(map[uint]bool)
+	for _, r := range results {
+		content := strings.TrimSpace(r.Content)
+		if content == "" {
+			continue
+		}
+		// 只接受本批次内、未被其他组合并过的记忆
+		var sources []*memory.Memory
+		for _, id := range r.IDs {
+			if m, ok := byID[id]; ok && !used[id] {
+				sources = append(sources, m)
+				used[id] = true
+			}
+		}
+		if len(sources) < 2 {
+			continue
+		}
+
+		mem := mergeMemoryFields(sources, content, r.Importance)
+		ids := make([]uint, 0, len(sources))
+		for _, m := range sources {
+			ids = append(ids, m.ID)
+		}
+		if err := a.memory.ReplaceMemories(ctx, ids, mem); err != nil {
+			zap.L().Warn("合并记忆失败", zap.Int64("group_id", groupID), zap.Error(err))
+			continue
+		}
+		merged++
+		removed += len(ids)
+	}
+
+	if merged > 0 {
+		zap.L().Info("记忆巩固完成", zap.Int64("group_id", groupID), zap.String("type", string(memType)),
+			zap.Int("merged", merged), zap.Int("removed", removed))
+	}
+	return nil
+}
+
+// mergeMemoryFields 根据被合并的记忆生成新记忆：重要性不低于原记忆，访问次数累加，同一用户的记忆保留用户
+func mergeMemoryFields(sources []*memory.Memory, content string, importance float64) *memory.Memory {
+	first := sources[0]
+	mem := &memory.Memory{
+		Type:       first.Type,
+		GroupID:    first.GroupID,
+		UserID:     first.UserID,
+		Persona:    first.Persona,
+		Content:    content,
+		Importance: min(max(importance, 0), 1),
+	}
+	var msgIDs []string
+	for _, m := range sources {
+		mem.Importance = max(mem.Importance, m.Importance)
+		mem.AccessCount += m.AccessCount
+		if m.UserID != mem.UserID {
+			mem.UserID = 0
+		}
+		if m.SourceMsgID != "" {
+			msgIDs = append(msgIDs, strings.Split(m.SourceMsgID, ",")...)
+		}
+	}
+	// 合并来源消息，超出字段长度时丢弃多余的
+	seen := make(map[string]bool, len(msgIDs))
+	for _, id := range msgIDs {
+		if seen[id] || len(mem.SourceMsgID)+len(id)+1 > 255 {
+			continue
+		}
+		seen[id] = true
+		if mem.SourceMsgID != "" {
+			mem.SourceMsgID += ","
+		}
+		mem.SourceMsgID += id
+	}
+	return mem
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"time"
+)
+
+// EffectiveCooldown 获取群当前有效的发言冷却时间
+// 自适应模式下按最近消息密度调整：冷却 = 基础冷却 × clamp(参考密度 / 实际密度, 下限, 上限)
+func (a *Agent) EffectiveCooldown(groupID int64) time.Duration {
+	cfg := a.cfg.SpeakCooldown()
+	if cfg.Base <= 0 {
+		return 0
+	}
+	base := time.Duration(cfg.Base) * time.Second
+	if !cfg.Adaptive {
+		return base
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = 300
+	}
+	refDensity := cfg.RefDensity
+	if refDensity <= 0 {
+		refDensity = 2
+	}
+	minFactor := cfg.MinFactor
+	if minFactor <= 0 {
+		minFactor = 0.3
+	}
+	maxFactor := cfg.MaxFactor
+	if maxFactor < minFactor {
+		maxFactor = max(3, minFactor)
+	}
+
+	// 消息密度（条/分钟）
+	count := a.memory.CountMessagesSince(groupID, time.Now().Add(-time.Duration(window)*time.Second))
+	density := float64(count) / (float64(window) / 60)
+
+	factor := maxFactor
+	if density > 0 {
+		factor = min(max(refDensity/density, minFactor), maxFactor)
+	}
+	return time.Duration(float64(base) * factor)
+}
+
+// inCooldown 检查群是否处于发言冷却中
+func (a *Agent) inCooldown(groupID int64) bool {
+	a.lastSpeakMu.RLock()
+	last := a.lastSpeakTime[groupID]
+	a.lastSpeakMu.RUnlock()
+	if last.IsZero() {
+		return false
+	}
+	return time.Since(last) < a.EffectiveCooldown(groupID)
+}
+
+// markSpoke 记录群内最后一次发言时间
+func (a *Agent) markSpoke(groupID int64) {
+	a.lastSpeakMu.Lock()
+	a.lastSpeakTime[groupID] = time.Now()
+	a.lastSpeakMu.Unlock()
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"mumu-bot/internal/events"
+	"mumu-bot/internal/memory"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/tool"
+	callbackutils "github.com/cloudwego/eino/utils/callbacks"
+	"go.uber.org/zap"
+)
+
+// 思考的最终动作
+const (
+	decisionSpeak        = "speak"         // 发言了
+	decisionQuiet        = "quiet"         // 主动保持沉默
+	decisionNone         = "none"          // 没有发言也没有调用 stayQuiet
+	decisionPaused       = "paused"        // 暂停或被禁言，未思考
+	decisionPrejudgeSkip = "prejudge_skip" // 预判无需发言
+	decisionTimeout      = "timeout"       // 思考超时
+	decisionInterrupted  = "interrupted"   // 被新的 @ 打断
+	decisionError        = "error"         // 思考失败
+)
+
+// decisionTrace 一次思考中的工具调用序列
+type decisionTrace struct {
+	mu    sync.Mutex
+	tools []string
+}
+
+// handler 创建记录工具调用的回调
+func (t *decisionTrace) handler() callbacks.Handler {
+	return callbackutils.NewHandlerHelper().Tool(&callbackutils.ToolCallbackHandler{
+		OnStart: func(ctx context.Context, info *callbacks.RunInfo, _ *tool.CallbackInput) context.Context {
+			if info != nil {
+				t.mu.Lock()
+				t.tools = append(t.tools, info.Name)
+				t.mu.Unlock()
+			}
+			return ctx
+		},
+	}).Handler()
+}
+
+// toolNames 获取工具调用序列
+func (t *decisionTrace) toolNames() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.tools)
+}
+
+// action 根据工具调用序列推断最终动作
+func (t *decisionTrace) action() string {
+	names := t.toolNames()
+	switch {
+	case slices.Contains(names, "speak") || slices.Contains(names, "sendSticker"):
+		return decisionSpeak
+	case slices.Contains(names, "stayQuiet"):
+		return decisionQuiet
+	default:
+		return decisionNone
+	}
+}
+
+// recordDecision 推送一次思考的决策事件，并保存决策记录（未开启 debug.decision_log 时不保存）
+func (a *Agent) recordDecision(groupID int64, trig thinkTrigger, action string, trace *decisionTrace, modelName string, start time.Time, err error) {
+	log := &memory.DecisionLog{
+		GroupID:    groupID,
+		Trigger:    trig.reason(),
+		SpeakProb:  trig.prob,
+		Action:     action,
+		Model:      modelName,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if trace != nil {
+		log.Tools = strings.Join(trace.toolNames(), ",")
+	}
+	if err != nil {
+		log.Error = err.Error()
+	}
+	a.events.Publish(events.TypeDecision, groupID, log)
+	if !a.cfg.Debug.DecisionLog {
+		return
+	}
+	if err := a.memory.SaveDecisionLog(log); err != nil {
+		zap.L().Warn("保存决策记录失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+}
+
+// decisionCleanupLoop 定期清理过期的决策记录
+func (a *Agent) decisionCleanupLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	days := a.cfg.Debug.DecisionLogDays
+	if days <= 0 {
+		days = 7
+	}
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			n, err := a.memory.CleanupDecisionLogs(now.AddDate(0, 0, -days))
+			if err != nil {
+				zap.L().Warn("清理决策记录失败", zap.Error(err))
+			} else if n > 0 {
+				zap.L().Info("已清理过期决策记录", zap.Int64("count", n))
+			}
+		}
+	}
+}
+
+// toolCallCleanupLoop 定期清理过期的工具调用记录
+func (a *Agent) toolCallCleanupLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	days := a.cfg.Debug.ToolCallLogDays
+	if days <= 0 {
+		days = 30
+	}
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			n, err := a.memory.CleanupToolCallLogs(now.AddDate(0, 0, -days))
+			if err != nil {
+				zap.L().Warn("清理工具调用记录失败", zap.Error(err))
+			} else if n > 0 {
+				zap.L().Info("已清理过期工具调用记录", zap.Int64("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// diaryLoop 每日日记定时任务
+func (a *Agent) diaryLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	diaryTime := a.cfg.Memory.Diary.Time
+	if diaryTime == "" {
+		diaryTime = "23:30"
+	}
+	lastDate := ""
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			today := now.Format(time.DateOnly)
+			if lastDate == today || now.Format("15:04") < diaryTime {
+				continue
+			}
+			lastDate = today
+			a.writeDiaries(now)
+		}
+	}
+}
+
+// writeDiaries 为每个启用的群写当天的日记
+func (a *Agent) writeDiaries(now time.Time) {
+	for _, gc := range a.cfg.GetGroups() {
+		if !gc.Enabled {
+			continue
+		}
+		if err := a.writeDiary(gc.GroupID, now); err != nil {
+			zap.L().Warn("写日记失败", zap.Int64("group_id", gc.GroupID), zap.Error(err))
+		}
+	}
+}
+
+// writeDiary 回顾当天的群聊和自己的发言，生成一篇日记保存为 self_experience 记忆
+func (a *Agent) writeDiary(groupID int64, now time.Time) error {
+	maxMessages := a.cfg.Memory.Diary.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = 300
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	msgs := a.memory.GetMessagesSince(groupID, startOfDay, maxMessages)
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	selfID := a.bot.GetSelfID()
+	var chat strings.Builder
+	selfCount := 0
+	for _, m := range msgs {
+		// 消息日志的 Content 已经是格式化后的消息行
+		chat.WriteString(m.Content)
+		if m.UserID == selfID {
+			selfCount++
+		}
+	}
+
+	p := a.personaFor(groupID)
+	prompt := fmt.Sprintf(`今天是 %s，一天快结束了。下面是今天群里的聊天记录（QQ号为 %d 的是你自己，你今天发了 %d 条消息）。
+请以第一人称写一篇简短的日记（200字以内），记录今天群里发生的值得记住的事、你参与了什么、对哪些群友有了新的印象、你的心情和感受。
+用你自己的说话风格，不要流水账，不要用 markdown，直接输出日记正文。
+
+%s`, now.Format(time.DateOnly), selfID, selfCount, chat.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	resp, err := a.model.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(p.GetSystemPrompt()),
+		schema.UserMessage(prompt),
+	})
+	if err != nil {
+		return err
+	}
+	a.recordResponseUsage(groupID, a.cfg.LLM.Model, resp)
+
+	content := strings.TrimSpace(resp.Content)
+	if content == "" {
+		return nil
+	}
+
+	mem := &memory.Memory{
+		Type:       memory.MemoryTypeSelfExperience,
+		GroupID:    groupID,
+		Content:    fmt.Sprintf("%s %s】%s", memory.DiaryPrefix, now.Format(time.DateOnly), content),
+		Importance: 0.7,
+	}
+	if _, err := a.memory.SaveMemory(memory.WithPersona(ctx, a.personaKey(groupID)), mem); err != nil {
+		return err
+	}
+	zap.L().Info("日记已保存", zap.Int64("group_id", groupID), zap.Int("messages", len(msgs)))
+	return nil
+}
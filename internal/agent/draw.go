@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// doDrawImage 根据提示词生成图片发到群里，提示词经过内容过滤
+func (a *Agent) doDrawImage(groupID int64, prompt string) (int64, error) {
+	res := a.speakFilter.Apply(groupID, prompt)
+	if res.Blocked {
+		return 0, fmt.Errorf("提示词包含不适合的内容（命中规则「%s」），换个主题", res.Hits[len(res.Hits)-1].Rule)
+	}
+	prompt = res.Content
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+	img, err := a.painter.Generate(ctx, prompt)
+	if err != nil {
+		zap.L().Warn("图片生成失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return 0, fmt.Errorf("图片生成失败了")
+	}
+
+	msgID, err := a.bot.SendImageData(groupID, img)
+	if err != nil {
+		zap.L().Error("发送生成的图片失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return 0, fmt.Errorf("发送失败: %w", err)
+	}
+	a.markSpoke(groupID)
+
+	// 回写自己的消息，记下画了什么
+	a.onMessage(&onebot.GroupMessage{
+		MessageID:   msgID,
+		GroupID:     groupID,
+		UserID:      a.bot.GetSelfID(),
+		Nickname:    a.personaFor(groupID).GetName(),
+		Content:     fmt.Sprintf("[图片:自己画的 %s]", prompt),
+		Time:        time.Now(),
+		MessageType: "group",
+	})
+	zap.L().Info("发送生成的图片", zap.Int64("group_id", groupID), zap.String("prompt", prompt))
+	return msgID, nil
+}
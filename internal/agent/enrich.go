@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"mumu-bot/internal/utils"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+)
+
+// memoryEnrichResult LLM 输出的记忆摘要和关键词
+type memoryEnrichResult struct {
+	Summary  string   `json:"summary"`
+	Keywords []string `json:"keywords"`
+}
+
+// EnrichMemory 用 LLM 为记忆生成一句话摘要和检索关键词，优先使用轻量模型
+func (a *Agent) EnrichMemory(ctx context.Context, mem *memory.Memory) (string, []string, error) {
+	if a.overBudget(mem.GroupID) {
+		return "", nil, fmt.Errorf("已超出 token 预算")
+	}
+
+	prompt := fmt.Sprintf(`为下面这条记忆生成一句话摘要（30字以内）和 3-6 个检索关键词（人名、事物、话题等名词，不要虚词）。
+只输出 JSON，不要输出其他内容，格式：{"summary":"摘要","keywords":["关键词"]}
+
+%s`, mem.Content)
+
+	enrichModel, modelName := a.model, a.cfg.LLM.Model
+	if a.lightModel != nil {
+		enrichModel, modelName = a.lightModel, a.cfg.LightLLM.Model
+	}
+	resp, err := enrichModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return "", nil, err
+	}
+	a.recordResponseUsage(mem.GroupID, modelName, resp)
+
+	var result memoryEnrichResult
+	if err := sonic.UnmarshalString(utils.ExtractJSON(resp.Content), &result); err != nil {
+		return "", nil, fmt.Errorf("解析记忆摘要失败: %w", err)
+	}
+	return result.Summary, result.Keywords, nil
+}
+
+// doSummarizeContent 用 LLM 总结长消息或合并转发的内容，优先使用轻量模型
+func (a *Agent) doSummarizeContent(ctx context.Context, groupID int64, content, focus string) (string, error) {
+	if a.overBudget(groupID) {
+		return "", fmt.Errorf("已超出 token 预算")
+	}
+
+	prompt := `用简洁的中文总结下面的内容（200字以内），保留关键信息：谁说了什么、结论或要点。只输出总结本身。`
+	if focus != "" {
+		prompt += "\n重点关注：" + focus
+	}
+	prompt += "\n\n" + content
+
+	summaryModel, modelName := a.model, a.cfg.LLM.Model
+	if a.lightModel != nil {
+		summaryModel, modelName = a.lightModel, a.cfg.LightLLM.Model
+	}
+	resp, err := summaryModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return "", err
+	}
+	a.recordResponseUsage(groupID, modelName, resp)
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/onebot"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultMessageFormat 默认消息行模板
+const defaultMessageFormat = `[{{.Time}}] {{if .ShowID}}#{{.MessageID}} {{end}}{{.Nickname}}({{.UserID}}):{{.Reply}} {{.Content}}`
+
+// messageLine 消息行模板数据
+type messageLine struct {
+	Time      string // 按 time_format 格式化后的时间
+	MessageID int64
+	ShowID    bool // 是否显示消息 ID
+	Nickname  string
+	UserID    int64
+	Reply     string // 回复信息，如 ` [回复 #123 昵称:"内容"]`，没有回复时为空
+	Content   string // 消息内容（已包含图片、表情等描述）
+}
+
+// messageFormatter 聊天上下文消息行格式化器
+type messageFormatter struct {
+	tmpl       *template.Template
+	timeFormat string
+	showID     bool
+}
+
+// newMessageFormatter 根据配置创建格式化器
+func newMessageFormatter(cfg config.ChatContextConfig) (*messageFormatter, error) {
+	format := cfg.Format
+	if format == "" {
+		format = defaultMessageFormat
+	}
+	tmpl, err := template.New("message").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("消息行模板无效: %w", err)
+	}
+
+	f := &messageFormatter{
+		tmpl:       tmpl,
+		timeFormat: cfg.TimeFormat,
+		showID:     true,
+	}
+	if f.timeFormat == "" {
+		f.timeFormat = "15:04:05"
+	}
+	if cfg.ShowMessageID != nil {
+		f.showID = *cfg.ShowMessageID
+	}
+	return f, nil
+}
+
+// format 格式化一条消息，返回以换行结尾的消息行
+func (f *messageFormatter) format(msg *onebot.GroupMessage, reply, content string) string {
+	data := messageLine{
+		Time:      msg.Time.Format(f.timeFormat),
+		MessageID: msg.MessageID,
+		ShowID:    f.showID,
+		Nickname:  msg.Nickname,
+		UserID:    msg.UserID,
+		Reply:     reply,
+		Content:   content,
+	}
+
+	var b strings.Builder
+	if err := f.tmpl.Execute(&b, data); err != nil {
+		// 模板执行失败时回退到默认格式，保证上下文不丢消息
+		return fmt.Sprintf("[%s] #%d %s(%d):%s %s\n",
+			msg.Time.Format(time.TimeOnly), msg.MessageID, msg.Nickname, msg.UserID, reply, content)
+	}
+	line := b.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	return line
+}
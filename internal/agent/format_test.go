@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/onebot"
+	"testing"
+	"time"
+)
+
+func testMessage() *onebot.GroupMessage {
+	return &onebot.GroupMessage{
+		MessageID: 123,
+		UserID:    10001,
+		Nickname:  "小明",
+		Time:      time.Date(2024, 5, 1, 8, 30, 15, 0, time.Local),
+	}
+}
+
+func TestMessageFormatter(t *testing.T) {
+	hide := false
+	cases := []struct {
+		name    string
+		cfg     config.ChatContextConfig
+		reply   string
+		content string
+		want    string
+	}{
+		{
+			name:    "默认模板",
+			content: "早上好",
+			want:    "[08:30:15] #123 小明(10001): 早上好\n",
+		},
+		{
+			name:    "默认模板带回复",
+			reply:   ` [回复 #100 小红:"在吗"]`,
+			content: "在的",
+			want:    "[08:30:15] #123 小明(10001): [回复 #100 小红:\"在吗\"] 在的\n",
+		},
+		{
+			name:    "自定义模板",
+			cfg:     config.ChatContextConfig{Format: "{{.Nickname}}说：{{.Content}}"},
+			content: "早上好",
+			want:    "小明说：早上好\n",
+		},
+		{
+			name:    "自定义时间格式",
+			cfg:     config.ChatContextConfig{TimeFormat: "01-02 15:04"},
+			content: "早上好",
+			want:    "[05-01 08:30] #123 小明(10001): 早上好\n",
+		},
+		{
+			name:    "不显示消息ID",
+			cfg:     config.ChatContextConfig{ShowMessageID: &hide},
+			content: "早上好",
+			want:    "[08:30:15] 小明(10001): 早上好\n",
+		},
+		{
+			name:    "模板执行失败时回退到默认格式",
+			cfg:     config.ChatContextConfig{Format: "{{.NoSuchField}}"},
+			content: "早上好",
+			want:    "[08:30:15] #123 小明(10001): 早上好\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := newMessageFormatter(tc.cfg)
+			if err != nil {
+				t.Fatalf("创建格式化器失败: %v", err)
+			}
+			if got := f.format(testMessage(), tc.reply, tc.content); got != tc.want {
+				t.Errorf("format() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMessageFormatterInvalidTemplate(t *testing.T) {
+	if _, err := newMessageFormatter(config.ChatContextConfig{Format: "{{.Content"}); err == nil {
+		t.Fatal("模板无法解析时应该返回错误")
+	}
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// doSendForward 把多条消息打包成合并转发发到群里，每条内容都经过内容过滤
+// 没有指定发送者的节点以自己的身份显示
+func (a *Agent) doSendForward(groupID int64, nodes []onebot.ForwardMessage) (int64, error) {
+	selfID, selfName := a.bot.GetSelfID(), a.personaFor(groupID).GetName()
+	now := time.Now()
+	for i := range nodes {
+		res := a.speakFilter.Apply(groupID, nodes[i].Content)
+		if res.Blocked {
+			return 0, fmt.Errorf("第 %d 条包含不适合发送的内容（命中规则「%s」），换个说法", i+1, res.Hits[len(res.Hits)-1].Rule)
+		}
+		nodes[i].Content = res.Content
+		if nodes[i].UserID == 0 {
+			nodes[i].UserID, nodes[i].Nickname = selfID, selfName
+		}
+		if nodes[i].Nickname == "" {
+			nodes[i].Nickname = fmt.Sprintf("%d", nodes[i].UserID)
+		}
+		nodes[i].Time = now
+	}
+
+	msgID, err := a.bot.SendGroupForwardMsg(groupID, nodes)
+	if err != nil {
+		zap.L().Error("发送合并转发失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return 0, fmt.Errorf("发送失败: %w", err)
+	}
+	a.markSpoke(groupID)
+
+	// 回写自己的消息，保留转发内容
+	a.onMessage(&onebot.GroupMessage{
+		MessageID:   msgID,
+		GroupID:     groupID,
+		UserID:      selfID,
+		Nickname:    selfName,
+		Content:     fmt.Sprintf("[合并转发，共%d条]", len(nodes)),
+		Time:        now,
+		MessageType: "group",
+		Forwards:    nodes,
+	})
+	zap.L().Info("发送合并转发", zap.Int64("group_id", groupID), zap.Int("count", len(nodes)))
+	return msgID, nil
+}
+
+// doForwardToGroup 把来源群的一条消息原样转发到目标群，两个群都要在跨群转发白名单内
+func (a *Agent) doForwardToGroup(fromGroupID, toGroupID, messageID int64) error {
+	groups := a.cfg.Chat.GroupForward.Groups
+	if fromGroupID == toGroupID {
+		return fmt.Errorf("不能转发到当前群")
+	}
+	if !slices.Contains(groups, fromGroupID) || !slices.Contains(groups, toGroupID) {
+		return fmt.Errorf("群 %d 不在可转发的名单里", toGroupID)
+	}
+	if gc := a.cfg.GetGroupConfig(toGroupID); gc == nil || !gc.Enabled {
+		return fmt.Errorf("目标群没有启用")
+	}
+	if a.IsPaused(toGroupID) || a.bot.IsSelfMuted(toGroupID) {
+		return fmt.Errorf("现在不方便在目标群发消息")
+	}
+
+	// 只能转发当前群的消息
+	inGroup := false
+	if log, err := a.memory.GetMessageLogByID(strconv.FormatInt(messageID, 10)); err == nil {
+		inGroup = log.GroupID == fromGroupID
+	} else if hm, err := a.bot.GetMessageDetail(messageID); err == nil {
+		inGroup = hm.GroupID == fromGroupID
+	}
+	if !inGroup {
+		return fmt.Errorf("找不到这条消息（可能太久远或不是本群的消息）")
+	}
+
+	if err := a.bot.ForwardGroupSingleMsg(toGroupID, messageID); err != nil {
+		zap.L().Error("跨群转发失败", zap.Int64("from", fromGroupID), zap.Int64("to", toGroupID), zap.Error(err))
+		return fmt.Errorf("转发失败: %w", err)
+	}
+	a.markSpoke(toGroupID)
+	zap.L().Info("跨群转发消息", zap.Int64("from", fromGroupID), zap.Int64("to", toGroupID), zap.Int64("message_id", messageID))
+	return nil
+}
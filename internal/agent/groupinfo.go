@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/memory"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxPromptNoticeLen 注入提示词的群公告最大长度
+const maxPromptNoticeLen = 150
+
+// groupInfoLoop 启动时和定期刷新已启用群的基础信息（群名、人数、群公告）
+func (a *Agent) groupInfoLoop() {
+	defer a.wg.Done()
+	interval := a.cfg.Agent.GroupInfoRefresh
+	if interval <= 0 {
+		interval = 6
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Hour)
+	defer ticker.Stop()
+
+	a.refreshGroupInfos()
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			a.refreshGroupInfos()
+		}
+	}
+}
+
+// refreshGroupInfos 从 OneBot 拉取各个启用群的基础信息并保存
+func (a *Agent) refreshGroupInfos() {
+	for _, gc := range a.cfg.GetGroups() {
+		if !gc.Enabled {
+			continue
+		}
+		info, err := a.memory.GetGroupInfo(gc.GroupID)
+		if err != nil {
+			info = &memory.GroupInfo{GroupID: gc.GroupID}
+		}
+
+		gi, err := a.bot.GetGroupInfo(gc.GroupID, true)
+		if err != nil {
+			zap.L().Debug("刷新群信息失败", zap.Int64("group_id", gc.GroupID), zap.Error(err))
+			continue
+		}
+		info.GroupName = gi.GroupName
+		info.MemberCount = gi.MemberCount
+		if notices, err := a.bot.GetGroupNotice(gc.GroupID); err == nil && len(notices) > 0 {
+			notice := []rune(notices[0].Content)
+			if len(notice) > maxOnboardNoticeLen {
+				notice = notice[:maxOnboardNoticeLen]
+			}
+			info.Notice = string(notice)
+		}
+		if err := a.memory.SaveGroupInfo(info); err != nil {
+			zap.L().Warn("保存群信息失败", zap.Int64("group_id", gc.GroupID), zap.Error(err))
+		}
+	}
+}
+
+// groupInfoPrompt 生成注入提示词的群基础信息
+func (a *Agent) groupInfoPrompt(groupID int64) string {
+	info, err := a.memory.GetGroupInfo(groupID)
+	if err != nil {
+		return ""
+	}
+
+	var lines []string
+	if info.GroupName != "" {
+		lines = append(lines, fmt.Sprintf("群名: %s（%d 人）", info.GroupName, info.MemberCount))
+	}
+	if info.Atmosphere != "" {
+		lines = append(lines, "群氛围: "+info.Atmosphere)
+	}
+	if info.HotTopics != "" {
+		lines = append(lines, "最近的热点话题: "+info.HotTopics)
+	}
+	if info.Rules != "" {
+		lines = append(lines, "群规/约定: "+info.Rules)
+	}
+	if info.Notice != "" {
+		notice := []rune(info.Notice)
+		if len(notice) > maxPromptNoticeLen {
+			notice = append(notice[:maxPromptNoticeLen], []rune("…")...)
+		}
+		lines = append(lines, "群公告: "+string(notice))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// triggerManual 通过 API 手动触发的思考来源
+const triggerManual = "manual"
+
+// ErrGroupNotEnabled 群未启用
+var ErrGroupNotEnabled = errors.New("群未启用")
+
+// ThinkNow 立即对群执行一次思考（调试用），不等定时周期、不经过发言概率和预判；
+// skipCooldown 为 true 时忽略主动发言冷却。思考在后台进行，结果见决策记录
+func (a *Agent) ThinkNow(groupID int64, skipCooldown bool) error {
+	if !a.cfg.IsGroupEnabled(groupID) {
+		return ErrGroupNotEnabled
+	}
+	if a.IsPaused(groupID) || a.bot.IsSelfMuted(groupID) {
+		return fmt.Errorf("群已暂停或沐沐被禁言")
+	}
+	if !skipCooldown && a.inCooldown(groupID) {
+		return fmt.Errorf("发言冷却中（%.0f 秒），可以带上 force=skip_cooldown", a.EffectiveCooldown(groupID).Seconds())
+	}
+	a.processingMu.RLock()
+	busy := a.processing[groupID]
+	a.processingMu.RUnlock()
+	if busy {
+		return fmt.Errorf("正在思考中")
+	}
+
+	zap.L().Info("手动触发思考", zap.Int64("group_id", groupID), zap.Bool("skip_cooldown", skipCooldown))
+	go a.think(groupID, thinkTrigger{source: triggerManual})
+	return nil
+}
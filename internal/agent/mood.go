@@ -0,0 +1,26 @@
+package agent
+
+// moodSpeakFactor 根据当前情绪计算发言概率的乘数
+// 社交意愿和精力处于中间值（0.5）时为 1，社交意愿低、精力低时衰减
+func (a *Agent) moodSpeakFactor() float64 {
+	cfg := a.cfg.Chat.MoodFactor
+	if !cfg.Enabled {
+		return 1
+	}
+	mood, err := a.memory.GetMoodState()
+	if err != nil {
+		return 1
+	}
+
+	minFactor := cfg.Min
+	if minFactor <= 0 {
+		minFactor = 0.3
+	}
+	maxFactor := cfg.Max
+	if maxFactor < minFactor {
+		maxFactor = max(1.5, minFactor)
+	}
+
+	willing := 0.7*mood.Sociability + 0.3*mood.Energy
+	return min(max(1+1.2*(willing-0.5), minFactor), maxFactor)
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxOnboardNoticeLen 初次加入时保存的群公告最大长度
+const maxOnboardNoticeLen = 500
+
+// ErrGroupAlreadyEnabled 群已经启用
+var ErrGroupAlreadyEnabled = errors.New("群已经启用")
+
+// discoverGroup 记录收到消息的未启用群（每个群每次运行只记录一次）
+func (a *Agent) discoverGroup(groupID int64) {
+	a.discoveredMu.Lock()
+	if a.discovered[groupID] {
+		a.discoveredMu.Unlock()
+		return
+	}
+	a.discovered[groupID] = true
+	a.discoveredMu.Unlock()
+
+	if err := a.memory.RecordDiscoveredGroup(groupID); err != nil {
+		zap.L().Warn("记录新发现的群失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return
+	}
+	zap.L().Info("发现未启用的群", zap.Int64("group_id", groupID))
+}
+
+// restoreEnabledGroups 启动时恢复之前通过 API/命令启用的群
+func (a *Agent) restoreEnabledGroups() {
+	infos, err := a.memory.ListEnabledGroupInfos()
+	if err != nil {
+		zap.L().Warn("加载已启用的群失败", zap.Error(err))
+		return
+	}
+	for _, info := range infos {
+		if a.cfg.EnableGroup(info.GroupID) {
+			zap.L().Info("恢复运行时启用的群", zap.Int64("group_id", info.GroupID), zap.String("name", info.GroupName))
+		}
+	}
+}
+
+// EnableGroup 启用群并执行一次初次加入流程
+func (a *Agent) EnableGroup(groupID int64) error {
+	if !a.cfg.EnableGroup(groupID) {
+		return fmt.Errorf("%w: %d", ErrGroupAlreadyEnabled, groupID)
+	}
+
+	info, err := a.memory.GetGroupInfo(groupID)
+	if err != nil {
+		info = &memory.GroupInfo{GroupID: groupID}
+	}
+	info.Enabled = true
+	if err := a.memory.SaveGroupInfo(info); err != nil {
+		zap.L().Warn("保存群启用状态失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+
+	zap.L().Info("已启用群", zap.Int64("group_id", groupID))
+	go a.onboardGroup(groupID)
+	return nil
+}
+
+// DisableGroup 运行时停用群。运行时启用的群停用后重启不会再恢复；配置文件中启用的群重启后仍按配置启用
+func (a *Agent) DisableGroup(groupID int64) error {
+	if !a.cfg.DisableGroup(groupID) {
+		return fmt.Errorf("群 %d 没有启用", groupID)
+	}
+
+	if info, err := a.memory.GetGroupInfo(groupID); err == nil && info.Enabled {
+		info.Enabled = false
+		if err := a.memory.SaveGroupInfo(info); err != nil {
+			zap.L().Warn("保存群停用状态失败", zap.Int64("group_id", groupID), zap.Error(err))
+		}
+	}
+	zap.L().Info("已停用群", zap.Int64("group_id", groupID))
+	return nil
+}
+
+// onboardGroup 初次加入流程：读群公告、拉成员列表、建立群信息，然后观察一下群里
+func (a *Agent) onboardGroup(groupID int64) {
+	info, err := a.memory.GetGroupInfo(groupID)
+	if err != nil {
+		info = &memory.GroupInfo{GroupID: groupID, Enabled: true}
+	}
+
+	if gi, err := a.bot.GetGroupInfo(groupID, true); err == nil {
+		info.GroupName = gi.GroupName
+		info.MemberCount = gi.MemberCount
+	} else {
+		zap.L().Warn("获取群信息失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+
+	// 最新的群公告同时记为群事实
+	if notices, err := a.bot.GetGroupNotice(groupID); err == nil && len(notices) > 0 {
+		notice := []rune(notices[0].Content)
+		if len(notice) > maxOnboardNoticeLen {
+			notice = notice[:maxOnboardNoticeLen]
+		}
+		info.Notice = string(notice)
+		mem := &memory.Memory{
+			Type:       memory.MemoryTypeGroupFact,
+			GroupID:    groupID,
+			Content:    "群公告：" + info.Notice,
+			Importance: 0.7,
+		}
+		ctx := memory.WithPersona(context.Background(), a.personaKey(groupID))
+		if _, err := a.memory.SaveMemory(ctx, mem); err != nil {
+			zap.L().Warn("保存群公告失败", zap.Int64("group_id", groupID), zap.Error(err))
+		}
+	}
+
+	// 为群成员建立画像
+	members, err := a.bot.GetGroupMemberList(groupID, true)
+	if err != nil {
+		zap.L().Warn("获取群成员列表失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+	for _, m := range members {
+		nickname := m.Card
+		if nickname == "" {
+			nickname = m.Nickname
+		}
+		if _, err := a.memory.GetOrCreateMemberProfile(groupID, m.UserID, nickname); err != nil {
+			zap.L().Debug("创建成员画像失败", zap.Int64("user_id", m.UserID), zap.Error(err))
+		}
+	}
+
+	now := time.Now()
+	info.OnboardedAt = &now
+	if err := a.memory.SaveGroupInfo(info); err != nil {
+		zap.L().Warn("保存群信息失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+	zap.L().Info("初次加入流程完成", zap.Int64("group_id", groupID), zap.String("name", info.GroupName), zap.Int("members", len(members)))
+
+	hint := fmt.Sprintf("注意：你刚开始在「%s」这个群里活跃，对这里还不熟悉。先看看大家在聊什么，可以简单打个招呼，也可以先不说话。", info.GroupName)
+	a.think(groupID, thinkTrigger{hint: hint, source: "onboard"})
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Pause 暂停群内发言，直到调用 Resume
+// 暂停期间消息仍会记录入库，但不会触发思考
+func (a *Agent) Pause(groupID int64) {
+	a.PauseFor(groupID, 0)
+}
+
+// PauseFor 暂停群内发言一段时间，d <= 0 表示一直暂停
+func (a *Agent) PauseFor(groupID int64, d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	a.pausedMu.Lock()
+	a.paused[groupID] = until
+	a.pausedMu.Unlock()
+	zap.L().Info("群已暂停", zap.Int64("group_id", groupID), zap.Duration("duration", d))
+}
+
+// Resume 恢复群内发言
+func (a *Agent) Resume(groupID int64) {
+	a.pausedMu.Lock()
+	_, ok := a.paused[groupID]
+	delete(a.paused, groupID)
+	a.pausedMu.Unlock()
+	if ok {
+		zap.L().Info("群已恢复", zap.Int64("group_id", groupID))
+	}
+}
+
+// IsPaused 检查群是否处于暂停状态（到期自动恢复）
+func (a *Agent) IsPaused(groupID int64) bool {
+	a.pausedMu.RLock()
+	until, ok := a.paused[groupID]
+	a.pausedMu.RUnlock()
+	if !ok {
+		return false
+	}
+	if !until.IsZero() && time.Now().After(until) {
+		a.Resume(groupID)
+		return false
+	}
+	return true
+}
+
+// PausedGroups 获取所有暂停中的群
+func (a *Agent) PausedGroups() []int64 {
+	a.pausedMu.RLock()
+	groups := make([]int64, 0, len(a.paused))
+	for groupID := range a.paused {
+		groups = append(groups, groupID)
+	}
+	a.pausedMu.RUnlock()
+
+	// 过滤已到期的
+	groups = slices.DeleteFunc(groups, func(groupID int64) bool { return !a.IsPaused(groupID) })
+	slices.Sort(groups)
+	return groups
+}
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"mumu-bot/internal/tools"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+// pollCheckInterval 投票截止检查间隔
+const pollCheckInterval = 30 * time.Second
+
+// maxOpenPolls 每个群同时进行的投票上限
+// 文字票只发编号，无法区分投给哪个投票，所以同一时间只允许一个
+const maxOpenPolls = 1
+
+// pollEmojis 投票选项对应的表情回应，按选项顺序分配
+var pollEmojis = []struct {
+	ID   int
+	Name string
+}{
+	{76, "赞"},
+	{66, "爱心"},
+	{63, "玫瑰"},
+	{124, "OK"},
+	{179, "doge"},
+	{144, "喝彩"},
+}
+
+// pollLoop 投票截止调度：到点后统计并公布结果
+func (a *Agent) pollLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(pollCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			polls, err := a.memory.GetDuePolls(now)
+			if err != nil {
+				zap.L().Warn("获取到期投票失败", zap.Error(err))
+				continue
+			}
+			for i := range polls {
+				if _, err := a.doClosePoll(&polls[i]); err != nil {
+					zap.L().Warn("结束投票失败", zap.Uint("id", polls[i].ID), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// doCreatePoll 发起投票：发送投票消息，贴上各选项的表情方便群友跟着点
+func (a *Agent) doCreatePoll(groupID int64, question string, options []string, duration time.Duration) (*memory.Poll, error) {
+	if len(options) < 2 || len(options) > len(pollEmojis) {
+		return nil, fmt.Errorf("选项需要 2~%d 个", len(pollEmojis))
+	}
+	if open, err := a.memory.GetOpenPolls(groupID); err == nil && len(open) >= maxOpenPolls {
+		return nil, fmt.Errorf("「%s」还在投票中，先结束它", open[0].Question)
+	}
+
+	closeAt := time.Now().Add(duration)
+	opts := make([]memory.PollOption, len(options))
+	var b strings.Builder
+	b.WriteString("【投票】" + question + "\n")
+	for i, text := range options {
+		opts[i] = memory.PollOption{Text: text, EmojiID: pollEmojis[i].ID}
+		b.WriteString(fmt.Sprintf("%d. %s（贴[%s]）\n", i+1, text, pollEmojis[i].Name))
+	}
+	b.WriteString(fmt.Sprintf("贴对应的表情或者直接发编号投票，%s 截止", closeAt.Format("01-02 15:04")))
+
+	msgID, err := a.doSpeak(groupID, b.String(), 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range opts {
+		if err := a.bot.SetMsgEmojiLike(msgID, opts[i].EmojiID); err == nil {
+			opts[i].Seeded = true
+		}
+	}
+
+	data, _ := sonic.MarshalString(opts)
+	poll := &memory.Poll{
+		GroupID:   groupID,
+		MessageID: msgID,
+		Question:  question,
+		Options:   data,
+		CloseAt:   closeAt,
+	}
+	if err := a.memory.CreatePoll(poll); err != nil {
+		return nil, fmt.Errorf("保存投票失败: %w", err)
+	}
+	zap.L().Info("发起投票", zap.Int64("group_id", groupID), zap.Uint("id", poll.ID), zap.String("question", question))
+	return poll, nil
+}
+
+// tallyPoll 统计票数：表情回应数（减去阿沐自己贴的）加上发送编号或选项原文的文字票（每人只算最后一次）
+// 表情回应接口只返回每个表情的数量，拿不到是谁贴的，因此同一个人贴多个表情或者又发了文字票都会重复计票
+func (a *Agent) tallyPoll(p *memory.Poll) ([]memory.PollOption, []int) {
+	var opts []memory.PollOption
+	if err := sonic.UnmarshalString(p.Options, &opts); err != nil {
+		return nil, nil
+	}
+	counts := make([]int, len(opts))
+
+	if reactions, err := a.bot.GetMessageReactions(p.MessageID); err == nil {
+		for _, r := range reactions {
+			for i, o := range opts {
+				if o.EmojiID != r.EmojiID {
+					continue
+				}
+				n := r.Count
+				if o.Seeded {
+					n--
+				}
+				counts[i] += max(n, 0)
+			}
+		}
+	}
+
+	selfID := a.bot.GetSelfID()
+	votes := make(map[int64]int)
+	for _, msg := range a.memory.GetMessagesSince(p.GroupID, p.CreatedAt, 500) {
+		if msg.UserID == selfID || msg.CreatedAt.After(p.CloseAt) {
+			continue
+		}
+		content := strings.TrimSpace(msg.Content)
+		if n, err := strconv.Atoi(content); err == nil && n >= 1 && n <= len(opts) {
+			votes[msg.UserID] = n - 1
+			continue
+		}
+		for i, o := range opts {
+			if content == o.Text {
+				votes[msg.UserID] = i
+				break
+			}
+		}
+	}
+	for _, i := range votes {
+		counts[i]++
+	}
+	return opts, counts
+}
+
+// doClosePoll 结束投票并在群里公布结果，返回结果文本
+func (a *Agent) doClosePoll(p *memory.Poll) (string, error) {
+	opts, counts := a.tallyPoll(p)
+	if opts == nil {
+		if _, err := a.memory.ClosePoll(p.ID, ""); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("投票选项解析失败")
+	}
+
+	total, best := 0, 0
+	for _, c := range counts {
+		total += c
+		best = max(best, c)
+	}
+	var b strings.Builder
+	b.WriteString("【投票结果】" + p.Question + "\n")
+	var winners []string
+	for i, o := range opts {
+		b.WriteString(fmt.Sprintf("%d. %s：%d 票\n", i+1, o.Text, counts[i]))
+		if best > 0 && counts[i] == best {
+			winners = append(winners, o.Text)
+		}
+	}
+	switch {
+	case total == 0:
+		b.WriteString("没有人投票")
+	case len(winners) == 1:
+		b.WriteString(fmt.Sprintf("共 %d 票，「%s」胜出", total, winners[0]))
+	default:
+		b.WriteString(fmt.Sprintf("共 %d 票，「%s」平票", total, strings.Join(winners, "」「")))
+	}
+	result := b.String()
+
+	closed, err := a.memory.ClosePoll(p.ID, result)
+	if err != nil {
+		return "", err
+	}
+	if !closed {
+		return "", fmt.Errorf("投票已经结束了")
+	}
+	zap.L().Info("投票结束", zap.Int64("group_id", p.GroupID), zap.Uint("id", p.ID), zap.Int("total", total))
+
+	if gc := a.cfg.GetGroupConfig(p.GroupID); gc == nil || !gc.Enabled {
+		return result, nil
+	}
+	// 投票消息太旧时会降级为普通发言，不算失败
+	if _, err := a.doSpeak(p.GroupID, result, p.MessageID, nil); err != nil && !errors.Is(err, tools.ErrReplyTargetInvalid) {
+		return result, fmt.Errorf("公布结果失败: %w", err)
+	}
+	return result, nil
+}
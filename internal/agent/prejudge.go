@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// 预判模式
+const (
+	prejudgeModeRule  = "rule"  // 仅规则
+	prejudgeModeModel = "model" // 轻量模型（未配置轻量模型时回退到规则）
+)
+
+// prejudge 预判是否可能需要发言，只有返回 true 才进入完整 ReAct 流程
+// 被 @ 时不做预判
+func (a *Agent) prejudge(ctx context.Context, groupID int64, since time.Time) bool {
+	cfg := a.cfg.Agent.Prejudge
+	if !cfg.Enabled {
+		return true
+	}
+
+	msgs := a.getBuffer(groupID)
+	var fresh []*onebot.GroupMessage
+	for _, m := range msgs {
+		if !m.Time.Before(since) && m.UserID != a.bot.GetSelfID() {
+			fresh = append(fresh, m)
+		}
+	}
+	if len(fresh) == 0 {
+		return false
+	}
+
+	if cfg.Mode == prejudgeModeModel && a.lightModel != nil {
+		ok, err := a.prejudgeByModel(ctx, groupID, msgs)
+		if err == nil {
+			return ok
+		}
+		zap.L().Warn("模型预判失败，回退到规则预判", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+	return a.prejudgeByRule(groupID, fresh)
+}
+
+// prejudgeByRule 规则预判：提到兴趣话题、回复了自己、提问等情况认为可能发言
+func (a *Agent) prejudgeByRule(groupID int64, fresh []*onebot.GroupMessage) bool {
+	p := a.personaFor(groupID)
+	selfID := a.bot.GetSelfID()
+	for _, m := range fresh {
+		if m.Reply != nil && m.Reply.SenderID == selfID {
+			return true
+		}
+		if p.IsInterested(m.Content) {
+			return true
+		}
+		if strings.ContainsAny(m.Content, "?？") || strings.HasSuffix(strings.TrimSpace(m.Content), "吗") {
+			return true
+		}
+	}
+
+	// 群里很热闹时也给一次机会
+	return len(fresh) >= 5
+}
+
+// prejudgeByModel 轻量模型预判
+func (a *Agent) prejudgeByModel(ctx context.Context, groupID int64, msgs []*onebot.GroupMessage) (bool, error) {
+	p := a.personaFor(groupID)
+
+	var chat strings.Builder
+	for _, m := range msgs {
+		chat.WriteString(m.FinalContent)
+	}
+	prompt := fmt.Sprintf(`你是QQ群友%s，感兴趣的话题：%s。
+下面是群里最近的对话，判断你现在有没有可能想插一句话（被搭话、聊到感兴趣的话题、有人提问、气氛适合接话等）。
+只回答 YES 或 NO，不要输出其他内容。
+
+%s`, p.GetName(), strings.Join(p.GetInterests(), "、"), chat.String())
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	resp, err := a.lightModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return false, err
+	}
+
+	a.recordResponseUsage(groupID, a.cfg.LightLLM.Model, resp)
+
+	answer := strings.ToUpper(strings.TrimSpace(resp.Content))
+	if a.cfg.Debug.ShowThinking {
+		zap.L().Debug("模型预判结果", zap.Int64("group_id", groupID), zap.String("answer", answer))
+	}
+	return strings.HasPrefix(answer, "YES"), nil
+}
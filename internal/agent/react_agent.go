@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"math/rand"
 	"mumu-bot/internal/config"
+	"mumu-bot/internal/events"
+	"mumu-bot/internal/filter"
+	"mumu-bot/internal/game"
 	"mumu-bot/internal/llm"
 	"mumu-bot/internal/mcp"
 	"mumu-bot/internal/memory"
@@ -13,7 +16,10 @@ import (
 	"mumu-bot/internal/persona"
 	"mumu-bot/internal/tools"
 	"mumu-bot/internal/utils"
+	"mumu-bot/internal/weather"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -22,22 +28,46 @@ import (
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
+	einoagent "github.com/cloudwego/eino/flow/agent"
 	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
 	"go.uber.org/zap"
 )
 
+// errThinkInterrupted 思考被新的 @ 消息打断
+var errThinkInterrupted = errors.New("思考被新消息打断")
+
+// replyTargetMaxAge 可回复消息的最大时长，更早的消息视为过旧
+const replyTargetMaxAge = 24 * time.Hour
+
 // Agent 沐沐智能体
 type Agent struct {
-	cfg     *config.Config
-	persona *persona.Persona
-	memory  *memory.Manager
-	model   model.ToolCallingChatModel
-	vision  *llm.VisionClient // 多模态视觉模型
-	bot     *onebot.Client
-	react   *react.Agent
-	tools   []tool.BaseTool
-	mcpMgr  *mcp.Manager // MCP 管理器
+	cfg          *config.Config
+	persona      *persona.Persona            // 默认人格
+	personas     map[string]*persona.Persona // 具名人格（按名称索引）
+	memory       *memory.Manager
+	model        model.ToolCallingChatModel
+	vision       *llm.VisionClient // 多模态视觉模型
+	tts          *llm.TTSClient    // 语音合成，未启用时为 nil
+	painter      *llm.ImageClient  // 图片生成，未启用时为 nil
+	weather      *weather.Client   // 天气查询，未启用时为 nil
+	bot          *onebot.Client
+	react        *react.Agent
+	tools        []tool.BaseTool
+	toolTimeouts *tools.ToolTimeouts // 工具执行超时与慢工具统计
+	mcpMgr       *mcp.Manager        // MCP 管理器
+	games        *game.Manager       // 小游戏管理器
+
+	speakFilter *filter.Pipeline  // 发言内容过滤管线
+	formatter   *messageFormatter // 聊天上下文消息行格式
+
+	lightModel model.ToolCallingChatModel // 轻量模型（可选）
+	lightReact *react.Agent               // 使用轻量模型的 ReAct（超预算降级时使用）
+
+	groupReacts   map[groupReactKey]*groupReact // 配置了工具黑白名单的群各自的 ReAct
+	groupReactsMu sync.Mutex
+
+	events *events.Bus // 实时事件（新消息、发言、工具调用、决策），供 /ws 推送
 
 	// 消息缓冲（使用 ring buffer 避免扩容缩容开销）
 	buffers   map[int64]*utils.RingBuffer[*onebot.GroupMessage]
@@ -46,8 +76,33 @@ type Agent struct {
 	// 正在处理中的群组（防止重复思考）和最后处理时间
 	processing        map[int64]bool
 	lastProcessedTime map[int64]time.Time
+	pendingMention    map[int64]bool                    // 思考期间又被 @，待当前思考结束后重新思考
+	thinkCancel       map[int64]context.CancelCauseFunc // 当前思考的取消函数（用于被新消息打断）
 	processingMu      sync.RWMutex
 
+	// 全局思考并发限制（被 @ 的群优先）
+	thinkSem *utils.PrioritySemaphore
+
+	// 暂停中的群及恢复时间（零值表示一直暂停）
+	paused   map[int64]time.Time
+	pausedMu sync.RWMutex
+
+	// 已记录的未启用群
+	discovered   map[int64]bool
+	discoveredMu sync.Mutex
+
+	// 等待中的合并思考（突发检测）
+	bursts   map[int64]*burstState
+	burstsMu sync.Mutex
+
+	// 最后发言时间（用于发言冷却）
+	lastSpeakTime map[int64]time.Time
+	lastSpeakMu   sync.RWMutex
+
+	// 最近自己的发言（用于防复读）
+	recentSpeaks   map[int64]*utils.RingBuffer[string]
+	recentSpeaksMu sync.Mutex
+
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
@@ -68,12 +123,93 @@ func New(
 		model:             m,
 		vision:            vision,
 		bot:               bot,
+		games:             game.NewManager(mem),
 		buffers:           make(map[int64]*utils.RingBuffer[*onebot.GroupMessage]),
+		groupReacts:       make(map[groupReactKey]*groupReact),
+		events:            events.NewBus(),
 		processing:        make(map[int64]bool),
 		lastProcessedTime: make(map[int64]time.Time),
+		pendingMention:    make(map[int64]bool),
+		thinkCancel:       make(map[int64]context.CancelCauseFunc),
+		thinkSem:          utils.NewPrioritySemaphore(cfg.Agent.MaxConcurrentThinks),
+		recentSpeaks:      make(map[int64]*utils.RingBuffer[string]),
+		paused:            make(map[int64]time.Time),
+		lastSpeakTime:     make(map[int64]time.Time),
+		discovered:        make(map[int64]bool),
+		bursts:            make(map[int64]*burstState),
 		stopCh:            make(chan struct{}),
 	}
 
+	// 加载具名人格
+	a.personas = make(map[string]*persona.Persona, len(cfg.Personas))
+	for i := range cfg.Personas {
+		pc := &cfg.Personas[i]
+		if pc.QQ == "" {
+			pc.QQ = cfg.Persona.QQ
+		}
+		a.personas[pc.Name] = persona.NewPersona(pc)
+	}
+	for _, gc := range cfg.GetGroups() {
+		if gc.Persona != "" && a.personas[gc.Persona] == nil {
+			zap.L().Warn("群配置的人格不存在，使用默认人格", zap.Int64("group_id", gc.GroupID), zap.String("persona", gc.Persona))
+		}
+	}
+
+	// 初始化轻量模型
+	if cfg.LightLLM.Model != "" {
+		lm, err := llm.NewChatModel(&cfg.LightLLM)
+		if err != nil {
+			zap.L().Warn("轻量模型创建失败", zap.Error(err))
+		} else {
+			a.lightModel = lm
+			zap.L().Info("轻量模型已启用", zap.String("model", cfg.LightLLM.Model))
+		}
+	}
+
+	// 初始化语音合成
+	tts, err := llm.NewTTSClient(&cfg.TTS)
+	if err != nil {
+		zap.L().Warn("语音合成客户端创建失败", zap.Error(err))
+	} else if tts != nil {
+		a.tts = tts
+		zap.L().Info("语音合成已启用", zap.String("provider", cfg.TTS.Provider))
+	}
+
+	// 初始化图片生成
+	painter, err := llm.NewImageClient(&cfg.Image.Draw)
+	if err != nil {
+		zap.L().Warn("图片生成客户端创建失败", zap.Error(err))
+	} else if painter != nil {
+		a.painter = painter
+		zap.L().Info("图片生成已启用", zap.String("provider", cfg.Image.Draw.Provider))
+	}
+
+	// 初始化天气查询
+	wc, err := weather.NewClient(&cfg.Weather)
+	if err != nil {
+		zap.L().Warn("天气查询客户端创建失败", zap.Error(err))
+	} else if wc != nil {
+		a.weather = wc
+		zap.L().Info("天气查询已启用", zap.String("provider", cfg.Weather.Provider))
+	}
+
+	// 保存记忆后异步生成摘要和关键词
+	if cfg.Memory.LongTerm.Enrich {
+		mem.SetEnricher(a)
+	}
+
+	formatter, err := newMessageFormatter(cfg.Agent.ChatContext)
+	if err != nil {
+		return nil, err
+	}
+	a.formatter = formatter
+
+	speakFilter, err := filter.NewPipeline(&cfg.Filter)
+	if err != nil {
+		return nil, err
+	}
+	a.speakFilter = speakFilter
+
 	// 初始化 MCP 管理器
 	a.mcpMgr = mcp.NewMCPManager()
 	if err := a.mcpMgr.LoadFromConfig("config/mcp.json"); err != nil {
@@ -93,33 +229,59 @@ func (a *Agent) initTools() error {
 	toolBuilders := []func() (tool.BaseTool, error){
 		// 记忆相关
 		func() (tool.BaseTool, error) { return tools.NewSaveMemoryTool() },
+		func() (tool.BaseTool, error) { return tools.NewEditMemoryTool() },
+		func() (tool.BaseTool, error) { return tools.NewDeleteMemoryTool() },
 		func() (tool.BaseTool, error) { return tools.NewQueryMemoryTool() },
+		func() (tool.BaseTool, error) { return tools.NewGetRecentDiariesTool() },
 		func() (tool.BaseTool, error) { return tools.NewSaveJargonTool() },
 		func() (tool.BaseTool, error) { return tools.NewSearchJargonTool() },
 		func() (tool.BaseTool, error) { return tools.NewUpdateMemberProfileTool() },
 		func() (tool.BaseTool, error) { return tools.NewGetMemberInfoTool() },
+		func() (tool.BaseTool, error) { return tools.NewFindMemberByNameTool() },
 		func() (tool.BaseTool, error) { return tools.NewGetRecentMessagesTool() },
+		func() (tool.BaseTool, error) { return tools.NewGetGroupHistoryTool() },
+		func() (tool.BaseTool, error) { return tools.NewGetMessageDetailTool() },
+		func() (tool.BaseTool, error) { return tools.NewSummarizeContentTool() },
 		func() (tool.BaseTool, error) { return tools.NewSearchExpressionsTool() },
 		func() (tool.BaseTool, error) { return tools.NewSaveExpressionTool() },
 		// 审核工具
 		func() (tool.BaseTool, error) { return tools.NewGetUncheckedExpressionsTool() },
 		func() (tool.BaseTool, error) { return tools.NewReviewExpressionTool() },
 		func() (tool.BaseTool, error) { return tools.NewGetUnverifiedJargonsTool() },
+		func() (tool.BaseTool, error) { return tools.NewListJargonsTool() },
 		func() (tool.BaseTool, error) { return tools.NewReviewJargonTool() },
 		// 发言相关
 		func() (tool.BaseTool, error) { return tools.NewSpeakTool() },
 		func() (tool.BaseTool, error) { return tools.NewStayQuietTool() },
 		// 时间
 		func() (tool.BaseTool, error) { return tools.NewGetCurrentTimeTool() },
+		func() (tool.BaseTool, error) { return tools.NewRollDiceTool() },
+		func() (tool.BaseTool, error) { return tools.NewRandomChoiceTool() },
+		func() (tool.BaseTool, error) { return tools.NewCalculateTool() },
+		func() (tool.BaseTool, error) { return tools.NewSetReminderTool() },
+		func() (tool.BaseTool, error) { return tools.NewListRemindersTool() },
+		func() (tool.BaseTool, error) { return tools.NewCancelReminderTool() },
+		func() (tool.BaseTool, error) { return tools.NewCreatePollTool() },
+		func() (tool.BaseTool, error) { return tools.NewClosePollTool() },
 		// 群交互
 		func() (tool.BaseTool, error) { return tools.NewGetGroupInfoTool() },
+		func() (tool.BaseTool, error) { return tools.NewUpdateGroupInfoTool() },
 		func() (tool.BaseTool, error) { return tools.NewGetGroupMemberDetailTool() },
+		func() (tool.BaseTool, error) { return tools.NewListGroupMembersTool() },
 		func() (tool.BaseTool, error) { return tools.NewPokeTool() },
 		func() (tool.BaseTool, error) { return tools.NewReactToMessageTool() },
 		func() (tool.BaseTool, error) { return tools.NewRecallMessageTool() },
+		func() (tool.BaseTool, error) { return tools.NewSendForwardTool() },
+		// 小游戏
+		func() (tool.BaseTool, error) { return tools.NewStartGameTool() },
+		func() (tool.BaseTool, error) { return tools.NewAnswerGameTool() },
+		func() (tool.BaseTool, error) { return tools.NewStopGameTool() },
 		// 表情包相关
 		func() (tool.BaseTool, error) { return tools.NewSearchStickersTool() },
 		func() (tool.BaseTool, error) { return tools.NewSendStickerTool() },
+		func() (tool.BaseTool, error) { return tools.NewDeleteStickerTool() },
+		func() (tool.BaseTool, error) { return tools.NewRelabelStickerTool() },
+		func() (tool.BaseTool, error) { return tools.NewSaveStickerFromMessageTool() },
 		// 群信息
 		func() (tool.BaseTool, error) { return tools.NewGetGroupNoticesTool() },
 		func() (tool.BaseTool, error) { return tools.NewGetEssenceMessagesTool() },
@@ -127,22 +289,110 @@ func (a *Agent) initTools() error {
 		func() (tool.BaseTool, error) { return tools.NewGetForwardMessageDetailTool() },
 		// 情绪系统
 		func() (tool.BaseTool, error) { return tools.NewUpdateMoodTool() },
+		func() (tool.BaseTool, error) { return tools.NewGetMoodTool() },
 		// HTTP GET
 		func() (tool.BaseTool, error) { return tools.NewHttpRequestTool() },
 	}
 
+	// 私聊
+	if a.cfg.Chat.PrivateMessage.Enabled {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewSendPrivateMessageTool() })
+	}
+	// 话题摘要检索（需要开启话题摘要）
+	if a.cfg.Memory.TopicSummary.Enabled {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewSearchTopicSummariesTool() })
+	}
+	// 跨群转发（至少两个群在白名单内才有意义）
+	if a.cfg.Chat.GroupForward.Enabled && len(a.cfg.Chat.GroupForward.Groups) >= 2 {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewForwardToGroupTool() })
+	}
+	// 资料卡点赞
+	if a.cfg.Chat.Like.Enabled {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewSendLikeTool() })
+	}
+	// 禁言（至少一个群授权时才提供）
+	if slices.ContainsFunc(a.cfg.GetGroups(), func(gc config.GroupConfig) bool { return gc.AllowMute }) {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewMuteMemberTool() })
+	}
+	// 按链接发送网络图片
+	if a.cfg.Image.Send.Enabled {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewSendImageTool() })
+	}
+	// 画图（需要配置图片生成）
+	if a.painter != nil {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewDrawImageTool() })
+	}
+	// 天气（需要配置天气 API）
+	if a.weather != nil {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewGetWeatherTool() })
+	}
+	// 语音（需要配置 TTS）
+	if a.tts != nil {
+		toolBuilders = append(toolBuilders, func() (tool.BaseTool, error) { return tools.NewSendVoiceTool() })
+	}
+
+	// 通过 tools.Register 注册的自定义工具
+	for _, build := range tools.Registered() {
+		toolBuilders = append(toolBuilders, build)
+	}
+
+	limiter := tools.NewRateLimiter(a.cfg.Agent.ToolRateLimits)
+	a.toolTimeouts = tools.NewToolTimeouts(a.cfg.Agent.ToolTimeout)
+	cache := tools.NewToolCache(a.cfg.Agent.ToolCache)
+	// 中间件由内到外：频率限制、panic 恢复、超时、结果缓存、调用审计、事件推送
+	wrap := func(t tool.BaseTool) tool.BaseTool {
+		t = tools.WithCache(tools.WithTimeout(tools.WithRecover(tools.WithRateLimit(t, limiter)), a.toolTimeouts), cache)
+		if a.cfg.Debug.ToolCallLog {
+			t = tools.WithAudit(t)
+		}
+		return tools.WithEvents(t, a.events)
+	}
 	for _, build := range toolBuilders {
 		t, err := build()
 		if err != nil {
 			return err
 		}
-		a.tools = append(a.tools, t)
+		a.tools = append(a.tools, wrap(t))
+	}
+
+	// 插件目录中的 HTTP 工具
+	if a.cfg.Plugins.Enabled {
+		dir := a.cfg.Plugins.ToolsDir
+		if dir == "" {
+			dir = "config/tools"
+		}
+		httpTools, err := tools.LoadHTTPTools(dir)
+		if err != nil {
+			zap.L().Warn("加载工具插件失败", zap.Error(err))
+		}
+		existing := make(map[string]bool, len(a.tools))
+		for _, t := range a.tools {
+			if info, err := t.Info(context.Background()); err == nil {
+				existing[info.Name] = true
+			}
+		}
+		loaded := 0
+		for _, t := range httpTools {
+			info, _ := t.Info(context.Background())
+			if existing[info.Name] {
+				zap.L().Warn("工具插件与已有工具重名，跳过", zap.String("tool", info.Name))
+				continue
+			}
+			existing[info.Name] = true
+			a.tools = append(a.tools, wrap(t))
+			loaded++
+		}
+		if loaded > 0 {
+			zap.L().Info("已加载工具插件", zap.Int("count", loaded))
+		}
 	}
 
 	// 添加 MCP 工具
 	mcpTools := a.mcpMgr.GetTools()
 	if len(mcpTools) > 0 {
-		a.tools = append(a.tools, mcpTools...)
+		for _, t := range mcpTools {
+			a.tools = append(a.tools, wrap(t))
+		}
 		zap.L().Info("已加载 MCP 工具", zap.Int("count", len(mcpTools)))
 	}
 
@@ -150,27 +400,80 @@ func (a *Agent) initTools() error {
 }
 
 func (a *Agent) initReact() error {
+	agent, err := a.newReact(a.model, a.tools)
+	if err != nil {
+		return err
+	}
+	a.react = agent
+
+	if a.lightModel != nil {
+		lightAgent, err := a.newReact(a.lightModel, a.tools)
+		if err != nil {
+			return err
+		}
+		a.lightReact = lightAgent
+	}
+	return nil
+}
+
+// newReact 使用指定模型和工具集创建 ReAct Agent
+func (a *Agent) newReact(m model.ToolCallingChatModel, tools []tool.BaseTool) (*react.Agent, error) {
 	maxStep := a.cfg.Agent.MaxStep
 	if maxStep <= 0 {
 		maxStep = 12 // 默认最大步数
 	}
-	agent, err := react.NewAgent(context.Background(), &react.AgentConfig{
-		ToolCallingModel: a.model,
-		ToolsConfig:      compose.ToolsNodeConfig{Tools: a.tools},
+	return react.NewAgent(context.Background(), &react.AgentConfig{
+		ToolCallingModel: m,
+		ToolsConfig:      compose.ToolsNodeConfig{Tools: tools},
 		MaxStep:          maxStep,
 	})
-	if err != nil {
-		return err
-	}
-	a.react = agent
-	return nil
 }
 
 // Start 启动
 func (a *Agent) Start() {
+	a.restoreEnabledGroups()
+	a.restoreBuffers()
 	a.bot.OnMessage(a.onMessage)
+	a.bot.OnPoke(a.onPoke)
+	// 开启突发检测时由新消息驱动思考，不再需要定时思考周期
+	if !a.cfg.Agent.Burst.Enabled {
+		a.wg.Add(1)
+		go a.thinkLoop()
+	}
+	if a.cfg.Memory.Diary.Enabled {
+		a.wg.Add(1)
+		go a.diaryLoop()
+	}
+	if a.cfg.Memory.TopicSummary.Enabled {
+		a.wg.Add(1)
+		go a.summaryLoop()
+	}
+	if a.cfg.Memory.Consolidation.Enabled {
+		a.wg.Add(1)
+		go a.consolidateLoop()
+	}
+	a.wg.Add(1)
+	go a.reminderLoop()
+	a.wg.Add(1)
+	go a.pollLoop()
 	a.wg.Add(1)
-	go a.thinkLoop()
+	go a.groupInfoLoop()
+	if a.cfg.Calendar.Enabled && a.cfg.Calendar.Greeting {
+		a.wg.Add(1)
+		go a.greetingLoop()
+	}
+	if a.cfg.Chat.ProactivePrivate.Enabled {
+		a.wg.Add(1)
+		go a.careLoop()
+	}
+	if a.cfg.Debug.DecisionLog {
+		a.wg.Add(1)
+		go a.decisionCleanupLoop()
+	}
+	if a.cfg.Debug.ToolCallLog {
+		a.wg.Add(1)
+		go a.toolCallCleanupLoop()
+	}
 	zap.L().Info("Agent 已启动")
 }
 
@@ -187,11 +490,19 @@ func (a *Agent) Stop() {
 
 func (a *Agent) onMessage(msg *onebot.GroupMessage) {
 	if !a.cfg.IsGroupEnabled(msg.GroupID) {
+		// 未启用的群只记录下来，并响应启用命令
+		a.discoverGroup(msg.GroupID)
+		a.handleCommand(msg)
+		return
+	}
+
+	// 管理命令不进入对话上下文
+	if a.handleCommand(msg) {
 		return
 	}
 
 	// 检测是否通过名字或别名提及了沐沐
-	isMentioned := msg.IsMentioned || a.persona.IsMentioned(msg.Content)
+	isMentioned := msg.IsMentioned || a.personaFor(msg.GroupID).IsMentioned(msg.Content)
 
 	// 序列化合并转发内容
 	forwardsJSON := ""
@@ -223,6 +534,14 @@ func (a *Agent) onMessage(msg *onebot.GroupMessage) {
 		CreatedAt:   msg.Time,
 		Forwards:    forwardsJSON,
 	})
+	go a.memory.RecordJargonUsage(msg.GroupID, msg.Content, msg.Time)
+	a.events.Publish(events.TypeMessage, msg.GroupID, map[string]any{
+		"message_id": msg.MessageID,
+		"user_id":    msg.UserID,
+		"nickname":   msg.Nickname,
+		"content":    parsedContent,
+		"mentioned":  isMentioned,
+	})
 
 	if msg.UserID == a.bot.GetSelfID() {
 		return
@@ -230,9 +549,24 @@ func (a *Agent) onMessage(msg *onebot.GroupMessage) {
 
 	go a.updateMember(msg)
 
+	var replyTo int64
+	if msg.Reply != nil {
+		replyTo = msg.Reply.MessageID
+	}
+	a.memory.ResolveExpressionResponse(msg.GroupID, replyTo, msg.IsMentioned, msg.Time)
+
+	// 回复你或 @ 你都算一次互动（回复通常自带 @，只记一次）
+	if msg.Reply != nil && msg.Reply.SenderID == a.bot.GetSelfID() {
+		go a.memory.RecordInteraction(msg.UserID, memory.InteractionReply, msg.Time)
+	} else if isMentioned {
+		go a.memory.RecordInteraction(msg.UserID, memory.InteractionMention, msg.Time)
+	}
+
 	// 如果被 @ 了，立即触发一次思考（跳过等待）
 	if isMentioned {
-		go a.think(msg.GroupID, true)
+		go a.think(msg.GroupID, thinkTrigger{mention: true})
+	} else if a.cfg.Agent.Burst.Enabled {
+		a.scheduleBurstThink(msg.GroupID)
 	}
 }
 
@@ -243,14 +577,15 @@ func (a *Agent) parseMessageContent(msg *onebot.GroupMessage) string {
 	// 构建回复信息
 	replyInfo := ""
 	if msg.Reply != nil {
+		replyImages := a.describeReplyImages(ctx, msg.Reply.Images)
 		if msg.Reply.Content != "" {
 			replyContent := []rune(msg.Reply.Content)
 			if len(replyContent) > 50 {
 				replyContent = replyContent[:50]
 			}
-			replyInfo = fmt.Sprintf(" [回复 #%d %s:\"%s\"]", msg.Reply.MessageID, msg.Reply.Nickname, string(replyContent))
+			replyInfo = fmt.Sprintf(" [回复 #%d %s:\"%s\"%s]", msg.Reply.MessageID, msg.Reply.Nickname, string(replyContent), replyImages)
 		} else {
-			replyInfo = fmt.Sprintf(" [回复 #%d]", msg.Reply.MessageID)
+			replyInfo = fmt.Sprintf(" [回复 #%d%s]", msg.Reply.MessageID, replyImages)
 		}
 	}
 
@@ -271,11 +606,13 @@ func (a *Agent) parseMessageContent(msg *onebot.GroupMessage) string {
 	// 处理图片（调用 Vision 模型识别）
 	for _, img := range msg.Images {
 		if img.SubType == 1 {
-			// 表情包类型
+			// 表情包类型：同时识别描述和结构化标签
 			var desc string
+			var info *llm.StickerInfo
 			if a.vision != nil && img.URL != "" {
-				if d, err := a.vision.DescribeImage(ctx, img.URL); err == nil {
-					desc = d
+				if si, err := a.vision.DescribeSticker(ctx, img.URL); err == nil {
+					info = si
+					desc = si.Description
 				}
 			}
 			if desc == "" && img.Summary != "" {
@@ -283,7 +620,7 @@ func (a *Agent) parseMessageContent(msg *onebot.GroupMessage) string {
 			}
 			// 自动保存表情包
 			if img.URL != "" && a.cfg.Sticker.AutoSave {
-				go a.autoSaveSticker(img.URL, desc)
+				go a.autoSaveSticker(msg.GroupID, img.URL, desc, info)
 			}
 			if desc != "" {
 				content += fmt.Sprintf(" [表情包 描述:%s]", desc)
@@ -317,9 +654,13 @@ func (a *Agent) parseMessageContent(msg *onebot.GroupMessage) string {
 		}
 	}
 
+	// @全体成员 的消息标注为群发通知
+	if msg.MentionAll {
+		content = "[群发通知] " + content
+	}
+
 	// 构建完整消息行
-	return fmt.Sprintf("[%s] #%d %s(%d):%s %s\n",
-		msg.Time.Format("15:04:05"), msg.MessageID, msg.Nickname, msg.UserID, replyInfo, content)
+	return a.formatter.format(msg, replyInfo, content)
 }
 
 func (a *Agent) addBuffer(msg *onebot.GroupMessage) {
@@ -339,6 +680,59 @@ func (a *Agent) addBuffer(msg *onebot.GroupMessage) {
 	buf.Push(msg)
 }
 
+// restoreBuffers 启动时从消息日志回填各群的消息缓冲，避免重启后丢失上下文
+func (a *Agent) restoreBuffers() {
+	n := a.cfg.Agent.BufferRestore
+	if n < 0 {
+		return
+	}
+	if n == 0 {
+		n = a.cfg.Agent.MessageBufferSize
+		if n <= 0 {
+			n = 15
+		}
+	}
+
+	now := time.Now()
+	for _, gc := range a.cfg.GetGroups() {
+		if !gc.Enabled {
+			continue
+		}
+		logs := a.memory.GetRecentMessages(gc.GroupID, n, 0)
+		for _, l := range logs {
+			msgID, _ := strconv.ParseInt(l.MessageID, 10, 64)
+			a.addBuffer(&onebot.GroupMessage{
+				MessageID:    msgID,
+				GroupID:      l.GroupID,
+				UserID:       l.UserID,
+				Nickname:     l.Nickname,
+				Content:      l.Content,
+				FinalContent: l.Content, // 消息日志中已是格式化后的消息行
+				Time:         l.CreatedAt,
+				MessageType:  l.MsgType,
+				IsMentioned:  l.IsMentioned,
+			})
+		}
+		if len(logs) > 0 {
+			// 回填的都是旧消息，不应触发思考
+			a.processingMu.Lock()
+			a.lastProcessedTime[gc.GroupID] = now
+			a.processingMu.Unlock()
+			zap.L().Info("已从消息日志恢复消息缓冲", zap.Int64("group_id", gc.GroupID), zap.Int("count", len(logs)))
+		}
+	}
+}
+
+// findBufferedMessage 在群消息缓冲中查找指定消息
+func (a *Agent) findBufferedMessage(groupID, messageID int64) *onebot.GroupMessage {
+	for _, m := range a.getBuffer(groupID) {
+		if m.MessageID == messageID {
+			return m
+		}
+	}
+	return nil
+}
+
 func (a *Agent) getBuffer(groupID int64) []*onebot.GroupMessage {
 	a.buffersMu.RLock()
 	buf, ok := a.buffers[groupID]
@@ -351,15 +745,7 @@ func (a *Agent) getBuffer(groupID int64) []*onebot.GroupMessage {
 }
 
 func (a *Agent) updateMember(msg *onebot.GroupMessage) {
-	p, err := a.memory.GetOrCreateMemberProfile(msg.UserID, msg.Nickname)
-	if err != nil {
-		zap.L().Warn("获取成员画像失败", zap.Error(err))
-		return
-	}
-	p.MsgCount++
-	p.LastSpeak = msg.Time
-	p.Nickname = msg.Nickname
-	if err := a.memory.UpdateMemberProfile(p); err != nil {
+	if err := a.memory.RecordMemberMessage(msg.GroupID, msg.UserID, msg.Nickname, msg.Time); err != nil {
 		zap.L().Warn("更新成员画像失败", zap.Error(err))
 	}
 }
@@ -379,50 +765,91 @@ func (a *Agent) thinkLoop() {
 }
 
 func (a *Agent) thinkCycle() {
-	for _, gc := range a.cfg.Groups {
-		if !gc.Enabled {
-			continue
-		}
-		msgs := a.getBuffer(gc.GroupID)
-		if len(msgs) == 0 {
-			continue
+	defer recoverPanic("thinkCycle")
+	moodFactor := a.moodSpeakFactor()
+	for _, gc := range a.cfg.GetGroups() {
+		if gc.Enabled {
+			a.considerThinking(gc.GroupID, moodFactor)
 		}
+	}
+}
 
-		lastMsg := msgs[len(msgs)-1]
+// considerThinking 检查群是否有需要处理的新消息，并按发言概率决定是否触发主动思考
+func (a *Agent) considerThinking(groupID int64, moodFactor float64) {
+	msgs := a.getBuffer(groupID)
+	if len(msgs) == 0 {
+		return
+	}
 
-		// 如果该消息的时间不晚于最后处理时间，说明是旧消息，跳过
-		a.processingMu.RLock()
-		lastTime := a.lastProcessedTime[gc.GroupID]
-		a.processingMu.RUnlock()
-		if !lastTime.IsZero() && lastMsg.Time.Before(lastTime) {
-			continue
-		}
+	lastMsg := msgs[len(msgs)-1]
 
-		// 如果最后一条消息是自己发的，跳过
-		if lastMsg.UserID == a.bot.GetSelfID() {
-			continue
-		}
+	// 如果该消息的时间不晚于最后处理时间，说明是旧消息，跳过
+	a.processingMu.RLock()
+	lastTime := a.lastProcessedTime[groupID]
+	a.processingMu.RUnlock()
+	if !lastTime.IsZero() && lastMsg.Time.Before(lastTime) {
+		return
+	}
 
-		// 如果最后一条消息是 @提及，已经在 onMessage 中触发了即时思考，这里跳过
-		if a.persona.IsMentioned(lastMsg.Content) || lastMsg.IsMentioned {
-			continue
-		}
+	// 如果最后一条消息是自己发的，跳过
+	if lastMsg.UserID == a.bot.GetSelfID() {
+		return
+	}
+
+	// 如果最后一条消息是 @提及，已经在 onMessage 中触发了即时思考，这里跳过
+	if a.personaFor(groupID).IsMentioned(lastMsg.Content) || lastMsg.IsMentioned {
+		return
+	}
+
+	if time.Since(lastMsg.Time) > time.Duration(a.cfg.Agent.ObserveWindow)*time.Second {
+		return
+	}
+	// 主动发言冷却中
+	if a.inCooldown(groupID) {
+		return
+	}
+	// 获取当前的发言概率（考虑时段规则）
+	speakProb := a.getSpeakProbability(groupID) * a.budgetSpeakFactor(groupID) * moodFactor
+	speakProb *= a.interestFactor(groupID, msgs, lastTime)
+	if lastMsg.MentionAll {
+		speakProb *= a.mentionAllFactor()
+	}
+	speakProb = min(speakProb, 1)
+	if rand.Float64() > speakProb {
+		return
+	}
+	// 并发由 thinkSem 统一限制，这里不再串行等待
+	go a.think(groupID, thinkTrigger{prob: speakProb})
+}
 
-		if time.Since(lastMsg.Time) > time.Duration(a.cfg.Agent.ObserveWindow)*time.Second {
+// interestFactor 根据最近的新消息是否命中兴趣话题调整发言概率
+// 命中兴趣时乘以 interest_boost，否则乘以 uninterested_factor
+func (a *Agent) interestFactor(groupID int64, msgs []*onebot.GroupMessage, since time.Time) float64 {
+	boost := a.cfg.Chat.InterestBoost
+	if boost <= 0 {
+		boost = 1.5
+	}
+	penalty := a.cfg.Chat.UninterestedFactor
+	if penalty <= 0 {
+		penalty = 0.8
+	}
+
+	p := a.personaFor(groupID)
+	selfID := a.bot.GetSelfID()
+	for _, m := range msgs {
+		if m.UserID == selfID || m.Time.Before(since) {
 			continue
 		}
-		// 获取当前的发言概率（考虑时段规则）
-		speakProb := a.getSpeakProbability(gc.GroupID)
-		if rand.Float64() > speakProb {
-			continue
+		if p.IsInterested(m.Content) {
+			return boost
 		}
-		a.think(gc.GroupID, false)
 	}
+	return penalty
 }
 
 // getSpeakProbability 获取发言概率（考虑时段规则）
 func (a *Agent) getSpeakProbability(groupID int64) float64 {
-	baseProb := a.cfg.Chat.TalkFrequency
+	baseProb := a.cfg.TalkFrequency()
 	if !a.cfg.Chat.EnableTimeRules || len(a.cfg.Chat.TimeRules) == 0 {
 		return baseProb
 	}
@@ -462,56 +889,146 @@ func (a *Agent) getSpeakProbability(groupID int64) float64 {
 	return baseProb
 }
 
+// thinkTrigger 触发思考的原因
+type thinkTrigger struct {
+	mention bool    // 被 @ 触发
+	hint    string  // 附加到思考提示词的触发说明（如到点的提醒）
+	source  string  // 触发来源（reminder、calendar、onboard 等），为空时根据 mention 判断
+	prob    float64 // 主动思考时使用的发言概率
+}
+
+// reason 触发原因（用于决策追踪）
+func (t thinkTrigger) reason() string {
+	switch {
+	case t.source != "":
+		return t.source
+	case t.mention:
+		return "mention"
+	default:
+		return "proactive"
+	}
+}
+
+// urgent 是否需要跳过预判、优先获得思考名额
+func (t thinkTrigger) urgent() bool {
+	return t.mention || t.hint != "" || t.source == triggerManual
+}
+
 // think 进行思考和决策
-func (a *Agent) think(groupID int64, isMention bool) {
-	if a.bot.IsSelfMuted(groupID) {
+func (a *Agent) think(groupID int64, trig thinkTrigger) {
+	// 最先注册，保证 panic 时 processing 等标记的清理先执行
+	defer recoverPanic("think", zap.Int64("group_id", groupID))
+	start := time.Now()
+	if a.bot.IsSelfMuted(groupID) || a.IsPaused(groupID) {
+		a.recordDecision(groupID, trig, decisionPaused, nil, "", start, nil)
 		return
 	}
 	// 并发锁：确保同一时间一个群只有一个思考进程
 	a.processingMu.Lock()
 	if a.processing[groupID] {
+		// 思考中又被 @：记入待处理，当前思考结束后带上新消息重新思考
+		if trig.mention {
+			a.pendingMention[groupID] = true
+			if a.cfg.Agent.InterruptOnMention {
+				if cancel := a.thinkCancel[groupID]; cancel != nil {
+					cancel(errThinkInterrupted)
+				}
+			}
+		}
 		a.processingMu.Unlock()
 		return
 	}
 	a.processing[groupID] = true
 	lastProcessedTime := a.lastProcessedTime[groupID]
 	a.lastProcessedTime[groupID] = time.Now()
+
+	// 创建可取消的 context，用于 stayQuiet 强制停止思考或被新消息打断
+	ctxWithCancel, cancelWithCause := context.WithCancelCause(context.Background())
+	cancelThinking := func() { cancelWithCause(nil) }
+	a.thinkCancel[groupID] = cancelWithCause
 	a.processingMu.Unlock()
 
 	defer func() {
 		a.processingMu.Lock()
 		a.processing[groupID] = false
+		delete(a.thinkCancel, groupID)
+		pending := a.pendingMention[groupID]
+		delete(a.pendingMention, groupID)
 		a.processingMu.Unlock()
-	}()
 
-	// 创建可取消的 context，用于 stayQuiet 强制停止思考
-	ctxWithCancel, cancelThinking := context.WithCancel(context.Background())
+		if pending {
+			go a.think(groupID, thinkTrigger{mention: true})
+		}
+	}()
 	defer cancelThinking()
 
-	ctx := tools.WithToolContext(ctxWithCancel, &tools.ToolContext{
+	// 全局并发限制：名额不足时排队，被 @ 的群优先获得名额
+	if !a.thinkSem.Acquire(trig.urgent(), ctxWithCancel.Done()) {
+		return
+	}
+	defer a.thinkSem.Release()
+
+	p, personaKey := a.personaFor(groupID), a.personaKey(groupID)
+	ctx := tools.WithToolContext(memory.WithPersona(ctxWithCancel, personaKey), &tools.ToolContext{
 		GroupID:   groupID,
 		MemoryMgr: a.memory,
 		Bot:       a.bot,
-		SpeakCallback: func(gid int64, content string, replyTo int64, mentions []int64) int64 {
+		Games:     a.games,
+		Vision:    a.vision,
+		Weather:   a.weather,
+		SpeakCallback: func(gid int64, content string, replyTo int64, mentions []int64) (int64, error) {
 			return a.doSpeak(gid, content, replyTo, mentions)
 		},
+		VoiceCallback: func(gid int64, content string) (int64, error) {
+			return a.doSpeakVoice(gid, content)
+		},
+		ForwardCallback: func(gid int64, nodes []onebot.ForwardMessage) (int64, error) {
+			return a.doSendForward(gid, nodes)
+		},
+		PrivateCallback: func(gid, uid int64, content string) (int64, error) {
+			return a.doSpeakPrivate(gid, uid, content)
+		},
+		ForwardToGroupCallback: func(from, to, msgID int64) error {
+			return a.doForwardToGroup(from, to, msgID)
+		},
+		SummarizeCallback: a.doSummarizeContent,
+		DrawCallback: func(gid int64, prompt string) (int64, error) {
+			return a.doDrawImage(gid, prompt)
+		},
+		CreatePollCallback: func(gid int64, question string, options []string, duration time.Duration) (*memory.Poll, error) {
+			return a.doCreatePoll(gid, question, options, duration)
+		},
+		ClosePollCallback: func(p *memory.Poll) (string, error) {
+			return a.doClosePoll(p)
+		},
+		SaveStickerCallback: func(gid, msgID int64) ([]memory.Sticker, int, error) {
+			return a.doSaveStickerFromMessage(gid, msgID)
+		},
 		StopThinking: cancelThinking, // 传递取消函数
 	})
 
 	// 构建对话上下文
-	chatContext := a.buildChatContext(groupID)
-	if chatContext == "" {
+	chatContext, mainTopic := a.buildChatContext(groupID)
+	if chatContext == "" && trig.hint == "" {
+		return
+	}
+
+	// 预判：非 @ 或提醒触发时先快速判断是否可能发言
+	if !trig.urgent() && !a.prejudge(ctx, groupID, lastProcessedTime) {
+		zap.L().Debug("预判无需发言，跳过本次思考", zap.Int64("group_id", groupID))
+		a.recordDecision(groupID, trig, decisionPrejudgeSkip, nil, "", start, nil)
 		return
 	}
 
 	// 构建动态 prompt 上下文
 	promptCtx := a.buildPromptContext(ctx, groupID, chatContext)
+	promptCtx.MainTopic = mainTopic
 
 	// 获取说话者信息
 	memberInfo := a.getMemberInfo(groupID)
 
 	// 构建消息
-	systemPrompt := a.persona.GetSystemPrompt()
+	systemPrompt := p.GetSystemPrompt()
 
 	// 添加群专属额外提示词
 	groupExtra := ""
@@ -519,7 +1036,7 @@ func (a *Agent) think(groupID int64, isMention bool) {
 		groupExtra = gc.ExtraPrompt
 	}
 
-	thinkPrompt := a.persona.GetThinkPrompt(promptCtx, chatContext, groupExtra, memberInfo)
+	thinkPrompt := p.GetThinkPrompt(promptCtx, chatContext, groupExtra, memberInfo)
 
 	// 注入上次处理时间到提示词
 	if !lastProcessedTime.IsZero() {
@@ -527,9 +1044,18 @@ func (a *Agent) think(groupID int64, isMention bool) {
 			lastProcessedTime.Format("15:04:05"))
 	}
 
-	if isMention {
+	if gameType, intro, ok := a.games.Current(groupID); ok {
+		thinkPrompt += fmt.Sprintf("\n\n注意：群里正在玩小游戏（%s）。%s\n有群友作答时用 answerGame 判定，再用 speak 告诉大家结果。", gameType, intro)
+	}
+	if a.hasFreshMentionAll(groupID, lastProcessedTime) {
+		thinkPrompt += "\n\n注意：标有 [群发通知] 的是 @全体成员 的通知，不是在和你对话，一般不需要回复。"
+	}
+	if trig.mention {
 		thinkPrompt += "\n\n注意：有人提到你了，可能在找你说话，你可以看情况回复。"
 	}
+	if trig.hint != "" {
+		thinkPrompt += "\n\n" + trig.hint
+	}
 
 	// 调试：显示系统提示词
 	if a.cfg.Debug.ShowPrompt {
@@ -547,18 +1073,42 @@ func (a *Agent) think(groupID int64, isMention bool) {
 	ctxWithTimeout, cancelTimeout := context.WithTimeout(ctx, timeout)
 	defer cancelTimeout()
 
-	result, err := a.react.Generate(ctxWithTimeout, msgs)
+	// 超预算且配置为切换模型时，使用轻量模型
+	light, modelName := false, a.cfg.LLM.Model
+	if a.lightReact != nil && a.cfg.Budget.Action == budgetActionSwitch && a.overBudget(groupID) {
+		light, modelName = true, a.cfg.LightLLM.Model
+		zap.L().Debug("已超出 token 预算，使用轻量模型", zap.Int64("group_id", groupID))
+	}
+	reactAgent, err := a.reactFor(groupID, light)
+	if err != nil {
+		zap.L().Error("创建群专属 ReAct 失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return
+	}
+
+	usage := &usageCounter{}
+	trace := &decisionTrace{}
+	result, err := reactAgent.Generate(ctxWithTimeout, msgs, einoagent.WithComposeOptions(
+		compose.WithCallbacks(usage.handler(), trace.handler())))
+	a.recordUsage(groupID, modelName, usage)
+	action := trace.action()
 	if err != nil {
 		// 区分是超时还是主动取消（stayQuiet）
 		if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
 			zap.L().Warn("思考超时", zap.Int64("group_id", groupID), zap.Duration("timeout", timeout))
+			action = decisionTimeout
+		} else if errors.Is(context.Cause(ctxWithCancel), errThinkInterrupted) {
+			zap.L().Debug("思考被新消息打断，稍后重新思考", zap.Int64("group_id", groupID))
+			action = decisionInterrupted
 		} else if errors.Is(ctxWithCancel.Err(), context.Canceled) {
 			// stayQuiet 触发的主动停止，这是正常行为，不记录错误
 			zap.L().Debug("思考结束（stayQuiet）", zap.Int64("group_id", groupID))
+			err = nil
 		} else {
 			zap.L().Error("思考失败", zap.Int64("group_id", groupID), zap.Error(err))
+			action = decisionError
 		}
 	}
+	a.recordDecision(groupID, trig, action, trace, modelName, start, err)
 
 	// 记录 Agent 输出
 	if a.cfg.Debug.ShowThinking && result != nil && result.Content != "" {
@@ -566,18 +1116,55 @@ func (a *Agent) think(groupID int64, isMention bool) {
 	}
 }
 
-// buildChatContext 构建聊天上下文
-func (a *Agent) buildChatContext(groupID int64) string {
+// mentionAllFactor @全体成员 消息的发言概率乘数
+func (a *Agent) mentionAllFactor() float64 {
+	if f := a.cfg.Chat.MentionAllFactor; f > 0 {
+		return f
+	}
+	return 0.3
+}
+
+// hasFreshMentionAll 检查上次处理之后是否有 @全体成员 的消息
+func (a *Agent) hasFreshMentionAll(groupID int64, since time.Time) bool {
+	for _, m := range a.getBuffer(groupID) {
+		if m.MentionAll && !m.Time.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// personaKey 获取群使用的人格名称，默认人格返回空字符串
+func (a *Agent) personaKey(groupID int64) string {
+	if gc := a.cfg.GetGroupConfig(groupID); gc != nil && a.personas[gc.Persona] != nil {
+		return gc.Persona
+	}
+	return ""
+}
+
+// personaFor 获取群使用的人格
+func (a *Agent) personaFor(groupID int64) *persona.Persona {
+	if p := a.personas[a.personaKey(groupID)]; p != nil {
+		return p
+	}
+	return a.persona
+}
+
+// buildChatContext 构建聊天上下文，开启话题跟踪时按话题分组并返回当前主要话题
+func (a *Agent) buildChatContext(groupID int64) (string, string) {
 	msgs := a.getBuffer(groupID)
 	if len(msgs) == 0 {
-		return ""
+		return "", ""
+	}
+	if a.cfg.Agent.TopicTracking {
+		return formatTopics(msgs)
 	}
 
 	var b strings.Builder
 	for _, m := range msgs {
 		b.WriteString(m.FinalContent)
 	}
-	return b.String()
+	return b.String(), ""
 }
 
 // buildPromptContext 构建动态 prompt 上下文
@@ -608,6 +1195,26 @@ func (a *Agent) buildPromptContext(ctx context.Context, groupID int64, chatConte
 		}
 	}
 
+	// 群基础信息
+	pc.GroupInfo = a.groupInfoPrompt(groupID)
+
+	// 最近发言的群友希望的称呼
+	pc.Addresses = a.preferredAddresses(groupID)
+
+	// 与当前聊天内容相关的黑话（原文出现的优先，再按语义匹配）
+	if jargons, err := a.memory.MatchJargons(ctx, groupID, chatContext, 10); err == nil && len(jargons) > 0 {
+		lines := make([]string, 0, len(jargons))
+		for _, j := range jargons {
+			lines = append(lines, fmt.Sprintf("- %s：%s", j.Content, j.Meaning))
+		}
+		pc.Jargons = strings.Join(lines, "\n")
+	}
+
+	// 今天的节日和群友生日
+	if a.cfg.Calendar.Enabled {
+		pc.Calendar = a.calendarInfo(groupID, time.Now())
+	}
+
 	// 获取当前情绪状态
 	if mood, err := a.memory.GetMoodState(); err == nil {
 		pc.MoodState = &persona.MoodInfo{
@@ -620,6 +1227,24 @@ func (a *Agent) buildPromptContext(ctx context.Context, groupID int64, chatConte
 	return pc
 }
 
+// preferredAddresses 列出缓冲区中发言过、且说过希望怎么被称呼的群友
+func (a *Agent) preferredAddresses(groupID int64) string {
+	seen := make(map[int64]bool)
+	var lines []string
+	for _, msg := range a.getBuffer(groupID) {
+		if seen[msg.UserID] {
+			continue
+		}
+		seen[msg.UserID] = true
+		profile, err := a.memory.GetUserProfile(msg.UserID)
+		if err != nil || profile.PreferredAddress == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %s(%d): %s", msg.Nickname, msg.UserID, profile.PreferredAddress))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // getMemberInfo 获取当前说话者信息
 func (a *Agent) getMemberInfo(groupID int64) string {
 	msgs := a.getBuffer(groupID)
@@ -629,18 +1254,31 @@ func (a *Agent) getMemberInfo(groupID int64) string {
 
 	// 获取最后一个说话者的信息
 	lastMsg := msgs[len(msgs)-1]
-	profile, err := a.memory.GetMemberProfile(lastMsg.UserID)
+	profile, err := a.memory.GetUserProfile(lastMsg.UserID)
 	if err != nil {
 		return ""
 	}
+	member, err := a.memory.GetMemberProfile(groupID, lastMsg.UserID)
+	if err != nil {
+		member = &memory.MemberProfile{Nickname: profile.Nickname, Activity: 0.5}
+	}
 
 	var parts []string
-	parts = append(parts, fmt.Sprintf("昵称: %s", profile.Nickname))
-	parts = append(parts, fmt.Sprintf("活跃度（0-1）: %.2f", profile.Activity))
+	parts = append(parts, fmt.Sprintf("昵称: %s", member.Nickname))
+	if profile.PreferredAddress != "" {
+		parts = append(parts, fmt.Sprintf("希望被称呼为: %s", profile.PreferredAddress))
+	}
+	if loc, err := time.LoadLocation(profile.Timezone); err == nil && profile.Timezone != "" {
+		parts = append(parts, fmt.Sprintf("所在时区: %s（当地时间 %s）", profile.Timezone, time.Now().In(loc).Format("15:04")))
+	}
+	parts = append(parts, fmt.Sprintf("在本群的活跃度（0-1）: %.2f", member.Activity))
 	parts = append(parts, fmt.Sprintf("你与他的亲密度（0-1）: %.2f", profile.Intimacy))
 	if profile.SpeakStyle != "" {
 		parts = append(parts, fmt.Sprintf("说话风格: %s", profile.SpeakStyle))
 	}
+	if member.SpeakStyle != "" {
+		parts = append(parts, fmt.Sprintf("在本群的说话风格: %s", member.SpeakStyle))
+	}
 	if profile.Interests != "" {
 		parts = append(parts, fmt.Sprintf("兴趣: %s", profile.Interests))
 	}
@@ -651,49 +1289,132 @@ func (a *Agent) getMemberInfo(groupID int64) string {
 }
 
 // doSpeak 执行发言，返回消息ID
-func (a *Agent) doSpeak(groupID int64, content string, replyTo int64, mentions []int64) int64 {
+func (a *Agent) doSpeak(groupID int64, content string, replyTo int64, mentions []int64) (int64, error) {
+	// 防复读：与最近自己的发言过于相似时拦截
+	if err := a.checkRepeat(groupID, content); err != nil {
+		zap.L().Info("拦截重复发言", zap.Int64("group_id", groupID), zap.String("content", content))
+		return 0, err
+	}
+
+	// 内容安全过滤
+	res := a.speakFilter.Apply(groupID, content)
+	if res.Blocked {
+		return 0, fmt.Errorf("这句话包含不适合发送的内容（命中规则「%s」），换个说法", res.Hits[len(res.Hits)-1].Rule)
+	}
+	content = res.Content
+
+	// 校验回复目标，无效时降级为普通发言
+	var replyErr error
+	if replyTo > 0 && !a.isValidReplyTarget(groupID, replyTo) {
+		zap.L().Info("回复目标无效，降级为普通发言", zap.Int64("group_id", groupID), zap.Int64("reply_to", replyTo))
+		replyTo = 0
+		replyErr = tools.ErrReplyTargetInvalid
+	}
+
 	// 模拟打字延迟
 	if a.cfg.Chat.TypingSimulation {
-		typingSpeed := a.cfg.Chat.TypingSpeed
-		if typingSpeed <= 0 {
-			typingSpeed = 6
-		}
-		delay := time.Duration(float64(len([]rune(content)))/float64(typingSpeed)*1000) * time.Millisecond
-		if delay > 5*time.Second {
-			delay = 5 * time.Second
-		}
-		if delay < 500*time.Millisecond {
-			delay = 500 * time.Millisecond
-		}
-		time.Sleep(delay)
+		time.Sleep(a.typingDelay(groupID, content))
 	}
 
 	msgID, err := a.bot.SendGroupMessage(groupID, content, replyTo, mentions)
 	if err != nil {
 		zap.L().Error("发言失败", zap.Int64("group_id", groupID), zap.Error(err))
-		return 0
+		return 0, fmt.Errorf("发送失败: %w", err)
+	}
+	a.recordSpeak(groupID, content)
+	a.markSpoke(groupID)
+	if a.cfg.Chat.PostSpeakReview {
+		go a.reviewSpeak(groupID, msgID, content)
 	}
 
+	// 回写自己的消息，保留回复和 @ 信息（@ 的文本形式与收到的消息一致）
+	var atParts []string
+	for _, uid := range mentions {
+		if uid > 0 {
+			atParts = append(atParts, fmt.Sprintf("@%d", uid))
+		}
+	}
+	text := content
+	if len(atParts) > 0 {
+		text = strings.Join(atParts, " ") + " " + content
+	}
 	msg := &onebot.GroupMessage{
 		MessageID:   msgID,
 		GroupID:     groupID,
 		UserID:      a.bot.GetSelfID(),
-		Nickname:    a.persona.GetName(),
-		Content:     content,
+		Nickname:    a.personaFor(groupID).GetName(),
+		Content:     text,
 		Time:        time.Now(),
 		MessageType: "group",
+		AtList:      mentions,
+	}
+	if replyTo > 0 {
+		msg.Reply = &onebot.ReplyInfo{MessageID: replyTo}
+		if target := a.findBufferedMessage(groupID, replyTo); target != nil {
+			msg.Reply.Content = target.Content
+			msg.Reply.SenderID = target.UserID
+			msg.Reply.Nickname = target.Nickname
+		}
 	}
 	a.onMessage(msg)
+	a.recordSpeakInteractions(msg)
+	a.events.Publish(events.TypeSpeak, groupID, map[string]any{
+		"message_id": msgID,
+		"content":    text,
+		"reply_to":   msg.Reply,
+	})
 	zap.L().Info("发言成功", zap.Int64("group_id", groupID), zap.String("content", content))
-	return msgID
+	return msgID, replyErr
+}
+
+// describeReplyImages 用 Vision 模型描述被回复消息中的图片，让"这是什么"之类的追问有上下文
+func (a *Agent) describeReplyImages(ctx context.Context, images []onebot.ImageInfo) string {
+	if a.vision == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, img := range images {
+		if img.URL == "" {
+			continue
+		}
+		if desc, err := a.vision.DescribeImage(ctx, img.URL); err == nil {
+			sb.WriteString(" " + desc)
+		}
+	}
+	return sb.String()
+}
+
+// isValidReplyTarget 检查回复目标是否在 buffer 或最近的消息日志中
+func (a *Agent) isValidReplyTarget(groupID, messageID int64) bool {
+	if a.findBufferedMessage(groupID, messageID) != nil {
+		return true
+	}
+	log, err := a.memory.GetMessageLogByID(strconv.FormatInt(messageID, 10))
+	if err != nil {
+		return false
+	}
+	return log.GroupID == groupID && time.Since(log.CreatedAt) <= replyTargetMaxAge
 }
 
 // autoSaveSticker 自动保存表情包（异步执行）
-func (a *Agent) autoSaveSticker(url string, description string) {
+func (a *Agent) autoSaveSticker(groupID int64, url string, description string, info *llm.StickerInfo) {
 	if url == "" {
 		return
 	}
+	sticker, isDuplicate, err := a.saveSticker(groupID, url, description, info)
+	if err != nil {
+		zap.L().Debug("自动保存表情包失败", zap.String("url", url), zap.Error(err))
+		return
+	}
+	if isDuplicate {
+		zap.L().Debug("表情包已存在，跳过保存", zap.String("hash", sticker.FileHash))
+		return
+	}
+	zap.L().Info("自动保存表情包", zap.Uint("id", sticker.ID), zap.String("desc", sticker.Description))
+}
 
+// saveSticker 下载并收藏表情包，已收藏过时返回 isDuplicate
+func (a *Agent) saveSticker(groupID int64, url string, description string, info *llm.StickerInfo) (*memory.Sticker, bool, error) {
 	// 获取配置
 	cfg := config.Get()
 	storagePath := cfg.Sticker.StoragePath
@@ -708,8 +1429,7 @@ func (a *Agent) autoSaveSticker(url string, description string) {
 	// 下载图片
 	result, err := utils.DownloadImage(url, storagePath, maxSizeMB)
 	if err != nil {
-		zap.L().Debug("下载表情包失败", zap.String("url", url), zap.Error(err))
-		return
+		return nil, false, fmt.Errorf("下载表情包失败: %w", err)
 	}
 
 	// 如果没有描述，使用默认描述
@@ -721,23 +1441,65 @@ func (a *Agent) autoSaveSticker(url string, description string) {
 	sticker := &memory.Sticker{
 		FileName:    result.FileName,
 		FileHash:    result.FileHash,
+		GroupID:     groupID,
 		Description: description,
 	}
+	if info != nil {
+		sticker.Emotion = info.Emotion
+		sticker.Subject = info.Subject
+		sticker.HasText = info.HasText
+		sticker.Text = info.Text
+	}
 
 	isDuplicate, err := a.memory.SaveSticker(sticker)
 	if err != nil {
 		// 保存失败，删除已下载的文件
 		_ = os.Remove(result.FilePath)
 		zap.L().Warn("保存表情包失败", zap.Error(err))
-		return
+		return nil, false, err
 	}
 
 	if isDuplicate {
 		// 已存在，删除刚下载的文件
 		_ = os.Remove(result.FilePath)
-		zap.L().Debug("表情包已存在，跳过保存", zap.String("hash", result.FileHash))
+	}
+	return sticker, isDuplicate, nil
+}
+
+// onPoke 群友戳了戳你，记一次互动
+func (a *Agent) onPoke(groupID, userID int64) {
+	if !a.cfg.IsGroupEnabled(groupID) {
 		return
 	}
+	a.memory.RecordInteraction(userID, memory.InteractionPoke, time.Now())
+}
+
+// recordSpeakInteractions 你回复或 @ 了群友，每人记一次互动
+func (a *Agent) recordSpeakInteractions(msg *onebot.GroupMessage) {
+	targets := make(map[int64]bool)
+	for _, uid := range msg.AtList {
+		if uid > 0 {
+			targets[uid] = true
+		}
+	}
+	if msg.Reply != nil && msg.Reply.SenderID != 0 {
+		targets[msg.Reply.SenderID] = true
+	}
+	delete(targets, a.bot.GetSelfID())
+	for uid := range targets {
+		go a.memory.RecordInteraction(uid, memory.InteractionReplied, msg.Time)
+	}
+}
+
+// ToolStats 获取各工具的执行统计
+func (a *Agent) ToolStats() map[string]tools.ToolStat {
+	if a.toolTimeouts == nil {
+		return map[string]tools.ToolStat{}
+	}
+	return a.toolTimeouts.Stats()
+}
 
-	zap.L().Info("自动保存表情包", zap.Uint("id", sticker.ID), zap.String("desc", description))
+// Events 获取实时事件总线
+func (a *Agent) Events() *events.Bus {
+	return a.events
 }
@@ -0,0 +1,15 @@
+package agent
+
+import (
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// recoverPanic 恢复 panic 并记录堆栈，需直接以 defer 调用
+func recoverPanic(where string, fields ...zap.Field) {
+	if r := recover(); r != nil {
+		fields = append(fields, zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+		zap.L().Error(where+" panic", fields...)
+	}
+}
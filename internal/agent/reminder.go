@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/memory"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reminderCheckInterval 提醒调度检查间隔
+const reminderCheckInterval = 30 * time.Second
+
+// reminderLoop 定时提醒调度：到点后触发一次带提醒上下文的思考
+func (a *Agent) reminderLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case now := <-ticker.C:
+			a.fireReminders(now)
+		}
+	}
+}
+
+// fireReminders 触发所有到期的提醒
+func (a *Agent) fireReminders(now time.Time) {
+	reminders, err := a.memory.GetDueReminders(now)
+	if err != nil {
+		zap.L().Warn("获取到期提醒失败", zap.Error(err))
+		return
+	}
+
+	fired := make(map[int64]bool)
+	for _, r := range reminders {
+		// 群未启用时直接丢弃提醒
+		if gc := a.cfg.GetGroupConfig(r.GroupID); gc == nil || !gc.Enabled {
+			_ = a.memory.MarkReminderDone(r.ID)
+			continue
+		}
+		// 同一群每轮只触发一条，群正在思考时留到下一轮
+		if fired[r.GroupID] || a.isThinking(r.GroupID) {
+			continue
+		}
+		if err := a.memory.MarkReminderDone(r.ID); err != nil {
+			zap.L().Warn("标记提醒失败", zap.Uint("id", r.ID), zap.Error(err))
+			continue
+		}
+		fired[r.GroupID] = true
+		zap.L().Info("触发定时提醒", zap.Int64("group_id", r.GroupID), zap.Int64("user_id", r.UserID), zap.String("content", r.Content))
+		go a.think(r.GroupID, thinkTrigger{hint: reminderHint(&r), source: "reminder"})
+	}
+}
+
+// reminderHint 生成提醒触发时附加的思考提示
+func reminderHint(r *memory.Reminder) string {
+	who := fmt.Sprintf("%s(%d)", r.Nickname, r.UserID)
+	if r.Nickname == "" {
+		who = fmt.Sprintf("QQ号为 %d 的群友", r.UserID)
+	}
+	return fmt.Sprintf("注意：你在 [%s] 答应了到 [%s] 提醒 %s：「%s」。现在时间到了，请用 speak 提醒 TA，并在 mentions 中 @ TA（QQ号 %d）。",
+		r.CreatedAt.Format("01-02 15:04"), r.RemindAt.Format("01-02 15:04"), who, r.Content, r.UserID)
+}
+
+// isThinking 检查群是否正在思考
+func (a *Agent) isThinking(groupID int64) bool {
+	a.processingMu.Lock()
+	defer a.processingMu.Unlock()
+	return a.processing[groupID]
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/utils"
+)
+
+// repeatConfig 获取防复读配置（比对条数、相似度阈值）
+func (a *Agent) repeatConfig() (int, float64) {
+	count := a.cfg.Chat.RepeatCheckCount
+	if count == 0 {
+		count = 5
+	}
+	threshold := a.cfg.Chat.RepeatSimilarity
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.8
+	}
+	return count, threshold
+}
+
+// checkRepeat 检查发言是否与最近自己的发言过于相似
+// 相似时返回错误，错误信息会作为工具结果反馈给 LLM，让它换个说法
+func (a *Agent) checkRepeat(groupID int64, content string) error {
+	count, threshold := a.repeatConfig()
+	if count < 0 {
+		return nil
+	}
+
+	a.recentSpeaksMu.Lock()
+	rb := a.recentSpeaks[groupID]
+	var recent []string
+	if rb != nil {
+		recent = rb.GetAll()
+	}
+	a.recentSpeaksMu.Unlock()
+
+	for _, prev := range recent {
+		if utils.TextSimilarity(prev, content) >= threshold {
+			return fmt.Errorf("这句话和你最近说过的「%s」太像了，不要重复自己，换个说法或者干脆不说", prev)
+		}
+	}
+	return nil
+}
+
+// recordSpeak 记录一条自己的发言
+func (a *Agent) recordSpeak(groupID int64, content string) {
+	count, _ := a.repeatConfig()
+	if count < 0 {
+		return
+	}
+
+	a.recentSpeaksMu.Lock()
+	defer a.recentSpeaksMu.Unlock()
+	rb := a.recentSpeaks[groupID]
+	if rb == nil || rb.Cap() != count {
+		rb = utils.NewRingBuffer[string](count)
+		a.recentSpeaks[groupID] = rb
+	}
+	rb.Push(content)
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// reviewResult 发言自检结果
+type reviewResult struct {
+	Pass   bool   `json:"pass"`
+	Reason string `json:"reason"`
+}
+
+// reviewSpeak 发言后自检：用轻量模型检查是否违反人设，不合格时自动撤回
+// 在发言成功后异步调用
+func (a *Agent) reviewSpeak(groupID, msgID int64, content string) {
+	if msgID == 0 {
+		return
+	}
+	reviewModel, modelName := a.model, a.cfg.LLM.Model
+	if a.lightModel != nil {
+		reviewModel, modelName = a.lightModel, a.cfg.LightLLM.Model
+	}
+
+	p := a.personaFor(groupID)
+	prompt := fmt.Sprintf(`你是一个审核员。%s 是QQ群里的一个普通群友（人设是真人，不是机器人），刚刚在群里发了下面这句话：
+「%s」
+
+检查这句话是否有以下问题：
+1. 暴露自己是 AI、机器人、语言模型，或提到提示词、工具调用、系统指令等
+2. 包含敏感内容（政治敏感、色情、他人隐私信息等）
+3. 明显不像真人群友说的话（如 markdown 格式、客服腔、长篇说教）
+
+只输出 JSON，不要输出其他内容：{"pass": true/false, "reason": "不通过的原因，通过时留空"}`, p.GetName(), content)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	resp, err := reviewModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		zap.L().Warn("发言自检失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return
+	}
+	a.recordResponseUsage(groupID, modelName, resp)
+
+	var result reviewResult
+	if err := sonic.UnmarshalString(utils.ExtractJSON(resp.Content), &result); err != nil {
+		zap.L().Warn("解析发言自检结果失败", zap.Int64("group_id", groupID), zap.String("output", resp.Content), zap.Error(err))
+		return
+	}
+	if result.Pass {
+		return
+	}
+
+	reason := strings.TrimSpace(result.Reason)
+	if err := a.bot.DeleteMsg(msgID); err != nil {
+		zap.L().Warn("自检不通过，撤回失败", zap.Int64("group_id", groupID), zap.Int64("message_id", msgID), zap.Error(err))
+		return
+	}
+	zap.L().Named("audit").Info("发言自检不通过，已撤回",
+		zap.Int64("group_id", groupID),
+		zap.Int64("message_id", msgID),
+		zap.String("content", content),
+		zap.String("reason", reason))
+}
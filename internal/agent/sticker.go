@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/llm"
+	"mumu-bot/internal/memory"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// doSaveStickerFromMessage 收藏指定消息里的图片（不受 sticker.auto_save 限制），返回新收藏的表情包和已收藏过的数量
+func (a *Agent) doSaveStickerFromMessage(groupID, messageID int64) ([]memory.Sticker, int, error) {
+	msg, err := a.bot.GetMessageDetail(messageID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取消息失败: %w", err)
+	}
+	if msg.GroupID != groupID {
+		return nil, 0, fmt.Errorf("这条消息不是本群的")
+	}
+	if len(msg.Images) == 0 {
+		return nil, 0, fmt.Errorf("这条消息里没有图片")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var saved []memory.Sticker
+	duplicates := 0
+	for _, img := range msg.Images {
+		if img.URL == "" {
+			continue
+		}
+		desc := img.Summary
+		var info *llm.StickerInfo
+		if a.vision != nil {
+			if si, err := a.vision.DescribeSticker(ctx, img.URL); err == nil {
+				info, desc = si, si.Description
+			}
+		}
+		sticker, isDuplicate, err := a.saveSticker(groupID, img.URL, desc, info)
+		if err != nil {
+			zap.L().Warn("收藏表情包失败", zap.Int64("message_id", messageID), zap.Error(err))
+			continue
+		}
+		if isDuplicate {
+			duplicates++
+			continue
+		}
+		zap.L().Info("收藏表情包", zap.Uint("id", sticker.ID), zap.String("desc", sticker.Description))
+		saved = append(saved, *sticker)
+	}
+	if len(saved) == 0 && duplicates == 0 {
+		return nil, 0, fmt.Errorf("图片下载或保存失败")
+	}
+	return saved, duplicates, nil
+}
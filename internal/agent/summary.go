@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"mumu-bot/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// topicSummaryResult LLM 输出的单个话题摘要
+type topicSummaryResult struct {
+	Topic        string   `json:"topic"`
+	Summary      string   `json:"summary"`
+	Keywords     []string `json:"keywords"`
+	Participants []string `json:"participants"`
+	MessageIDs   []string `json:"message_ids"`
+}
+
+// summaryLoop 话题摘要定时任务
+func (a *Agent) summaryLoop() {
+	defer a.wg.Done()
+	interval := a.cfg.Memory.TopicSummary.IntervalMinutes
+	if interval <= 0 {
+		interval = 60
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			for _, gc := range a.cfg.GetGroups() {
+				if !gc.Enabled {
+					continue
+				}
+				if err := a.summarizeTopics(gc.GroupID); err != nil {
+					zap.L().Warn("话题摘要失败", zap.Int64("group_id", gc.GroupID), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// summarizeTopics 对群内未摘要的消息按话题总结，写入 TopicSummary 并标记消息已摘要
+func (a *Agent) summarizeTopics(groupID int64) error {
+	cfg := a.cfg.Memory.TopicSummary
+	minMessages := cfg.MinMessages
+	if minMessages <= 0 {
+		minMessages = 30
+	}
+	maxMessages := cfg.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = 200
+	}
+
+	msgs := a.memory.GetUnsummarizedMessages(groupID, maxMessages)
+	if len(msgs) < minMessages {
+		return nil
+	}
+
+	var chat strings.Builder
+	byMsgID := make(map[string]*memory.MessageLog, len(msgs))
+	for i := range msgs {
+		chat.WriteString(msgs[i].Content)
+		byMsgID[msgs[i].MessageID] = &msgs[i]
+	}
+
+	prompt := fmt.Sprintf(`下面是一段QQ群聊天记录，#后面的数字是消息ID。请把它按话题分组并分别总结，忽略没有实际内容的闲聊。
+只输出 JSON 数组，不要输出其他内容，格式：
+[{"topic":"话题名（10字以内）","summary":"摘要（100字以内，写清楚谁说了什么、结论是什么）","keywords":["关键词"],"participants":["参与者昵称"],"message_ids":["属于该话题的消息ID"]}]
+
+%s`, chat.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	summaryModel, modelName := a.model, a.cfg.LLM.Model
+	if a.lightModel != nil {
+		summaryModel, modelName = a.lightModel, a.cfg.LightLLM.Model
+	}
+	resp, err := summaryModel.Generate(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return err
+	}
+	a.recordResponseUsage(groupID, modelName, resp)
+
+	var results []topicSummaryResult
+	if err := sonic.UnmarshalString(utils.ExtractJSON(resp.Content), &results); err != nil {
+		return fmt.Errorf("解析摘要结果失败: %w", err)
+	}
+
+	ctx = memory.WithPersona(ctx, a.personaKey(groupID))
+	saved := 0
+	for _, r := range results {
+		if strings.TrimSpace(r.Summary) == "" {
+			continue
+		}
+		ts := &memory.TopicSummary{
+			GroupID:      groupID,
+			Topic:        r.Topic,
+			Summary:      r.Summary,
+			Keywords:     strings.Join(r.Keywords, ","),
+			Participants: strings.Join(r.Participants, ","),
+		}
+		// 根据消息 ID 计算话题的时间范围
+		for _, id := range r.MessageIDs {
+			m, ok := byMsgID[strings.TrimPrefix(id, "#")]
+			if !ok {
+				continue
+			}
+			ts.MessageCount++
+			if ts.StartTime.IsZero() || m.CreatedAt.Before(ts.StartTime) {
+				ts.StartTime = m.CreatedAt
+			}
+			if m.CreatedAt.After(ts.EndTime) {
+				ts.EndTime = m.CreatedAt
+			}
+		}
+		if ts.StartTime.IsZero() {
+			ts.StartTime, ts.EndTime = msgs[0].CreatedAt, msgs[len(msgs)-1].CreatedAt
+		}
+
+		if err := a.memory.SaveTopicSummary(ctx, ts); err != nil {
+			zap.L().Warn("保存话题摘要失败", zap.Int64("group_id", groupID), zap.Error(err))
+			continue
+		}
+		saved++
+	}
+
+	// 整批消息都标记为已摘要（包括被判定为无意义闲聊的）
+	ids := make([]uint, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+	}
+	if err := a.memory.MarkMessagesSummarized(ids); err != nil {
+		return err
+	}
+	zap.L().Info("话题摘要完成", zap.Int64("group_id", groupID), zap.Int("messages", len(msgs)), zap.Int("topics", saved))
+	return nil
+}
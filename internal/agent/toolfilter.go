@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"mumu-bot/internal/config"
+	"slices"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"go.uber.org/zap"
+)
+
+// alwaysKeptTools 不受群工具黑白名单影响的工具，缺了 speak 沐沐在群里就说不了话
+var alwaysKeptTools = []string{"speak", "stayQuiet"}
+
+// groupReactKey 群专属 ReAct 的缓存键
+type groupReactKey struct {
+	groupID int64
+	light   bool
+}
+
+// groupReact 按群工具黑白名单构建的 ReAct，sig 变化（配置热更新）时重建
+type groupReact struct {
+	sig   string
+	agent *react.Agent
+}
+
+// toolsForGroup 按群配置的工具白名单/黑名单过滤工具集合
+func (a *Agent) toolsForGroup(gc *config.GroupConfig) []tool.BaseTool {
+	result := make([]tool.BaseTool, 0, len(a.tools))
+	for _, t := range a.tools {
+		info, err := t.Info(context.Background())
+		if err != nil {
+			continue
+		}
+		name := info.Name
+		if !slices.Contains(alwaysKeptTools, name) {
+			if len(gc.ToolsWhitelist) > 0 && !slices.Contains(gc.ToolsWhitelist, name) {
+				continue
+			}
+			if slices.Contains(gc.ToolsBlacklist, name) {
+				continue
+			}
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// reactFor 返回群使用的 ReAct：未配置工具黑白名单的群共用全局实例
+func (a *Agent) reactFor(groupID int64, light bool) (*react.Agent, error) {
+	gc := a.cfg.GetGroupConfig(groupID)
+	if gc == nil || (len(gc.ToolsWhitelist) == 0 && len(gc.ToolsBlacklist) == 0) {
+		if light {
+			return a.lightReact, nil
+		}
+		return a.react, nil
+	}
+
+	key := groupReactKey{groupID: groupID, light: light}
+	sig := strings.Join(gc.ToolsWhitelist, ",") + "|" + strings.Join(gc.ToolsBlacklist, ",")
+
+	a.groupReactsMu.Lock()
+	defer a.groupReactsMu.Unlock()
+	if gr, ok := a.groupReacts[key]; ok && gr.sig == sig {
+		return gr.agent, nil
+	}
+
+	m := a.model
+	if light {
+		m = a.lightModel
+	}
+	tools := a.toolsForGroup(gc)
+	agent, err := a.newReact(m, tools)
+	if err != nil {
+		return nil, err
+	}
+	a.groupReacts[key] = &groupReact{sig: sig, agent: agent}
+	zap.L().Info("构建群专属工具集", zap.Int64("group_id", groupID), zap.Int("tools", len(tools)), zap.Bool("light", light))
+	return agent, nil
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 话题聚类参数
+const (
+	topicSimilarityThreshold = 0.3              // 消息与已有话题的字符二元组重合度阈值
+	topicFollowWindow        = 30 * time.Second // 同一人连续发言视为同一话题的时间窗口
+)
+
+// topic 一个话题线程
+type topic struct {
+	msgs    []*onebot.GroupMessage
+	bigrams map[string]struct{}
+}
+
+func (t *topic) last() *onebot.GroupMessage { return t.msgs[len(t.msgs)-1] }
+
+func (t *topic) add(m *onebot.GroupMessage, grams map[string]struct{}) {
+	t.msgs = append(t.msgs, m)
+	for g := range grams {
+		t.bigrams[g] = struct{}{}
+	}
+}
+
+// clusterTopics 把按时间排列的消息按话题分组（返回的话题按首条消息时间排序）
+// 依次使用：回复链 → 同一人短时间内连续发言 → 内容相似度，都不满足则开启新话题
+func clusterTopics(msgs []*onebot.GroupMessage) []*topic {
+	var topics []*topic
+	byMsgID := make(map[int64]*topic)
+
+	for _, m := range msgs {
+		grams := bigrams(m.Content)
+		var target *topic
+
+		// 回复链：回复的消息在哪个话题，就归到哪个话题
+		if m.Reply != nil {
+			target = byMsgID[m.Reply.MessageID]
+		}
+
+		// 同一人短时间内连续发言
+		if target == nil {
+			for _, t := range topics {
+				if t.last().UserID == m.UserID && m.Time.Sub(t.last().Time) <= topicFollowWindow &&
+					(target == nil || t.last().Time.After(target.last().Time)) {
+					target = t
+				}
+			}
+		}
+
+		// 内容相似度
+		if target == nil && len(grams) > 0 {
+			best := 0.0
+			for _, t := range topics {
+				if sim := overlap(grams, t.bigrams); sim >= topicSimilarityThreshold && sim > best {
+					best, target = sim, t
+				}
+			}
+		}
+
+		if target == nil {
+			target = &topic{bigrams: make(map[string]struct{})}
+			topics = append(topics, target)
+		}
+		target.add(m, grams)
+		byMsgID[m.MessageID] = target
+	}
+	return topics
+}
+
+// mainTopicIndex 当前主要话题：最近 5 条消息中占比最多的话题，平局取最近活跃的
+func mainTopicIndex(topics []*topic, msgs []*onebot.GroupMessage) int {
+	recent := msgs
+	if len(recent) > 5 {
+		recent = recent[len(recent)-5:]
+	}
+	counts := make(map[int]int)
+	for _, m := range recent {
+		for i, t := range topics {
+			if containsMsg(t, m) {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	mainIdx := -1
+	for i, t := range topics {
+		if mainIdx < 0 || counts[i] > counts[mainIdx] ||
+			(counts[i] == counts[mainIdx] && t.last().Time.After(topics[mainIdx].last().Time)) {
+			mainIdx = i
+		}
+	}
+	return mainIdx
+}
+
+// formatTopics 按话题分组格式化聊天上下文，返回聊天上下文和主要话题描述
+func formatTopics(msgs []*onebot.GroupMessage) (string, string) {
+	topics := clusterTopics(msgs)
+	if len(topics) <= 1 {
+		var b strings.Builder
+		for _, m := range msgs {
+			b.WriteString(m.FinalContent)
+		}
+		return b.String(), ""
+	}
+
+	mainIdx := mainTopicIndex(topics, msgs)
+	var b strings.Builder
+	for i, t := range topics {
+		mark := ""
+		if i == mainIdx {
+			mark = "（当前主要话题）"
+		}
+		b.WriteString(fmt.Sprintf("### 话题%d%s\n", i+1, mark))
+		for _, m := range t.msgs {
+			b.WriteString(m.FinalContent)
+		}
+	}
+
+	mt := topics[mainIdx]
+	starter := []rune(strings.TrimSpace(mt.msgs[0].Content))
+	if len(starter) > 30 {
+		starter = append(starter[:30], '…')
+	}
+	mainTopic := fmt.Sprintf("话题%d：由 %s 的「%s」开始，共 %d 条消息", mainIdx+1, mt.msgs[0].Nickname, string(starter), len(mt.msgs))
+	return b.String(), mainTopic
+}
+
+// bigrams 提取文本的字符二元组（忽略空白和标点）
+func bigrams(text string) map[string]struct{} {
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	grams := make(map[string]struct{})
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+// overlap 计算 a 中有多少比例的元素出现在 b 中
+// 话题的二元组集合会随消息增多而变大，用重合度比 Jaccard 更不容易被稀释
+func overlap(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a))
+}
+
+func containsMsg(t *topic, m *onebot.GroupMessage) bool {
+	for _, tm := range t.msgs {
+		if tm == m {
+			return true
+		}
+	}
+	return false
+}
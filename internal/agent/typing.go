@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 打字延迟上下限
+const (
+	minTypingDelay = 500 * time.Millisecond
+	maxTypingDelay = 6 * time.Second
+)
+
+// typingDelay 计算模拟打字的延迟
+// 在字数/打字速度的基础上考虑：精力低时打字慢、内容复杂（数字、英文、标点多）时慢、
+// 群里正在激烈讨论时快，最后加入随机抖动
+func (a *Agent) typingDelay(groupID int64, content string) time.Duration {
+	speed := float64(a.cfg.Chat.TypingSpeed)
+	if speed <= 0 {
+		speed = 6
+	}
+
+	// 精力：0.5 时不变，精力越低越慢
+	if mood, err := a.memory.GetMoodState(); err == nil {
+		speed *= 0.7 + 0.6*mood.Energy
+	}
+
+	// 内容复杂度：数字、英文和标点需要切换输入法，打得更慢
+	runes := []rune(content)
+	if len(runes) > 0 {
+		hard := 0
+		for _, r := range runes {
+			if unicode.IsDigit(r) || unicode.IsPunct(r) || (r < unicode.MaxASCII && unicode.IsLetter(r)) {
+				hard++
+			}
+		}
+		speed /= 1 + 0.5*float64(hard)/float64(len(runes))
+	}
+
+	// 激烈讨论：最近一分钟消息很多或感叹号/问号很多时打字更快
+	if a.isHeated(groupID) {
+		speed *= 1.4
+	}
+
+	delay := float64(len(runes)) / speed * float64(time.Second)
+
+	// 随机抖动
+	jitter := a.cfg.Chat.TypingJitter
+	if jitter == 0 {
+		jitter = 0.25
+	}
+	if jitter > 0 {
+		delay *= 1 + jitter*(2*rand.Float64()-1)
+	}
+
+	return min(max(time.Duration(delay), minTypingDelay), maxTypingDelay)
+}
+
+// isHeated 判断群里是否正在激烈讨论
+func (a *Agent) isHeated(groupID int64) bool {
+	selfID := a.bot.GetSelfID()
+	count, excited := 0, 0
+	for _, m := range a.getBuffer(groupID) {
+		if m.UserID == selfID || time.Since(m.Time) > time.Minute {
+			continue
+		}
+		count++
+		if strings.ContainsAny(m.Content, "!！?？") {
+			excited++
+		}
+	}
+	return count >= 6 || (count >= 3 && excited*2 >= count)
+}
@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/onebot"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// doSpeakVoice 把文字合成语音发到群里，和文字发言一样经过防复读和内容过滤
+func (a *Agent) doSpeakVoice(groupID int64, content string) (int64, error) {
+	if err := a.checkRepeat(groupID, content); err != nil {
+		return 0, err
+	}
+	res := a.speakFilter.Apply(groupID, content)
+	if res.Blocked {
+		return 0, fmt.Errorf("这句话包含不适合发送的内容（命中规则「%s」），换个说法", res.Hits[len(res.Hits)-1].Rule)
+	}
+	content = res.Content
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	audio, err := a.tts.Synthesize(ctx, content)
+	if err != nil {
+		zap.L().Warn("语音合成失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return 0, fmt.Errorf("语音合成失败，用文字说吧")
+	}
+
+	msgID, err := a.bot.SendRecordMessage(groupID, audio)
+	if err != nil {
+		zap.L().Error("发送语音失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return 0, fmt.Errorf("发送失败: %w", err)
+	}
+	a.recordSpeak(groupID, content)
+	a.markSpoke(groupID)
+
+	// 回写自己的消息，保留语音的文字内容
+	a.onMessage(&onebot.GroupMessage{
+		MessageID:   msgID,
+		GroupID:     groupID,
+		UserID:      a.bot.GetSelfID(),
+		Nickname:    a.personaFor(groupID).GetName(),
+		Content:     fmt.Sprintf("[语音:%s]", content),
+		Time:        time.Now(),
+		MessageType: "group",
+	})
+	zap.L().Info("发送语音成功", zap.Int64("group_id", groupID), zap.String("content", content))
+	return msgID, nil
+}
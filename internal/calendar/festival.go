@@ -0,0 +1,55 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// solarFestivals 公历节日（MM-DD）
+var solarFestivals = map[string]string{
+	"01-01": "元旦",
+	"02-14": "情人节",
+	"03-08": "妇女节",
+	"04-01": "愚人节",
+	"05-01": "劳动节",
+	"05-04": "青年节",
+	"06-01": "儿童节",
+	"09-10": "教师节",
+	"10-01": "国庆节",
+	"10-31": "万圣夜",
+	"12-24": "平安夜",
+	"12-25": "圣诞节",
+}
+
+// lunarFestivals 农历节日（MM-DD，仅非闰月）
+var lunarFestivals = map[string]string{
+	"01-01": "春节",
+	"01-15": "元宵节",
+	"02-02": "龙抬头",
+	"05-05": "端午节",
+	"07-07": "七夕",
+	"07-15": "中元节",
+	"08-15": "中秋节",
+	"09-09": "重阳节",
+	"12-08": "腊八节",
+	"12-23": "小年",
+}
+
+// Festivals 获取某天的节日列表
+func Festivals(t time.Time) []string {
+	var result []string
+	if name, ok := solarFestivals[t.Format("01-02")]; ok {
+		result = append(result, name)
+	}
+
+	if l, ok := ToLunar(t); ok && !l.IsLeap {
+		if name, ok := lunarFestivals[fmt.Sprintf("%02d-%02d", l.Month, l.Day)]; ok {
+			result = append(result, name)
+		}
+	}
+	// 除夕：农历新年的前一天（腊月可能只有 29 天）
+	if l, ok := ToLunar(t.AddDate(0, 0, 1)); ok && l.Month == 1 && l.Day == 1 && !l.IsLeap {
+		result = append(result, "除夕")
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import "time"
+
+// lunarInfo 2000-2060 年农历数据
+// 低 4 位：闰月月份（0 表示无闰月）
+// 第 5-16 位：1-12 月的大小月（1 为大月 30 天，0 为小月 29 天），从高位到低位依次为 1 月到 12 月
+// 第 17 位：闰月是否为大月
+var lunarInfo = []int{
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050-2059
+	0x092e0, // 2060
+}
+
+const lunarBaseYear = 2000
+
+// lunarBaseDate 2000 年正月初一对应的公历日期
+var lunarBaseDate = time.Date(2000, 2, 5, 0, 0, 0, 0, time.UTC)
+
+// Lunar 农历日期
+type Lunar struct {
+	Year   int
+	Month  int
+	Day    int
+	IsLeap bool // 是否闰月
+}
+
+// leapMonth 闰月月份，无闰月返回 0
+func leapMonth(info int) int {
+	return info & 0xf
+}
+
+// leapDays 闰月天数
+func leapDays(info int) int {
+	if leapMonth(info) == 0 {
+		return 0
+	}
+	if info&0x10000 != 0 {
+		return 30
+	}
+	return 29
+}
+
+// monthDays 农历某月（非闰月）天数
+func monthDays(info, month int) int {
+	if info&(0x10000>>month) != 0 {
+		return 30
+	}
+	return 29
+}
+
+// yearDays 农历某年总天数
+func yearDays(info int) int {
+	days := 0
+	for m := 1; m <= 12; m++ {
+		days += monthDays(info, m)
+	}
+	return days + leapDays(info)
+}
+
+// ToLunar 公历转农历，超出支持范围（2000-2060 年）时返回 false
+func ToLunar(t time.Time) (Lunar, bool) {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := int(date.Sub(lunarBaseDate).Hours() / 24)
+	if offset < 0 {
+		return Lunar{}, false
+	}
+
+	year := lunarBaseYear
+	for i := 0; i < len(lunarInfo); i++ {
+		days := yearDays(lunarInfo[i])
+		if offset < days {
+			break
+		}
+		offset -= days
+		year++
+	}
+	if year-lunarBaseYear >= len(lunarInfo) {
+		return Lunar{}, false
+	}
+
+	info := lunarInfo[year-lunarBaseYear]
+	leap := leapMonth(info)
+	for m := 1; m <= 12; m++ {
+		days := monthDays(info, m)
+		if offset < days {
+			return Lunar{Year: year, Month: m, Day: offset + 1}, true
+		}
+		offset -= days
+
+		if m == leap {
+			if offset < leapDays(info) {
+				return Lunar{Year: year, Month: m, Day: offset + 1, IsLeap: true}, true
+			}
+			offset -= leapDays(info)
+		}
+	}
+	return Lunar{}, false
+}
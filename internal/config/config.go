@@ -10,21 +10,37 @@ import (
 var (
 	cfg  *Config
 	once sync.Once
+
+	// groupsMu 保护运行时对群配置的修改（如启用新发现的群）
+	groupsMu sync.RWMutex
+	// runtimeMu 保护通过 API 热更新的聊天参数（发言频率、发言冷却）
+	runtimeMu sync.RWMutex
 )
 
 // Config 全局配置结构
 type Config struct {
 	App       AppConfig       `yaml:"app"`
 	Persona   PersonaConfig   `yaml:"persona"`
+	Personas  []PersonaConfig `yaml:"personas"` // 额外的具名人格，供群配置按名称引用
 	OneBot    OneBotConfig    `yaml:"onebot"`
 	Groups    []GroupConfig   `yaml:"groups"`
 	Agent     AgentConfig     `yaml:"agent"`
-	Chat      ChatConfig      `yaml:"chat"` // 聊天行为配置
+	Chat      ChatConfig      `yaml:"chat"`    // 聊天行为配置
+	Filter    FilterConfig    `yaml:"filter"`  // 发言内容过滤配置
+	Command   CommandConfig   `yaml:"command"` // 群内管理命令配置
 	LLM       LLMConfig       `yaml:"llm"`
+	LightLLM  LLMConfig       `yaml:"light_llm"` // 便宜的轻量模型（超预算降级等），model 留空表示不启用
+	Budget    BudgetConfig    `yaml:"budget"`    // token 用量预算
+	Calendar  CalendarConfig  `yaml:"calendar"`  // 节日与纪念日
 	Embedding EmbeddingConfig `yaml:"embedding"`
 	VisionLLM VisionLLMConfig `yaml:"vision_llm"`
+	TTS       TTSConfig       `yaml:"tts"`     // 语音合成
+	Weather   WeatherConfig   `yaml:"weather"` // 天气查询
 	Memory    MemoryConfig    `yaml:"memory"`
 	Sticker   StickerConfig   `yaml:"sticker"` // 表情包配置
+	Image     ImageConfig     `yaml:"image"`   // 发送网络图片
+	Backup    BackupConfig    `yaml:"backup"`  // 定时备份
+	Plugins   PluginsConfig   `yaml:"plugins"` // 自定义工具插件
 	Server    ServerConfig    `yaml:"server"`
 	Debug     DebugConfig     `yaml:"debug"` // 调试配置
 }
@@ -57,6 +73,13 @@ type GroupConfig struct {
 	GroupID     int64  `yaml:"group_id"`
 	Enabled     bool   `yaml:"enabled"`
 	ExtraPrompt string `yaml:"extra_prompt"` // 群专属额外提示词
+	Persona     string `yaml:"persona"`      // 使用的人格名称（对应 personas 中的 name），留空使用默认人格
+	DailyTokens int64  `yaml:"daily_tokens"` // 该群每日 token 预算，0 使用 budget.group_daily_tokens
+	MaxMemories int    `yaml:"max_memories"` // 该群长期记忆条数上限，0 使用 memory.quota.max_per_group
+	AllowMute   bool   `yaml:"allow_mute"`   // 是否允许在该群使用禁言工具（需要是管理员）
+
+	ToolsWhitelist []string `yaml:"tools_whitelist"` // 该群只提供这些工具（speak、stayQuiet 始终保留），为空表示不限制
+	ToolsBlacklist []string `yaml:"tools_blacklist"` // 该群不提供这些工具（speak、stayQuiet 不能禁用）
 }
 
 // AgentConfig Agent决策配置
@@ -65,15 +88,145 @@ type AgentConfig struct {
 	ThinkInterval     int `yaml:"think_interval"`      // 决策间隔（秒）
 	MessageBufferSize int `yaml:"message_buffer_size"` // 消息缓冲区大小
 	MaxStep           int `yaml:"max_step"`            // ReAct 最大步数
+	BufferRestore     int `yaml:"buffer_restore"`      // 启动时从消息日志回填缓冲区的条数，0 使用 message_buffer_size，负数表示不回填
+
+	InterruptOnMention  bool `yaml:"interrupt_on_mention"`  // 思考中再次被 @ 时是否打断当前思考（否则排队等当前思考结束）
+	MaxConcurrentThinks int  `yaml:"max_concurrent_thinks"` // 全局同时进行的思考数上限，超出时排队（被 @ 的群优先），0 表示不限制
+	TopicTracking       bool `yaml:"topic_tracking"`        // 是否启用话题跟踪（按话题分组构建聊天上下文）
+	GroupInfoRefresh    int  `yaml:"group_info_refresh"`    // 刷新群名、人数、公告的间隔（小时），默认 6
+
+	Burst       BurstConfig       `yaml:"burst"`        // 基于消息速率的突发检测（开启后替代定时思考周期）
+	Prejudge    PrejudgeConfig    `yaml:"prejudge"`     // 思考前的预判阶段
+	ChatContext ChatContextConfig `yaml:"chat_context"` // 聊天上下文格式
+
+	// ToolRateLimits 按工具名配置的频率限制（每个群单独计数），未配置时 poke、reactToMessage 使用内置默认值
+	ToolRateLimits map[string]ToolRateLimit `yaml:"tool_rate_limits"`
+	ToolTimeout    ToolTimeoutConfig        `yaml:"tool_timeout"` // 工具执行超时
+
+	// ToolCache 按工具名配置的结果缓存时间（秒，每个群单独缓存），0 表示不缓存，未配置时查询类工具使用内置默认值
+	ToolCache map[string]int `yaml:"tool_cache"`
+}
+
+// ToolTimeoutConfig 工具执行超时配置
+type ToolTimeoutConfig struct {
+	Default       int            `yaml:"default"`        // 默认超时（秒），默认 30，负数表示不限制
+	SlowThreshold int            `yaml:"slow_threshold"` // 执行超过该时长（秒）记为慢调用，默认 5
+	Tools         map[string]int `yaml:"tools"`          // 按工具名单独配置的超时（秒），未配置时 drawImage 使用 120
+}
+
+// ToolRateLimit 单个工具的频率限制
+type ToolRateLimit struct {
+	Max    int `yaml:"max"`    // 时间窗口内最多调用次数，0 表示不限制
+	Window int `yaml:"window"` // 时间窗口（秒）
+}
+
+// BurstConfig 消息突发检测配置
+type BurstConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	Window    int  `yaml:"window"`    // 统计消息速率的窗口（秒），默认 10
+	Threshold int  `yaml:"threshold"` // 窗口内消息数达到该值视为突发，默认 5
+	QuietGap  int  `yaml:"quiet_gap"` // 突发时等群里安静多久（秒）再思考，默认 4
+	MaxWait   int  `yaml:"max_wait"`  // 合并等待的最长时间（秒），默认 20
+}
+
+// ChatContextConfig 聊天上下文格式配置
+type ChatContextConfig struct {
+	// Format 消息行模板（Go template），可用字段：.Time .MessageID .ShowID .Nickname .UserID .Reply .Content
+	// 留空使用默认格式：[时间] #消息ID 昵称(QQ号): [回复] 内容
+	Format        string `yaml:"format"`
+	TimeFormat    string `yaml:"time_format"`     // 时间格式（Go 时间布局），默认 "15:04:05"
+	ShowMessageID *bool  `yaml:"show_message_id"` // 是否显示消息ID，默认 true（关闭后 LLM 无法回复/撤回指定消息）
+}
+
+// PrejudgeConfig 预判配置（先快速判断是否可能发言，再决定是否进入完整 ReAct）
+type PrejudgeConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Mode    string `yaml:"mode"` // rule（规则）, model（轻量模型，未配置 light_llm 时回退到规则），默认 rule
 }
 
 // ChatConfig 聊天行为配置
 type ChatConfig struct {
-	TalkFrequency    float64          `yaml:"talk_frequency"`    // 聊天频率，0-1，越大越活跃
-	TypingSimulation bool             `yaml:"typing_simulation"` // 是否模拟打字延迟
-	TypingSpeed      int              `yaml:"typing_speed"`      // 每秒打字速度（字符）
-	EnableTimeRules  bool             `yaml:"enable_time_rules"` // 是否启用时段规则
-	TimeRules        []TimeRuleConfig `yaml:"time_rules"`        // 时段发言频率规则
+	TalkFrequency    float64          `yaml:"talk_frequency"`     // 聊天频率，0-1，越大越活跃
+	TypingSimulation bool             `yaml:"typing_simulation"`  // 是否模拟打字延迟
+	TypingSpeed      int              `yaml:"typing_speed"`       // 每秒打字速度（字符）
+	TypingJitter     float64          `yaml:"typing_jitter"`      // 打字延迟的随机抖动比例（0-1），默认 0.25，负数表示不抖动
+	EnableTimeRules  bool             `yaml:"enable_time_rules"`  // 是否启用时段规则
+	TimeRules        []TimeRuleConfig `yaml:"time_rules"`         // 时段发言频率规则
+	RepeatCheckCount int              `yaml:"repeat_check_count"` // 防复读：与最近 N 条自己的发言比对，默认 5，负数表示关闭
+	RepeatSimilarity float64          `yaml:"repeat_similarity"`  // 防复读：相似度阈值（0-1），超过则拦截，默认 0.8
+	PostSpeakReview  bool             `yaml:"post_speak_review"`  // 发言后自检（优先使用 light_llm），违反人设时自动撤回
+
+	InterestBoost      float64 `yaml:"interest_boost"`      // 最近消息命中兴趣话题时发言概率的乘数，默认 1.5
+	UninterestedFactor float64 `yaml:"uninterested_factor"` // 未命中兴趣话题时发言概率的乘数，默认 0.8（设为 1 关闭）
+	MentionAllFactor   float64 `yaml:"mention_all_factor"`  // 最新消息是 @全体成员 通知时发言概率的乘数，默认 0.3（设为 1 关闭）
+
+	MoodFactor MoodFactorConfig `yaml:"mood_factor"` // 情绪对发言概率的影响
+
+	SpeakCooldown CooldownConfig `yaml:"speak_cooldown"` // 主动发言冷却（被 @ 时不受限制）
+
+	ProactivePrivate ProactivePrivateConfig `yaml:"proactive_private"` // 主动私聊关心熟人
+
+	Mute MuteConfig `yaml:"mute"` // 禁言工具（只在群配置 allow_mute 的群可用）
+
+	PrivateMessage PrivateMessageConfig `yaml:"private_message"` // sendPrivateMessage 工具
+	Like           LikeConfig           `yaml:"like"`            // sendLike 工具
+	GroupForward   GroupForwardConfig   `yaml:"group_forward"`   // forwardToGroup 工具
+}
+
+// GroupForwardConfig 跨群转发工具配置
+type GroupForwardConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	Groups     []int64 `yaml:"groups"`      // 允许互相转发的群，来源群和目标群都要在名单内，目标群还需已启用
+	DailyLimit int     `yaml:"daily_limit"` // 每天最多转发多少条，默认 5，-1 表示不限制
+}
+
+// PrivateMessageConfig 私聊发送工具配置
+type PrivateMessageConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	MinIntimacy float64 `yaml:"min_intimacy"` // 亲密度不低于该值才能私聊，默认 0.6
+	DailyLimit  int     `yaml:"daily_limit"`  // 每天最多发多少条私聊，默认 10，-1 表示不限制
+}
+
+// LikeConfig 资料卡点赞工具配置
+type LikeConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	MinIntimacy  float64 `yaml:"min_intimacy"`   // 亲密度不低于该值才会点赞，默认 0.5
+	Times        int     `yaml:"times"`          // 每次点几个赞，默认 10（非会员每天最多给同一人点 10 个）
+	DailyPerUser int     `yaml:"daily_per_user"` // 每天最多给同一个人点几次，默认 1
+}
+
+// MuteConfig 禁言工具配置
+type MuteConfig struct {
+	MaxMinutes int     `yaml:"max_minutes"` // 单次禁言最长时间（分钟），默认 10
+	Protected  []int64 `yaml:"protected"`   // 永远不会被禁言的QQ号（command.admins 也会受保护）
+}
+
+// ProactivePrivateConfig 主动私聊配置
+type ProactivePrivateConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	Time         string  `yaml:"time"`          // 每天检查的时间，如 "20:00"，默认 "20:00"
+	MinIntimacy  float64 `yaml:"min_intimacy"`  // 亲密度下限，默认 0.8
+	InactiveDays int     `yaml:"inactive_days"` // 多少天没发言才关心，默认 3
+	MaxPerDay    int     `yaml:"max_per_day"`   // 每天最多私聊几个人，默认 1
+	UserInterval int     `yaml:"user_interval"` // 同一个人两次关心的最小间隔（天），默认 14
+}
+
+// MoodFactorConfig 情绪影响发言概率的配置
+// 乘数 = 1 + 1.2 × (0.7×社交意愿 + 0.3×精力 - 0.5)，再限制在 [Min, Max] 内
+type MoodFactorConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Min     float64 `yaml:"min"` // 乘数下限，默认 0.3
+	Max     float64 `yaml:"max"` // 乘数上限，默认 1.5
+}
+
+// CooldownConfig 发言冷却配置
+type CooldownConfig struct {
+	Base       int     `yaml:"base"`        // 基础冷却时间（秒），0 表示不冷却
+	Adaptive   bool    `yaml:"adaptive"`    // 是否根据群活跃度自适应调整
+	Window     int     `yaml:"window"`      // 统计消息密度的时间窗口（秒），默认 300
+	RefDensity float64 `yaml:"ref_density"` // 参考消息密度（条/分钟），密度等于该值时冷却为基础值，默认 2
+	MinFactor  float64 `yaml:"min_factor"`  // 冷却系数下限（群很热闹时），默认 0.3
+	MaxFactor  float64 `yaml:"max_factor"`  // 冷却系数上限（群很冷清时），默认 3
 }
 
 // TimeRuleConfig 时段规则配置
@@ -83,6 +236,30 @@ type TimeRuleConfig struct {
 	TalkValue float64 `yaml:"talk_value"` // 该时段的发言频率
 }
 
+// FilterConfig 发言内容过滤配置
+type FilterConfig struct {
+	Enabled bool               `yaml:"enabled"`
+	Rules   []FilterRuleConfig `yaml:"rules"` // 按顺序依次应用
+}
+
+// FilterRuleConfig 过滤规则配置
+type FilterRuleConfig struct {
+	Name        string   `yaml:"name"`        // 规则名（用于审计日志）
+	Type        string   `yaml:"type"`        // keyword, regex, phone, id_card, long_url
+	Patterns    []string `yaml:"patterns"`    // keyword/regex 类型的匹配内容
+	MaxLength   int      `yaml:"max_length"`  // long_url 类型：超过该长度的链接才算命中，默认 60
+	Action      string   `yaml:"action"`      // mask（打码）, block（拦截）, replace（替换），默认 mask
+	Replacement string   `yaml:"replacement"` // replace 动作的替换文本，默认 "[已屏蔽]"
+}
+
+// CommandConfig 群内管理命令配置
+type CommandConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	Prefix          string  `yaml:"prefix"`            // 命令前缀，默认 "#"
+	Admins          []int64 `yaml:"admins"`            // 可使用命令的QQ号（群主始终可用）
+	AllowGroupAdmin bool    `yaml:"allow_group_admin"` // 群管理员是否可使用命令
+}
+
 // LLMConfig LLM 配置
 type LLMConfig struct {
 	APIKey      string                 `yaml:"api_key"`
@@ -91,6 +268,22 @@ type LLMConfig struct {
 	ExtraFields map[string]interface{} `yaml:"extra_fields"` // 额外参数
 }
 
+// BudgetConfig token 用量预算配置
+type BudgetConfig struct {
+	Enabled          bool    `yaml:"enabled"`
+	DailyTokens      int64   `yaml:"daily_tokens"`       // 全局每日 token 预算，0 表示不限制
+	GroupDailyTokens int64   `yaml:"group_daily_tokens"` // 每个群的默认每日 token 预算，0 表示不限制
+	Action           string  `yaml:"action"`             // 超额后的处理：reduce（降低发言频率）, switch（切换到 light_llm）
+	ReduceFactor     float64 `yaml:"reduce_factor"`      // reduce 时发言概率的乘数，默认 0.3
+}
+
+// CalendarConfig 节日与纪念日配置
+type CalendarConfig struct {
+	Enabled      bool   `yaml:"enabled"`       // 在提示词中注入当天的节日和群友生日
+	Greeting     bool   `yaml:"greeting"`      // 节日/生日当天主动发祝福
+	GreetingTime string `yaml:"greeting_time"` // 主动祝福的时间，如 "09:00"，默认 "09:00"
+}
+
 // EmbeddingConfig Embedding 模型配置
 type EmbeddingConfig struct {
 	Enabled bool   `yaml:"enabled"`
@@ -107,19 +300,127 @@ type VisionLLMConfig struct {
 	Model   string `yaml:"model"`
 }
 
+// TTSConfig 语音合成配置
+type TTSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Provider   string `yaml:"provider"` // openai（OpenAI 兼容的 /audio/speech，默认）、local（本地 TTS 服务）
+	APIKey     string `yaml:"api_key"`
+	BaseURL    string `yaml:"base_url"` // openai 为 API 地址（如 https://api.openai.com/v1），local 为合成接口的完整地址
+	Model      string `yaml:"model"`
+	Voice      string `yaml:"voice"`
+	Format     string `yaml:"format"`      // 音频格式，默认 mp3
+	MaxChars   int    `yaml:"max_chars"`   // 单条语音最多多少字，默认 100
+	DailyLimit int    `yaml:"daily_limit"` // 每天最多发送多少条语音，默认 20，-1 表示不限制
+}
+
+// WeatherConfig 天气查询配置
+type WeatherConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Provider    string `yaml:"provider"` // qweather（和风天气，默认）、openweather（OpenWeather）
+	APIKey      string `yaml:"api_key"`
+	APIHost     string `yaml:"api_host"`     // 和风天气控制台分配的 API Host，留空使用公共地址
+	DefaultCity string `yaml:"default_city"` // 没说城市时查询的城市
+}
+
 // MemoryConfig 记忆系统配置
 type MemoryConfig struct {
+	Driver            string                  `yaml:"driver"` // 数据库驱动：mysql（默认）、postgres
 	MySQL             MySQLConfig             `yaml:"mysql"`
+	Postgres          PostgresConfig          `yaml:"postgres"`
 	Milvus            MilvusConfig            `yaml:"milvus"`
+	Vector            VectorConfig            `yaml:"vector"`
 	LongTerm          LongTermConfig          `yaml:"long_term"`
 	MessageLogCleanup MessageLogCleanupConfig `yaml:"message_log_cleanup"`
+	PersonaIsolation  bool                    `yaml:"persona_isolation"` // 不同人格的长期记忆是否相互隔离，默认共享
+	Diary             DiaryConfig             `yaml:"diary"`
+	TopicSummary      TopicSummaryConfig      `yaml:"topic_summary"`
+	Consolidation     ConsolidationConfig     `yaml:"consolidation"`
+	Decay             DecayConfig             `yaml:"decay"`
+	ExpressionPrune   ExpressionPruneConfig   `yaml:"expression_prune"`
+	TTL               MemoryTTLConfig         `yaml:"ttl"`
+	Quota             MemoryQuotaConfig       `yaml:"quota"`
+	MessageBatch      MessageBatchConfig      `yaml:"message_batch"`
+	SlowQueryMs       int                     `yaml:"slow_query_ms"` // 慢查询阈值（毫秒），超过时以 Warn 级别记录，默认 200
+	AutoMigrate       *bool                   `yaml:"auto_migrate"`  // 启动时自动执行数据库迁移，默认 true；关闭后需手动执行 migrate up
+	Intimacy          IntimacyConfig          `yaml:"intimacy"`      // 亲密度演化
+	CacheTTL          int                     `yaml:"cache_ttl"`     // 用户档案、群信息、情绪等热数据的进程内缓存有效期（秒），默认 30，-1 关闭
+}
+
+// MessageBatchConfig 消息日志批量写入配置
+type MessageBatchConfig struct {
+	Size            int `yaml:"size"`              // 攒够多少条写入一次，默认 50，设为 1 时逐条写入
+	FlushIntervalMs int `yaml:"flush_interval_ms"` // 最长多久写入一次（毫秒），默认 2000
+}
+
+// MemoryQuotaConfig 分群记忆配额配置
+type MemoryQuotaConfig struct {
+	MaxPerGroup int `yaml:"max_per_group"` // 每个群的长期记忆条数上限，0 表示不限制
+}
+
+// MemoryQuota 获取某群的长期记忆条数上限，0 表示不限制
+func (c *Config) MemoryQuota(groupID int64) int {
+	if gc := c.GetGroupConfig(groupID); gc != nil && gc.MaxMemories > 0 {
+		return gc.MaxMemories
+	}
+	return c.Memory.Quota.MaxPerGroup
+}
+
+// MemoryTTLConfig 记忆按类型过期归档配置
+type MemoryTTLConfig struct {
+	Enabled       bool           `yaml:"enabled"`
+	IntervalHours int            `yaml:"interval_hours"` // 检查间隔（小时），默认 24
+	Days          map[string]int `yaml:"days"`           // 各记忆类型的保留天数，未配置或为 0 表示永久保留；为空时默认 conversation 90 天
+}
+
+// ExpressionPruneConfig 表达方式淘汰配置
+type ExpressionPruneConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	IntervalHours   int     `yaml:"interval_hours"`    // 淘汰任务间隔（小时），默认 24
+	UnusedDays      int     `yaml:"unused_days"`       // 超过多少天没被模仿过就淘汰，默认 60
+	MinUses         int     `yaml:"min_uses"`          // 至少模仿多少次后才按回应率评估，默认 5
+	MinResponseRate float64 `yaml:"min_response_rate"` // 回应率低于该值时淘汰，默认 0.1
+}
+
+// DecayConfig 记忆衰减（遗忘曲线）配置
+type DecayConfig struct {
+	Enabled           bool    `yaml:"enabled"`
+	IntervalHours     int     `yaml:"interval_hours"`     // 衰减任务间隔（小时），默认 24
+	GraceDays         int     `yaml:"grace_days"`         // 多少天未被访问才开始衰减，默认 7
+	StabilityDays     float64 `yaml:"stability_days"`     // 未被访问过的记忆的稳定度（天），越大忘得越慢，默认 30
+	ArchiveThreshold  float64 `yaml:"archive_threshold"`  // 重要性低于该值时归档，默认 0.2
+	ProtectImportance float64 `yaml:"protect_importance"` // 重要性不低于该值的记忆不衰减，默认 0.9
+}
+
+// ConsolidationConfig 记忆巩固配置
+type ConsolidationConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	IntervalHours int  `yaml:"interval_hours"` // 巩固任务间隔（小时），默认 24
+	MinMemories   int  `yaml:"min_memories"`   // 同一群同类记忆达到该数量才巩固，默认 20
+	BatchSize     int  `yaml:"batch_size"`     // 单次交给 LLM 合并的记忆数，默认 50
+}
+
+// TopicSummaryConfig 话题摘要配置
+type TopicSummaryConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	IntervalMinutes int  `yaml:"interval_minutes"` // 摘要任务间隔（分钟），默认 60
+	MinMessages     int  `yaml:"min_messages"`     // 未摘要消息达到该数量才进行摘要，默认 30
+	MaxMessages     int  `yaml:"max_messages"`     // 单次摘要的最大消息数，默认 200
+}
+
+// DiaryConfig 每日日记配置
+type DiaryConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Time        string `yaml:"time"`         // 每天写日记的时间，如 "23:30"，默认 "23:30"
+	MaxMessages int    `yaml:"max_messages"` // 每个群回顾的最大消息数，默认 300
 }
 
 // MessageLogCleanupConfig 消息日志清理配置
 type MessageLogCleanupConfig struct {
-	Enabled       *bool `yaml:"enabled"`        // 是否启用，默认 true
-	IntervalHours int   `yaml:"interval_hours"` // 清理间隔（小时），默认 6
-	KeepLatest    int   `yaml:"keep_latest"`    // 每个群保留最新消息数
+	Enabled       *bool  `yaml:"enabled"`        // 是否启用，默认 true
+	IntervalHours int    `yaml:"interval_hours"` // 清理间隔（小时），默认 6
+	KeepLatest    int    `yaml:"keep_latest"`    // 每个群保留最新消息数
+	Archive       string `yaml:"archive"`        // 归档模式：空为直接删除，table 转存到 message_archives 表，file 写入压缩的 JSONL 文件
+	ArchivePath   string `yaml:"archive_path"`   // file 模式的归档目录，默认 data/message_archive
 }
 
 // MySQLConfig MySQL 数据库配置
@@ -131,6 +432,16 @@ type MySQLConfig struct {
 	DBName   string `yaml:"db_name"`
 }
 
+// PostgresConfig PostgreSQL 数据库配置
+type PostgresConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"db_name"`
+	SSLMode  string `yaml:"ssl_mode"` // 默认 disable
+}
+
 // MilvusConfig Milvus 向量数据库配置
 type MilvusConfig struct {
 	Enabled        bool   `yaml:"enabled"`
@@ -141,11 +452,112 @@ type MilvusConfig struct {
 	MetricType     string `yaml:"metric_type"` // IP, L2, COSINE
 }
 
+// VectorConfig 向量存储配置
+type VectorConfig struct {
+	Provider string         `yaml:"provider"` // milvus、qdrant、pgvector、local、none；留空时启用了 Milvus 则用 Milvus，否则用进程内索引
+	Qdrant   QdrantConfig   `yaml:"qdrant"`
+	PGVector PGVectorConfig `yaml:"pgvector"`
+}
+
+// PGVectorConfig pgvector 配置（需要 memory.driver 为 postgres）
+type PGVectorConfig struct {
+	TableName string `yaml:"table_name"` // 向量表名，默认 memory_vectors
+	VectorDim int    `yaml:"vector_dim"`
+}
+
+// QdrantConfig Qdrant 向量数据库配置
+type QdrantConfig struct {
+	Address        string `yaml:"address"` // REST 地址，如 http://localhost:6333
+	APIKey         string `yaml:"api_key"`
+	CollectionName string `yaml:"collection_name"`
+	VectorDim      int    `yaml:"vector_dim"`
+	Distance       string `yaml:"distance"` // Cosine, Dot, Euclid
+}
+
+// VectorProvider 获取实际使用的向量存储，返回空表示不使用向量检索
+func (m *MemoryConfig) VectorProvider() string {
+	switch {
+	case m.Vector.Provider == "none":
+		return ""
+	case m.Vector.Provider != "":
+		return m.Vector.Provider
+	case m.Milvus.Enabled:
+		return "milvus"
+	default:
+		// 没有部署向量数据库时使用进程内索引兜底
+		return "local"
+	}
+}
+
+// VectorDim 获取当前向量存储的向量维度
+func (m *MemoryConfig) VectorDim() int {
+	switch m.VectorProvider() {
+	case "qdrant":
+		return m.Vector.Qdrant.VectorDim
+	case "pgvector":
+		return m.Vector.PGVector.VectorDim
+	}
+	return m.Milvus.VectorDim
+}
+
 // LongTermConfig 长期记忆配置
 type LongTermConfig struct {
 	TopK                int     `yaml:"top_k"`                // 检索返回数量
 	SimilarityThreshold float64 `yaml:"similarity_threshold"` // 相似度阈值
 	ImportanceThreshold float64 `yaml:"importance_threshold"` // 重要性阈值
+	DedupThreshold      float64 `yaml:"dedup_threshold"`      // 保存时与已有记忆的相似度超过该值则合并而不新建，默认 0.92
+	Enrich              bool    `yaml:"enrich"`               // 保存记忆后异步用 LLM 生成摘要和关键词（优先使用轻量模型）
+
+	RankWeights         RankWeightsConfig `yaml:"rank_weights"`           // 检索排序权重
+	RecencyHalfLifeDays float64           `yaml:"recency_half_life_days"` // 新近度半衰期（天），默认 14
+}
+
+// RankWeightsConfig 记忆检索排序中各因子的权重，全为 0 时使用默认值 0.6/0.25/0.15
+type RankWeightsConfig struct {
+	Similarity float64 `yaml:"similarity"`
+	Importance float64 `yaml:"importance"`
+	Recency    float64 `yaml:"recency"`
+}
+
+// IntimacyConfig 亲密度演化配置：互动按规则累积亲密度，LLM 的调整只作为修正项
+type IntimacyConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	DailyCap    float64 `yaml:"daily_cap"`     // 每人每天通过互动最多增加的亲密度，默认 0.05
+	MaxLLMDelta float64 `yaml:"max_llm_delta"` // LLM 单次调整亲密度的最大幅度，默认 0.1
+}
+
+// BackupConfig 定时备份配置：把记忆、黑话、表达方式、用户画像导出为压缩 JSON
+type BackupConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Cron    string `yaml:"cron"` // cron 表达式（分 时 日 月 周），默认 "0 4 * * *"
+	Dir     string `yaml:"dir"`  // 备份目录，默认 ./backups
+	Keep    int    `yaml:"keep"` // 保留最近几份，默认 7
+}
+
+// ImageConfig 图片发送配置
+type ImageConfig struct {
+	Send ImageSendConfig `yaml:"send"` // sendImage 工具：按链接发送网络图片
+	Draw ImageDrawConfig `yaml:"draw"` // drawImage 工具：生成图片并发送
+}
+
+// ImageDrawConfig 图片生成配置
+type ImageDrawConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Provider       string `yaml:"provider"` // openai（OpenAI 兼容的 /images/generations，默认）、sd_webui（Stable Diffusion WebUI API）
+	APIKey         string `yaml:"api_key"`
+	BaseURL        string `yaml:"base_url"`
+	Model          string `yaml:"model"`
+	Size           string `yaml:"size"`            // 图片尺寸，默认 1024x1024
+	Steps          int    `yaml:"steps"`           // 采样步数（sd_webui），默认 20
+	NegativePrompt string `yaml:"negative_prompt"` // 反向提示词（sd_webui）
+	DailyLimit     int    `yaml:"daily_limit"`     // 每天最多生成多少张，默认 10，-1 表示不限制
+}
+
+// ImageSendConfig 按链接发送网络图片的配置
+type ImageSendConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	AllowedDomains []string `yaml:"allowed_domains"` // 允许的图片域名（包括子域名），为空表示不限制（内网地址始终拒绝）
+	MaxSizeMB      int      `yaml:"max_size_mb"`     // 单张图片最大大小（MB），默认 5
 }
 
 // StickerConfig 表情包配置
@@ -153,12 +565,33 @@ type StickerConfig struct {
 	AutoSave    bool   `yaml:"auto_save"`    // 是否自动保存收到的表情包，默认 true
 	StoragePath string `yaml:"storage_path"` // 表情包存储目录，默认 "data/stickers"
 	MaxSizeMB   int    `yaml:"max_size_mb"`  // 单个文件最大大小(MB)，默认 5
+
+	Scope   string               `yaml:"scope"`   // 检索范围：global 全局共享（默认）、group_first 本群优先、group_only 仅本群
+	Cleanup StickerCleanupConfig `yaml:"cleanup"` // 自动清理
+}
+
+// StickerCleanupConfig 表情包自动清理配置
+type StickerCleanupConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	IntervalHours int    `yaml:"interval_hours"` // 清理间隔（小时），默认 24
+	UnusedDays    int    `yaml:"unused_days"`    // 保存超过多少天仍从未使用的表情包会被清理，默认 30
+	MaxCount      int    `yaml:"max_count"`      // 表情包数量上限，超出时优先清理使用次数少、最久未用的，0 表示不限制
+	ArchivePath   string `yaml:"archive_path"`   // 清理前把文件移动到该目录归档，留空直接删除
+}
+
+// PluginsConfig 自定义工具插件配置
+type PluginsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	ToolsDir string `yaml:"tools_dir"` // HTTP 工具描述文件目录（*.yaml / *.json），默认 config/tools
 }
 
 // ServerConfig HTTP服务配置
 type ServerConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
+
+	WSToken          string   `yaml:"ws_token"`           // /ws 事件流的访问令牌，监听非本机地址时必须配置
+	WSAllowedOrigins []string `yaml:"ws_allowed_origins"` // 允许连接 /ws 的网页来源（如 http://localhost:3000），同源始终允许
 }
 
 // DebugConfig 调试配置
@@ -167,6 +600,12 @@ type DebugConfig struct {
 	ShowThinking  bool `yaml:"show_thinking"`   // 显示思考过程
 	ShowMemory    bool `yaml:"show_memory"`     // 显示记忆检索
 	ShowToolCalls bool `yaml:"show_tool_calls"` // 显示工具调用
+	ShowSQL       bool `yaml:"show_sql"`        // 以 Debug 级别输出执行的 SQL
+
+	DecisionLog     bool `yaml:"decision_log"`       // 记录每次思考的决策过程到 decision_logs 表
+	DecisionLogDays int  `yaml:"decision_log_days"`  // 决策记录保留天数，默认 7
+	ToolCallLog     bool `yaml:"tool_call_log"`      // 记录每次工具调用到 tool_call_logs 表（行为审计）
+	ToolCallLogDays int  `yaml:"tool_call_log_days"` // 工具调用记录保留天数，默认 30
 }
 
 // Load 加载配置文件
@@ -201,13 +640,36 @@ func Load(path string) (*Config, error) {
 		} else if cfg.Embedding.APIKey == "" && cfg.LLM.APIKey != "" {
 			cfg.VisionLLM.APIKey = cfg.LLM.APIKey
 		}
+		if apiKey := os.Getenv("MUMU_TTS_API_KEY"); apiKey != "" {
+			cfg.TTS.APIKey = apiKey
+		}
+		if apiKey := os.Getenv("MUMU_IMAGE_API_KEY"); apiKey != "" {
+			cfg.Image.Draw.APIKey = apiKey
+		}
+		if apiKey := os.Getenv("MUMU_WEATHER_API_KEY"); apiKey != "" {
+			cfg.Weather.APIKey = apiKey
+		}
+		// 轻量模型未单独配置时沿用主模型的连接信息
+		if cfg.LightLLM.APIKey == "" {
+			cfg.LightLLM.APIKey = cfg.LLM.APIKey
+		}
+		if cfg.LightLLM.BaseURL == "" {
+			cfg.LightLLM.BaseURL = cfg.LLM.BaseURL
+		}
 		if token := os.Getenv("MUMU_ONEBOT_TOKEN"); token != "" {
 			cfg.OneBot.AccessToken = token
 		}
+		if token := os.Getenv("MUMU_WS_TOKEN"); token != "" {
+			cfg.Server.WSToken = token
+		}
 		// MySQL 密码
 		if password := os.Getenv("MUMU_MYSQL_PASSWORD"); password != "" {
 			cfg.Memory.MySQL.Password = password
 		}
+		// PostgreSQL 密码
+		if password := os.Getenv("MUMU_POSTGRES_PASSWORD"); password != "" {
+			cfg.Memory.Postgres.Password = password
+		}
 	})
 	return cfg, err
 }
@@ -217,11 +679,62 @@ func Get() *Config {
 	return cfg
 }
 
-// GetGroupConfig 获取指定群的配置
+// GetGroupConfig 获取指定群的配置（副本）
 func (c *Config) GetGroupConfig(groupID int64) *GroupConfig {
+	groupsMu.RLock()
+	defer groupsMu.RUnlock()
 	for i := range c.Groups {
 		if c.Groups[i].GroupID == groupID {
-			return &c.Groups[i]
+			gc := c.Groups[i]
+			return &gc
+		}
+	}
+	return nil
+}
+
+// GetGroups 获取所有群配置的快照
+func (c *Config) GetGroups() []GroupConfig {
+	groupsMu.RLock()
+	defer groupsMu.RUnlock()
+	return append([]GroupConfig(nil), c.Groups...)
+}
+
+// EnableGroup 运行时启用群，未配置的群会以默认配置加入
+// 返回 false 表示该群之前已经启用
+func (c *Config) EnableGroup(groupID int64) bool {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	for i := range c.Groups {
+		if c.Groups[i].GroupID == groupID {
+			if c.Groups[i].Enabled {
+				return false
+			}
+			c.Groups[i].Enabled = true
+			return true
+		}
+	}
+	c.Groups = append(c.Groups, GroupConfig{GroupID: groupID, Enabled: true})
+	return true
+}
+
+// DisableGroup 运行时停用群，返回 false 表示该群之前没有启用
+func (c *Config) DisableGroup(groupID int64) bool {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	for i := range c.Groups {
+		if c.Groups[i].GroupID == groupID && c.Groups[i].Enabled {
+			c.Groups[i].Enabled = false
+			return true
+		}
+	}
+	return false
+}
+
+// GetPersonaConfig 按名称获取具名人格配置，未找到返回 nil
+func (c *Config) GetPersonaConfig(name string) *PersonaConfig {
+	for i := range c.Personas {
+		if c.Personas[i].Name == name {
+			return &c.Personas[i]
 		}
 	}
 	return nil
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeys 查看配置时需要脱敏的字段（yaml 键名）
+var secretKeys = map[string]bool{
+	"api_key":      true,
+	"access_token": true,
+	"ws_token":     true,
+	"password":     true,
+	"secret":       true,
+}
+
+// secretSuffixes 键名带这些后缀的字段也按密钥处理，避免新增字段时漏掉
+var secretSuffixes = []string{"_token", "_key", "_secret", "password"}
+
+// isSecretKey 判断 yaml 键名是否为密钥类字段
+func isSecretKey(key string) bool {
+	if secretKeys[key] {
+		return true
+	}
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedValue 脱敏后的显示值
+const maskedValue = "******"
+
+// Masked 返回脱敏后的配置（按 yaml 键名组织），密钥类字段有值时替换为 ******
+func (c *Config) Masked() (map[string]any, error) {
+	groupsMu.RLock()
+	runtimeMu.RLock()
+	data, err := yaml.Marshal(c)
+	runtimeMu.RUnlock()
+	groupsMu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	maskSecrets(result)
+	return result, nil
+}
+
+// maskSecrets 递归替换密钥类字段的值
+func maskSecrets(v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if s, ok := item.(string); ok && isSecretKey(k) && s != "" {
+				val[k] = maskedValue
+				continue
+			}
+			maskSecrets(item)
+		}
+	case []any:
+		for _, item := range val {
+			maskSecrets(item)
+		}
+	}
+}
+
+// RuntimePatch 运行时可热更新的配置项，nil 表示不修改
+type RuntimePatch struct {
+	TalkFrequency *float64       `json:"talk_frequency"`
+	SpeakCooldown *CooldownPatch `json:"speak_cooldown"`
+}
+
+// CooldownPatch 发言冷却的可修改项
+type CooldownPatch struct {
+	Base     *int  `json:"base"`
+	Adaptive *bool `json:"adaptive"`
+}
+
+// Validate 检查修改值是否合法
+func (p *RuntimePatch) Validate() error {
+	if p.TalkFrequency != nil && (*p.TalkFrequency < 0 || *p.TalkFrequency > 1) {
+		return fmt.Errorf("talk_frequency 需要在 0~1 之间")
+	}
+	if p.SpeakCooldown != nil && p.SpeakCooldown.Base != nil && *p.SpeakCooldown.Base < 0 {
+		return fmt.Errorf("speak_cooldown.base 不能为负数")
+	}
+	return nil
+}
+
+// ApplyRuntimePatch 校验并应用运行时修改，立即生效但不会写回配置文件
+// 读取方需要通过 TalkFrequency / SpeakCooldown 获取，修改在下一次判断时生效
+func (c *Config) ApplyRuntimePatch(p *RuntimePatch) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	if p.TalkFrequency != nil {
+		c.Chat.TalkFrequency = *p.TalkFrequency
+	}
+	if p.SpeakCooldown != nil {
+		if p.SpeakCooldown.Base != nil {
+			c.Chat.SpeakCooldown.Base = *p.SpeakCooldown.Base
+		}
+		if p.SpeakCooldown.Adaptive != nil {
+			c.Chat.SpeakCooldown.Adaptive = *p.SpeakCooldown.Adaptive
+		}
+	}
+	return nil
+}
+
+// TalkFrequency 获取当前的聊天频率
+func (c *Config) TalkFrequency() float64 {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	return c.Chat.TalkFrequency
+}
+
+// SpeakCooldown 获取当前发言冷却配置的副本
+func (c *Config) SpeakCooldown() CooldownConfig {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	return c.Chat.SpeakCooldown
+}
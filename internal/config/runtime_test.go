@@ -0,0 +1,69 @@
+package config
+
+import "testing"
+
+func TestMaskedHidesSecrets(t *testing.T) {
+	c := &Config{}
+	c.LLM.APIKey = "sk-llm"
+	c.LLM.Model = "gpt"
+	c.OneBot.AccessToken = "onebot-token"
+	c.Server.WSToken = "ws-token"
+	c.Server.Host = "0.0.0.0"
+	c.Memory.MySQL.Password = "db-pass"
+
+	masked, err := c.Masked()
+	if err != nil {
+		t.Fatalf("Masked() 失败: %v", err)
+	}
+
+	section := func(path ...string) map[string]any {
+		cur := masked
+		for _, p := range path {
+			next, ok := cur[p].(map[string]any)
+			if !ok {
+				t.Fatalf("缺少配置段 %v", path)
+			}
+			cur = next
+		}
+		return cur
+	}
+
+	cases := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"llm.api_key", section("llm")["api_key"], maskedValue},
+		{"onebot.access_token", section("onebot")["access_token"], maskedValue},
+		{"server.ws_token", section("server")["ws_token"], maskedValue},
+		{"memory.mysql.password", section("memory", "mysql")["password"], maskedValue},
+		// 普通字段保持原样，空的密钥字段不替换
+		{"llm.model", section("llm")["model"], "gpt"},
+		{"server.host", section("server")["host"], "0.0.0.0"},
+		{"light_llm.api_key", section("light_llm")["api_key"], ""},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	cases := map[string]bool{
+		"api_key":            true,
+		"ws_token":           true,
+		"access_token":       true,
+		"refresh_token":      true,
+		"client_secret":      true,
+		"admin_password":     true,
+		"daily_tokens":       false,
+		"group_daily_tokens": false,
+		"model":              false,
+	}
+	for key, want := range cases {
+		if got := isSecretKey(key); got != want {
+			t.Errorf("isSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	TypeMessage  = "message"   // 群里的新消息（包括自己发的）
+	TypeSpeak    = "speak"     // 发言成功
+	TypeToolCall = "tool_call" // 工具调用
+	TypeDecision = "decision"  // 一次思考的决策结果
+)
+
+// subscriberBuffer 每个订阅者的事件缓冲，消费跟不上时丢弃新事件
+const subscriberBuffer = 256
+
+// Event 实时事件
+type Event struct {
+	Type    string    `json:"type"`
+	GroupID int64     `json:"group_id"`
+	Time    time.Time `json:"time"`
+	Data    any       `json:"data"`
+}
+
+// Bus 进程内事件总线，供实时监控推送使用；没有订阅者时发布几乎没有开销
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[chan Event]struct{}
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]struct{})}
+}
+
+// Publish 发布事件，不会阻塞：订阅者缓冲已满时丢弃该事件
+func (b *Bus) Publish(typ string, groupID int64, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subs) == 0 {
+		return
+	}
+	e := Event{Type: typ, GroupID: groupID, Time: time.Now(), Data: data}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe 订阅事件，返回事件通道和取消订阅函数
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
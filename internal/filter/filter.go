@@ -0,0 +1,196 @@
+package filter
+
+import (
+	"fmt"
+	"mumu-bot/internal/config"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 规则类型
+const (
+	TypeKeyword = "keyword"
+	TypeRegex   = "regex"
+	TypePhone   = "phone"
+	TypeIDCard  = "id_card"
+	TypeLongURL = "long_url"
+)
+
+// 命中动作
+const (
+	ActionMask    = "mask"
+	ActionBlock   = "block"
+	ActionReplace = "replace"
+)
+
+var (
+	phonePattern  = regexp.MustCompile(`\b1[3-9]\d{9}\b`)
+	idCardPattern = regexp.MustCompile(`\b\d{17}[\dXx]\b`)
+	urlPattern    = regexp.MustCompile(`https?://[^\s]+`)
+)
+
+// Hit 一次规则命中
+type Hit struct {
+	Rule    string
+	Action  string
+	Matched string
+}
+
+// Result 过滤结果
+type Result struct {
+	Content string // 处理后的内容
+	Blocked bool   // 是否被拦截
+	Hits    []Hit
+}
+
+// rule 编译后的过滤规则
+type rule struct {
+	name        string
+	re          *regexp.Regexp
+	minLen      int // 命中内容的最小长度（long_url 使用）
+	action      string
+	replacement string
+}
+
+// Pipeline 发言内容过滤管线
+type Pipeline struct {
+	rules []*rule
+}
+
+// NewPipeline 根据配置创建过滤管线，未启用时返回空管线
+func NewPipeline(cfg *config.FilterConfig) (*Pipeline, error) {
+	p := &Pipeline{}
+	if cfg == nil || !cfg.Enabled {
+		return p, nil
+	}
+
+	for i, rc := range cfg.Rules {
+		r, err := compileRule(rc)
+		if err != nil {
+			return nil, fmt.Errorf("过滤规则 #%d(%s) 无效: %w", i, rc.Name, err)
+		}
+		if r != nil {
+			p.rules = append(p.rules, r)
+		}
+	}
+	return p, nil
+}
+
+// compileRule 编译单条规则，无有效匹配内容时返回 nil
+func compileRule(rc config.FilterRuleConfig) (*rule, error) {
+	r := &rule{
+		name:        rc.Name,
+		action:      rc.Action,
+		replacement: rc.Replacement,
+	}
+	if r.name == "" {
+		r.name = rc.Type
+	}
+	switch r.action {
+	case "":
+		r.action = ActionMask
+	case ActionMask, ActionBlock, ActionReplace:
+	default:
+		return nil, fmt.Errorf("未知动作 %q", rc.Action)
+	}
+	if r.action == ActionReplace && r.replacement == "" {
+		r.replacement = "[已屏蔽]"
+	}
+
+	switch rc.Type {
+	case TypeKeyword:
+		var quoted []string
+		for _, kw := range rc.Patterns {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				quoted = append(quoted, regexp.QuoteMeta(kw))
+			}
+		}
+		if len(quoted) == 0 {
+			return nil, nil
+		}
+		r.re = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	case TypeRegex:
+		if len(rc.Patterns) == 0 {
+			return nil, nil
+		}
+		re, err := regexp.Compile(strings.Join(rc.Patterns, "|"))
+		if err != nil {
+			return nil, err
+		}
+		r.re = re
+	case TypePhone:
+		r.re = phonePattern
+	case TypeIDCard:
+		r.re = idCardPattern
+	case TypeLongURL:
+		r.re = urlPattern
+		r.minLen = rc.MaxLength
+		if r.minLen <= 0 {
+			r.minLen = 60
+		}
+	default:
+		return nil, fmt.Errorf("未知类型 %q", rc.Type)
+	}
+	return r, nil
+}
+
+// Apply 依次应用所有规则
+// 命中 block 动作时立即停止并返回 Blocked；所有命中都会记录审计日志
+func (p *Pipeline) Apply(groupID int64, content string) *Result {
+	res := &Result{Content: content}
+	if p == nil {
+		return res
+	}
+
+	for _, r := range p.rules {
+		res.Content = r.re.ReplaceAllStringFunc(res.Content, func(matched string) string {
+			if len([]rune(matched)) <= r.minLen {
+				return matched
+			}
+			res.Hits = append(res.Hits, Hit{Rule: r.name, Action: r.action, Matched: matched})
+			switch r.action {
+			case ActionBlock:
+				res.Blocked = true
+				return matched
+			case ActionReplace:
+				return r.replacement
+			default:
+				return mask(matched)
+			}
+		})
+		if res.Blocked {
+			break
+		}
+	}
+
+	if len(res.Hits) > 0 {
+		audit(groupID, content, res)
+	}
+	return res
+}
+
+// mask 打码，保留首尾各 1/4 的字符
+func mask(s string) string {
+	runes := []rune(s)
+	keep := len(runes) / 4
+	for i := keep; i < len(runes)-keep; i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+// audit 记录审计日志
+func audit(groupID int64, original string, res *Result) {
+	for _, h := range res.Hits {
+		zap.L().Named("audit").Info("发言内容过滤命中",
+			zap.Int64("group_id", groupID),
+			zap.String("rule", h.Rule),
+			zap.String("action", h.Action),
+			zap.String("matched", h.Matched),
+			zap.String("original", original),
+			zap.String("result", res.Content),
+			zap.Bool("blocked", res.Blocked))
+	}
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+// 游戏类型
+const (
+	TypeGuessNumber = "guess_number" // 猜数字
+	TypeIdiomChain  = "idiom_chain"  // 成语接龙
+	TypeQuiz        = "quiz"         // 出题问答
+)
+
+// sessionTimeout 游戏无人参与的超时时间
+const sessionTimeout = 30 * time.Minute
+
+var (
+	ErrNoGame      = errors.New("当前没有进行中的游戏")
+	ErrGameRunning = errors.New("已经有一个游戏在进行中了，先结束它再开新的")
+)
+
+// Game 小游戏
+// 实现需要可以用 JSON 序列化，以便持久化游戏状态
+type Game interface {
+	// Intro 当前的题面或游戏状态描述
+	Intro() string
+	// Answer 处理一次作答
+	Answer(userID int64, answer string) Result
+}
+
+// Result 作答结果
+type Result struct {
+	Correct  bool   `json:"correct"`
+	Finished bool   `json:"finished"`
+	Message  string `json:"message"`
+}
+
+// StartOptions 开始游戏的参数
+type StartOptions struct {
+	Question string // 出题问答：题目
+	Answer   string // 出题问答：答案
+	First    string // 成语接龙：起始成语
+}
+
+// newGame 按类型创建游戏
+func newGame(gameType string, opts StartOptions) (Game, error) {
+	switch gameType {
+	case TypeGuessNumber:
+		return newGuessNumber(), nil
+	case TypeIdiomChain:
+		return newIdiomChain(opts.First)
+	case TypeQuiz:
+		return newQuiz(opts.Question, opts.Answer)
+	default:
+		return nil, fmt.Errorf("不支持的游戏类型: %s", gameType)
+	}
+}
+
+// restoreGame 从持久化状态恢复游戏
+func restoreGame(gameType, state string) (Game, error) {
+	var g Game
+	switch gameType {
+	case TypeGuessNumber:
+		g = &guessNumber{}
+	case TypeIdiomChain:
+		g = &idiomChain{}
+	case TypeQuiz:
+		g = &quiz{}
+	default:
+		return nil, fmt.Errorf("不支持的游戏类型: %s", gameType)
+	}
+	if err := sonic.UnmarshalString(state, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// session 进行中的游戏会话
+type session struct {
+	record       *memory.GameSession
+	game         Game
+	participants []int64
+}
+
+// Manager 小游戏管理器，每个群同时只有一个进行中的游戏
+type Manager struct {
+	mu       sync.Mutex
+	sessions map[int64]*session
+	store    *memory.Manager
+}
+
+// NewManager 创建小游戏管理器，并恢复进行中的游戏
+func NewManager(store *memory.Manager) *Manager {
+	m := &Manager{
+		sessions: make(map[int64]*session),
+		store:    store,
+	}
+
+	records, err := store.ListActiveGameSessions()
+	if err != nil {
+		zap.L().Warn("加载进行中的小游戏失败", zap.Error(err))
+		return m
+	}
+	for i := range records {
+		r := &records[i]
+		g, err := restoreGame(r.Type, r.State)
+		if err != nil {
+			zap.L().Warn("恢复小游戏失败", zap.Int64("group_id", r.GroupID), zap.Error(err))
+			continue
+		}
+		s := &session{record: r, game: g}
+		for _, p := range strings.Split(r.Participants, ",") {
+			if uid, err := strconv.ParseInt(p, 10, 64); err == nil {
+				s.participants = append(s.participants, uid)
+			}
+		}
+		m.sessions[r.GroupID] = s
+	}
+	return m
+}
+
+// Start 在群内开始一个游戏，返回开场说明
+func (m *Manager) Start(groupID int64, gameType string, opts StartOptions) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s := m.activeSession(groupID); s != nil {
+		return "", ErrGameRunning
+	}
+	g, err := newGame(gameType, opts)
+	if err != nil {
+		return "", err
+	}
+
+	s := &session{
+		record: &memory.GameSession{GroupID: groupID, Type: gameType, Active: true},
+		game:   g,
+	}
+	if err := m.save(s); err != nil {
+		return "", err
+	}
+	m.sessions[groupID] = s
+	return g.Intro(), nil
+}
+
+// Answer 处理群友的一次作答
+func (m *Manager) Answer(groupID, userID int64, answer string) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.activeSession(groupID)
+	if s == nil {
+		return Result{}, ErrNoGame
+	}
+	if !slices.Contains(s.participants, userID) {
+		s.participants = append(s.participants, userID)
+	}
+
+	res := s.game.Answer(userID, strings.TrimSpace(answer))
+	if res.Finished {
+		if res.Correct {
+			s.record.WinnerID = userID
+		}
+		m.finish(s)
+	} else if err := m.save(s); err != nil {
+		zap.L().Warn("保存小游戏状态失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+	return res, nil
+}
+
+// Stop 结束群内进行中的游戏
+func (m *Manager) Stop(groupID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.activeSession(groupID)
+	if s == nil {
+		return ErrNoGame
+	}
+	m.finish(s)
+	return nil
+}
+
+// Current 获取群内进行中的游戏类型和状态描述
+func (m *Manager) Current(groupID int64) (string, string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.activeSession(groupID)
+	if s == nil {
+		return "", "", false
+	}
+	return s.record.Type, s.game.Intro(), true
+}
+
+// activeSession 获取群内进行中的会话，超时的会话会被结束（需持有锁）
+func (m *Manager) activeSession(groupID int64) *session {
+	s, ok := m.sessions[groupID]
+	if !ok {
+		return nil
+	}
+	if time.Since(s.record.UpdatedAt) > sessionTimeout {
+		m.finish(s)
+		return nil
+	}
+	return s
+}
+
+// finish 结束会话并记录参与数据（需持有锁）
+func (m *Manager) finish(s *session) {
+	now := time.Now()
+	s.record.Active = false
+	s.record.EndedAt = &now
+	if err := m.save(s); err != nil {
+		zap.L().Warn("保存小游戏结果失败", zap.Int64("group_id", s.record.GroupID), zap.Error(err))
+	}
+	delete(m.sessions, s.record.GroupID)
+
+	for _, uid := range s.participants {
+		if err := m.store.RecordGamePlay(uid, uid == s.record.WinnerID); err != nil {
+			zap.L().Debug("记录小游戏参与失败", zap.Int64("user_id", uid), zap.Error(err))
+		}
+	}
+}
+
+// save 持久化会话状态
+func (m *Manager) save(s *session) error {
+	state, err := sonic.MarshalString(s.game)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(s.participants))
+	for _, uid := range s.participants {
+		ids = append(ids, strconv.FormatInt(uid, 10))
+	}
+	s.record.State = state
+	s.record.Participants = strings.Join(ids, ",")
+	return m.store.SaveGameSession(s.record)
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// 猜数字范围
+const (
+	guessMin = 1
+	guessMax = 100
+)
+
+// guessNumber 猜数字：猜一个 1-100 之间的数，每次提示大了或小了
+type guessNumber struct {
+	Target   int `json:"target"`
+	Low      int `json:"low"`  // 当前可能范围
+	High     int `json:"high"` // 当前可能范围
+	Attempts int `json:"attempts"`
+}
+
+func newGuessNumber() *guessNumber {
+	return &guessNumber{
+		Target: guessMin + rand.Intn(guessMax-guessMin+1),
+		Low:    guessMin,
+		High:   guessMax,
+	}
+}
+
+func (g *guessNumber) Intro() string {
+	return fmt.Sprintf("猜数字：答案在 %d 到 %d 之间，已经猜了 %d 次", g.Low, g.High, g.Attempts)
+}
+
+func (g *guessNumber) Answer(_ int64, answer string) Result {
+	n, err := strconv.Atoi(answer)
+	if err != nil {
+		return Result{Message: "要猜一个整数"}
+	}
+	if n < g.Low || n > g.High {
+		return Result{Message: fmt.Sprintf("超出范围了，答案在 %d 到 %d 之间", g.Low, g.High)}
+	}
+
+	g.Attempts++
+	switch {
+	case n > g.Target:
+		g.High = n - 1
+		return Result{Message: fmt.Sprintf("%d 大了，答案在 %d 到 %d 之间", n, g.Low, g.High)}
+	case n < g.Target:
+		g.Low = n + 1
+		return Result{Message: fmt.Sprintf("%d 小了，答案在 %d 到 %d 之间", n, g.Low, g.High)}
+	default:
+		return Result{Correct: true, Finished: true, Message: fmt.Sprintf("猜对了！答案就是 %d，一共猜了 %d 次", n, g.Attempts)}
+	}
+}
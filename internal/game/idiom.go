@@ -0,0 +1,74 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"unicode"
+)
+
+// idiomChainMaxRounds 成语接龙最多进行的轮数
+const idiomChainMaxRounds = 50
+
+// defaultIdioms 未指定起始成语时随机选用
+var defaultIdioms = []string{"一心一意", "万事如意", "画蛇添足", "守株待兔", "马到成功", "心想事成"}
+
+// idiomChain 成语接龙：下一个成语的首字必须是上一个成语的尾字，不能重复
+// 只校验格式和接龙规则，成语是否存在由 LLM 判断
+type idiomChain struct {
+	Chain []string `json:"chain"`
+}
+
+func newIdiomChain(first string) (*idiomChain, error) {
+	if first == "" {
+		first = defaultIdioms[rand.Intn(len(defaultIdioms))]
+	}
+	if !isIdiomLike(first) {
+		return nil, errors.New("起始成语必须是四个汉字")
+	}
+	return &idiomChain{Chain: []string{first}}, nil
+}
+
+func (g *idiomChain) last() []rune {
+	return []rune(g.Chain[len(g.Chain)-1])
+}
+
+func (g *idiomChain) Intro() string {
+	last := g.last()
+	return fmt.Sprintf("成语接龙：上一个成语是「%s」，请接「%c」字开头的成语（已接 %d 个）", string(last), last[len(last)-1], len(g.Chain)-1)
+}
+
+func (g *idiomChain) Answer(_ int64, answer string) Result {
+	if !isIdiomLike(answer) {
+		return Result{Message: "要接一个四字成语"}
+	}
+	last := g.last()
+	if []rune(answer)[0] != last[len(last)-1] {
+		return Result{Message: fmt.Sprintf("要用「%c」字开头哦", last[len(last)-1])}
+	}
+	if slices.Contains(g.Chain, answer) {
+		return Result{Message: fmt.Sprintf("「%s」已经接过了", answer)}
+	}
+
+	g.Chain = append(g.Chain, answer)
+	if len(g.Chain)-1 >= idiomChainMaxRounds {
+		return Result{Correct: true, Finished: true, Message: fmt.Sprintf("接上了！已经接了 %d 个，今天就到这吧", len(g.Chain)-1)}
+	}
+	runes := []rune(answer)
+	return Result{Correct: true, Message: fmt.Sprintf("接上了！下一个请接「%c」字开头的成语", runes[len(runes)-1])}
+}
+
+// isIdiomLike 是否为四个汉字
+func isIdiomLike(s string) bool {
+	runes := []rune(s)
+	if len(runes) != 4 {
+		return false
+	}
+	for _, r := range runes {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return true
+}
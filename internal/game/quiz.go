@@ -0,0 +1,52 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"mumu-bot/internal/utils"
+	"strings"
+)
+
+// quizMaxAttempts 出题问答最多允许的作答次数
+const quizMaxAttempts = 20
+
+// quiz 出题问答：由 LLM 出题并给出答案，答案足够接近即算答对
+type quiz struct {
+	Question string `json:"question"`
+	Expected string `json:"expected"`
+	Attempts int    `json:"attempts"`
+}
+
+func newQuiz(question, answer string) (*quiz, error) {
+	if strings.TrimSpace(question) == "" || strings.TrimSpace(answer) == "" {
+		return nil, errors.New("出题需要同时提供题目和答案")
+	}
+	return &quiz{Question: question, Expected: answer}, nil
+}
+
+func (g *quiz) Intro() string {
+	return fmt.Sprintf("问答：%s（已经有 %d 次作答）", g.Question, g.Attempts)
+}
+
+func (g *quiz) Answer(_ int64, answer string) Result {
+	g.Attempts++
+	if normalizeAnswer(answer) == normalizeAnswer(g.Expected) ||
+		utils.TextSimilarity(normalizeAnswer(answer), normalizeAnswer(g.Expected)) >= 0.8 {
+		return Result{Correct: true, Finished: true, Message: fmt.Sprintf("答对了！答案是「%s」", g.Expected)}
+	}
+	if g.Attempts >= quizMaxAttempts {
+		return Result{Finished: true, Message: fmt.Sprintf("没人答对，答案是「%s」", g.Expected)}
+	}
+	return Result{Message: "不对哦"}
+}
+
+// normalizeAnswer 去掉空白和常见标点并转为小写
+func normalizeAnswer(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\n，。！？、,.!?\"'「」《》", r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -17,17 +17,9 @@ type Client struct {
 
 // NewClient 创建 LLM 客户端
 func NewClient(cfg *config.Config) (*Client, error) {
-	ctx := context.Background()
-
-	// 使用 Eino 的 OpenAI 兼容客户端
-	chatModel, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		BaseURL:     cfg.LLM.BaseURL,
-		APIKey:      cfg.LLM.APIKey,
-		Model:       cfg.LLM.Model,
-		ExtraFields: cfg.LLM.ExtraFields,
-	})
+	chatModel, err := NewChatModel(&cfg.LLM)
 	if err != nil {
-		return nil, fmt.Errorf("创建 ChatModel 失败: %w", err)
+		return nil, err
 	}
 
 	return &Client{
@@ -36,6 +28,21 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	}, nil
 }
 
+// NewChatModel 根据模型配置创建支持工具调用的 ChatModel
+func NewChatModel(llmCfg *config.LLMConfig) (model.ToolCallingChatModel, error) {
+	// 使用 Eino 的 OpenAI 兼容客户端
+	chatModel, err := openai.NewChatModel(context.Background(), &openai.ChatModelConfig{
+		BaseURL:     llmCfg.BaseURL,
+		APIKey:      llmCfg.APIKey,
+		Model:       llmCfg.Model,
+		ExtraFields: llmCfg.ExtraFields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 ChatModel 失败: %w", err)
+	}
+	return chatModel, nil
+}
+
 // GetModel 获取底层模型（支持工具调用）
 func (c *Client) GetModel() model.ToolCallingChatModel {
 	return c.chatModel
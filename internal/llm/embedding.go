@@ -23,12 +23,13 @@ func NewEmbeddingClient(cfg *config.Config) (*EmbeddingClient, error) {
 	}
 
 	ctx := context.Background()
+	dim := cfg.Memory.VectorDim()
 
 	embedder, err := openai.NewEmbedder(ctx, &openai.EmbeddingConfig{
 		BaseURL:    cfg.Embedding.BaseURL,
 		APIKey:     cfg.Embedding.APIKey,
 		Model:      cfg.Embedding.Model,
-		Dimensions: &cfg.Memory.Milvus.VectorDim,
+		Dimensions: &dim,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("创建 Embedder 失败: %w", err)
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// maxImageSize 生成图片的最大字节数
+const maxImageSize = 20 << 20
+
+// ImageClient 图片生成客户端，支持 OpenAI 兼容接口和 Stable Diffusion WebUI
+type ImageClient struct {
+	cfg  *config.ImageDrawConfig
+	http *http.Client
+}
+
+// NewImageClient 创建图片生成客户端，未启用时返回 nil
+func NewImageClient(cfg *config.ImageDrawConfig) (*ImageClient, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("未配置图片生成接口地址")
+	}
+	switch cfg.Provider {
+	case "", "openai", "sd_webui":
+	default:
+		return nil, fmt.Errorf("不支持的图片生成服务: %s", cfg.Provider)
+	}
+	return &ImageClient{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 3 * time.Minute},
+	}, nil
+}
+
+// Generate 根据提示词生成一张图片，返回图片数据
+func (c *ImageClient) Generate(ctx context.Context, prompt string) ([]byte, error) {
+	if c.cfg.Provider == "sd_webui" {
+		return c.generateSD(ctx, prompt)
+	}
+	return c.generateOpenAI(ctx, prompt)
+}
+
+// generateOpenAI 调用 OpenAI 兼容的 /images/generations，结果可能是 base64 或链接
+func (c *ImageClient) generateOpenAI(ctx context.Context, prompt string) ([]byte, error) {
+	var result struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+			URL     string `json:"url"`
+		} `json:"data"`
+	}
+	err := c.post(ctx, strings.TrimRight(c.cfg.BaseURL, "/")+"/images/generations", map[string]any{
+		"model":  c.cfg.Model,
+		"prompt": prompt,
+		"n":      1,
+		"size":   c.size(),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("没有生成图片")
+	}
+	if b64 := result.Data[0].B64JSON; b64 != "" {
+		return base64.StdEncoding.DecodeString(b64)
+	}
+	if url := result.Data[0].URL; url != "" {
+		return utils.FetchImage(ctx, url, nil, maxImageSize)
+	}
+	return nil, fmt.Errorf("没有生成图片")
+}
+
+// generateSD 调用 Stable Diffusion WebUI 的 /sdapi/v1/txt2img
+func (c *ImageClient) generateSD(ctx context.Context, prompt string) ([]byte, error) {
+	width, height := 1024, 1024
+	if w, h, ok := strings.Cut(c.size(), "x"); ok {
+		if v, err := strconv.Atoi(w); err == nil {
+			width = v
+		}
+		if v, err := strconv.Atoi(h); err == nil {
+			height = v
+		}
+	}
+	steps := c.cfg.Steps
+	if steps <= 0 {
+		steps = 20
+	}
+
+	var result struct {
+		Images []string `json:"images"`
+	}
+	err := c.post(ctx, strings.TrimRight(c.cfg.BaseURL, "/")+"/sdapi/v1/txt2img", map[string]any{
+		"prompt":          prompt,
+		"negative_prompt": c.cfg.NegativePrompt,
+		"steps":           steps,
+		"width":           width,
+		"height":          height,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Images) == 0 {
+		return nil, fmt.Errorf("没有生成图片")
+	}
+	return base64.StdEncoding.DecodeString(result.Images[0])
+}
+
+// size 图片尺寸，默认 1024x1024
+func (c *ImageClient) size() string {
+	if c.cfg.Size != "" {
+		return c.cfg.Size
+	}
+	return "1024x1024"
+}
+
+// post 发送 JSON 请求并解析响应
+func (c *ImageClient) post(ctx context.Context, url string, body map[string]any, out any) error {
+	data, err := sonic.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求图片生成失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// base64 编码后体积约为原图的 4/3
+	respData, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize*2))
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("图片生成返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(respData[:min(len(respData), 200)])))
+	}
+	if err := sonic.Unmarshal(respData, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mumu-bot/internal/config"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// maxAudioSize 合成音频的最大字节数
+const maxAudioSize = 10 << 20
+
+// TTSClient 语音合成客户端，支持 OpenAI 兼容接口和本地 TTS 服务
+type TTSClient struct {
+	cfg  *config.TTSConfig
+	http *http.Client
+}
+
+// NewTTSClient 创建语音合成客户端，未启用时返回 nil
+func NewTTSClient(cfg *config.TTSConfig) (*TTSClient, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("未配置 TTS 接口地址")
+	}
+	switch cfg.Provider {
+	case "", "openai", "local":
+	default:
+		return nil, fmt.Errorf("不支持的 TTS 服务: %s", cfg.Provider)
+	}
+	return &TTSClient{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Synthesize 把文字合成为语音，返回音频数据
+func (c *TTSClient) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	format := c.cfg.Format
+	if format == "" {
+		format = "mp3"
+	}
+
+	var url string
+	var body map[string]any
+	if c.cfg.Provider == "local" {
+		url = c.cfg.BaseURL
+		body = map[string]any{"text": text, "voice": c.cfg.Voice, "format": format}
+	} else {
+		url = strings.TrimRight(c.cfg.BaseURL, "/") + "/audio/speech"
+		body = map[string]any{"model": c.cfg.Model, "input": text, "voice": c.cfg.Voice, "response_format": format}
+	}
+	data, err := sonic.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 TTS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取音频失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TTS 返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(audio[:min(len(audio), 200)])))
+	}
+	if len(audio) > maxAudioSize {
+		return nil, fmt.Errorf("音频过大")
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("TTS 返回的音频为空")
+	}
+	return audio, nil
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"mumu-bot/internal/config"
+	"mumu-bot/internal/utils"
+	"slices"
 	"strings"
 
+	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/schema"
 )
@@ -76,6 +79,64 @@ func (v *VisionClient) DescribeImage(ctx context.Context, imageURL string) (stri
 	return fmt.Sprintf("[图片:%s]", desc), nil
 }
 
+// StickerEmotions 表情包情绪标签的可选值
+var StickerEmotions = []string{"开心", "难过", "生气", "惊讶", "无语", "害怕", "得意", "害羞", "疑惑", "其他"}
+
+// StickerInfo 表情包的描述和结构化标签
+type StickerInfo struct {
+	Description string `json:"description"` // 自由文本描述
+	Emotion     string `json:"emotion"`     // 情绪，取值见 StickerEmotions
+	Subject     string `json:"subject"`     // 画面主体，如猫、熊猫头、某动漫角色
+	HasText     bool   `json:"has_text"`    // 是否带字
+	Text        string `json:"text"`        // 图上的文字
+}
+
+// DescribeSticker 描述表情包，同时产出情绪、主体、是否带字等结构化标签
+// 模型没有按格式输出时，整段回复作为描述，标签留空
+func (v *VisionClient) DescribeSticker(ctx context.Context, imageURL string) (*StickerInfo, error) {
+	if v == nil || v.model == nil {
+		return nil, fmt.Errorf("视觉模型未启用")
+	}
+
+	prompt := fmt.Sprintf(`这是一张表情包。请用 JSON 输出：
+{"description": "不超过50字的中文描述，包括表情、情绪和文字，能判断角色出处时说明", "emotion": "情绪，只能是 %s 之一", "subject": "画面主体，如猫、熊猫头、某动漫角色，不超过10字", "has_text": 是否带字, "text": "图上的文字，没有则为空"}
+只输出 JSON。`, strings.Join(StickerEmotions, "、"))
+
+	msg := &schema.Message{
+		Role: schema.User,
+		UserInputMultiContent: []schema.MessageInputPart{
+			{
+				Type: schema.ChatMessagePartTypeImageURL,
+				Image: &schema.MessageInputImage{
+					MessagePartCommon: schema.MessagePartCommon{
+						URL: &imageURL,
+					},
+					Detail: schema.ImageURLDetailAuto,
+				},
+			},
+			{
+				Type: schema.ChatMessagePartTypeText,
+				Text: prompt,
+			},
+		},
+	}
+
+	resp, err := v.model.Generate(ctx, []*schema.Message{msg})
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(resp.Content)
+	var info StickerInfo
+	if err := sonic.UnmarshalString(utils.ExtractJSON(content), &info); err != nil || info.Description == "" {
+		return &StickerInfo{Description: content}, nil
+	}
+	if !slices.Contains(StickerEmotions, info.Emotion) {
+		info.Emotion = "其他"
+	}
+	return &info, nil
+}
+
 // DescribeVideo 描述视频内容
 func (v *VisionClient) DescribeVideo(ctx context.Context, videoURL string) (string, error) {
 	if v == nil || v.model == nil {
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
+)
+
+// 消息日志归档模式
+const (
+	ArchiveModeTable = "table" // 转存到 message_archives 表
+	ArchiveModeFile  = "file"  // 追加写入 gzip 压缩的 JSONL 文件
+)
+
+// archiveMessageLogs 按配置的模式归档将被清理的消息
+func (m *Manager) archiveMessageLogs(logs []MessageLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	cfg := m.cfg.Memory.MessageLogCleanup
+	switch cfg.Archive {
+	case ArchiveModeTable:
+		archives := make([]MessageArchive, 0, len(logs))
+		for _, l := range logs {
+			archives = append(archives, MessageArchive{
+				CreatedAt: l.CreatedAt,
+				MessageID: l.MessageID,
+				GroupID:   l.GroupID,
+				UserID:    l.UserID,
+				Nickname:  l.Nickname,
+				Content:   l.Content,
+				MsgType:   l.MsgType,
+				Forwards:  l.Forwards,
+			})
+		}
+		return m.db.CreateInBatches(archives, 200).Error
+	case ArchiveModeFile:
+		dir := cfg.ArchivePath
+		if dir == "" {
+			dir = "data/message_archive"
+		}
+		return appendArchiveFiles(dir, logs)
+	default:
+		return fmt.Errorf("未知的归档模式: %s", cfg.Archive)
+	}
+}
+
+// appendArchiveFiles 按群和月份追加写入 <dir>/<群号>/<年-月>.jsonl.gz
+// 每次追加一个新的 gzip 成员，标准 gzip 工具可以直接解压出完整内容
+func appendArchiveFiles(dir string, logs []MessageLog) error {
+	byFile := make(map[string][]MessageLog)
+	for _, l := range logs {
+		path := filepath.Join(dir, strconv.FormatInt(l.GroupID, 10), l.CreatedAt.Format("2006-01")+".jsonl.gz")
+		byFile[path] = append(byFile[path], l)
+	}
+
+	for path, items := range byFile {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		zw := gzip.NewWriter(f)
+		for _, l := range items {
+			line, err := sonic.Marshal(l)
+			if err == nil {
+				_, err = zw.Write(append(line, '\n'))
+			}
+			if err != nil {
+				_ = zw.Close()
+				_ = f.Close()
+				return err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteMessageLogsWithArchive 先归档再删除群内不在保留列表中的消息
+func (m *Manager) deleteMessageLogsWithArchive(groupID int64, keepIDs []uint) (int64, error) {
+	var deleted int64
+	var batch []MessageLog
+	err := m.db.Where("group_id = ? AND id NOT IN ?", groupID, keepIDs).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			if err := m.archiveMessageLogs(batch); err != nil {
+				return fmt.Errorf("归档消息失败: %w", err)
+			}
+			ids := make([]uint, 0, len(batch))
+			for _, l := range batch {
+				ids = append(ids, l.ID)
+			}
+			result := m.db.Where("id IN ?", ids).Delete(&MessageLog{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += result.RowsAffected
+			return nil
+		}).Error
+	return deleted, err
+}
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"compress/gzip"
+	"fmt"
+	"mumu-bot/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+// ==================== 定时备份 ====================
+
+const (
+	backupFilePrefix = "mumu-backup-"
+	backupFileSuffix = ".json.gz"
+)
+
+// BackupFile 备份目录中的一份备份
+type BackupFile struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// backupDir 备份目录，默认 ./backups
+func (m *Manager) backupDir() string {
+	if m.cfg.Backup.Dir != "" {
+		return m.cfg.Backup.Dir
+	}
+	return "./backups"
+}
+
+// startAutoBackup 按 cron 表达式定时备份，每分钟检查一次
+func (m *Manager) startAutoBackup() {
+	expr := m.cfg.Backup.Cron
+	if expr == "" {
+		expr = "0 4 * * *"
+	}
+	schedule, err := utils.ParseCron(expr)
+	if err != nil {
+		zap.L().Warn("定时备份的 cron 表达式无效，任务未启动", zap.String("cron", expr), zap.Error(err))
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if !schedule.Match(now) {
+					continue
+				}
+				if name, err := m.RunBackup(); err != nil {
+					zap.L().Warn("定时备份失败", zap.Error(err))
+				} else {
+					zap.L().Info("定时备份完成", zap.String("file", name))
+				}
+			case <-m.cleanupStop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	zap.L().Info("定时备份任务已启动", zap.String("cron", expr), zap.String("dir", m.backupDir()))
+}
+
+// RunBackup 立即备份一次：导出人格资产为 gzip 压缩的 JSON，并清理超出保留份数的旧备份
+// 返回备份文件名
+func (m *Manager) RunBackup() (string, error) {
+	m.backupMu.Lock()
+	defer m.backupMu.Unlock()
+
+	dir := m.backupDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	backup, err := m.Export()
+	if err != nil {
+		return "", err
+	}
+	data, err := sonic.Marshal(backup)
+	if err != nil {
+		return "", err
+	}
+
+	name := backupFilePrefix + time.Now().Format("20060102-150405") + backupFileSuffix
+	path := filepath.Join(dir, name)
+	// 先写临时文件再改名，避免中途失败留下不完整的备份
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("创建备份文件失败: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("写入备份失败: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("写入备份失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("写入备份失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("保存备份失败: %w", err)
+	}
+
+	m.pruneBackups()
+	return name, nil
+}
+
+// ListBackups 列出备份目录中的备份，新的在前
+func (m *Manager) ListBackups() ([]BackupFile, error) {
+	entries, err := os.ReadDir(m.backupDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []BackupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, backupFileSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, BackupFile{Name: name, Size: info.Size(), CreatedAt: info.ModTime()})
+	}
+	// 文件名带时间戳，按名称倒序即按时间倒序
+	sort.Slice(files, func(i, j int) bool { return files[i].Name > files[j].Name })
+	return files, nil
+}
+
+// pruneBackups 只保留最近 N 份备份，默认 7 份
+func (m *Manager) pruneBackups() {
+	keep := m.cfg.Backup.Keep
+	if keep <= 0 {
+		keep = 7
+	}
+	files, err := m.ListBackups()
+	if err != nil || len(files) <= keep {
+		return
+	}
+	for _, f := range files[keep:] {
+		if err := os.Remove(filepath.Join(m.backupDir(), f.Name)); err != nil {
+			zap.L().Warn("删除旧备份失败", zap.String("file", f.Name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// BackupVersion 备份格式版本
+// 版本 2 起成员画像拆分为全局用户档案和群内成员画像
+const BackupVersion = 2
+
+// Backup 人格资产备份（记忆、黑话、表达方式、用户档案、成员画像）
+type Backup struct {
+	Version        int                 `json:"version"`
+	ExportedAt     time.Time           `json:"exported_at"`
+	Memories       []Memory            `json:"memories"`
+	Jargons        []Jargon            `json:"jargons"`
+	Expressions    []Expression        `json:"expressions"`
+	UserProfiles   []GlobalUserProfile `json:"user_profiles"`
+	MemberProfiles []MemberProfile     `json:"member_profiles"`
+}
+
+// UnmarshalJSON 兼容版本 1 的备份：旧版成员画像不区分群，按全局用户档案导入
+func (b *Backup) UnmarshalJSON(data []byte) error {
+	type backupAlias Backup
+	if err := sonic.Unmarshal(data, (*backupAlias)(b)); err != nil {
+		return err
+	}
+	if b.Version >= 2 {
+		return nil
+	}
+
+	var legacy struct {
+		MemberProfiles []GlobalUserProfile `json:"member_profiles"`
+	}
+	if err := sonic.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	b.UserProfiles = legacy.MemberProfiles
+	b.MemberProfiles = nil
+	return nil
+}
+
+// ImportResult 导入结果统计
+type ImportResult struct {
+	Memories       int `json:"memories"`
+	Jargons        int `json:"jargons"`
+	Expressions    int `json:"expressions"`
+	UserProfiles   int `json:"user_profiles"`
+	MemberProfiles int `json:"member_profiles"`
+	Skipped        int `json:"skipped"` // 已存在而跳过的记忆数
+}
+
+// Export 导出全部人格资产
+func (m *Manager) Export() (*Backup, error) {
+	b := &Backup{Version: BackupVersion, ExportedAt: time.Now()}
+	if err := m.db.Order("id ASC").Find(&b.Memories).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Order("id ASC").Find(&b.Jargons).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Order("id ASC").Find(&b.Expressions).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Order("id ASC").Find(&b.UserProfiles).Error; err != nil {
+		return nil, err
+	}
+	if err := m.db.Order("id ASC").Find(&b.MemberProfiles).Error; err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Import 从备份导入人格资产，记忆会重新生成向量
+// 内容完全相同的记忆会被跳过，黑话和表达方式按原有规则去重，用户档案按 QQ 号、成员画像按群和 QQ 号覆盖
+func (m *Manager) Import(ctx context.Context, b *Backup) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for i := range b.Memories {
+		mem := b.Memories[i]
+		var count int64
+		if err := m.db.Model(&Memory{}).
+			Where("group_id = ? AND type = ? AND persona = ? AND content = ?", mem.GroupID, mem.Type, mem.Persona, mem.Content).
+			Count(&count).Error; err != nil {
+			return result, err
+		}
+		if count > 0 {
+			result.Skipped++
+			continue
+		}
+		mem.ID = 0
+		if _, err := m.saveMemory(ctx, &mem, false); err != nil {
+			return result, err
+		}
+		result.Memories++
+	}
+
+	for i := range b.Jargons {
+		jargon := b.Jargons[i]
+		jargon.ID = 0
+		if err := m.SaveJargon(&jargon); err != nil {
+			return result, err
+		}
+		result.Jargons++
+	}
+
+	for i := range b.Expressions {
+		exp := b.Expressions[i]
+		exp.ID = 0
+		saved, err := m.SaveExpression(&exp)
+		if err != nil {
+			return result, err
+		}
+		if saved {
+			result.Expressions++
+		}
+	}
+
+	for i := range b.UserProfiles {
+		profile := b.UserProfiles[i]
+		profile.ID = 0
+		if err := m.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			UpdateAll: true,
+		}).Create(&profile).Error; err != nil {
+			return result, err
+		}
+		result.UserProfiles++
+	}
+
+	for i := range b.MemberProfiles {
+		profile := b.MemberProfiles[i]
+		profile.ID = 0
+		if err := m.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "group_id"}, {Name: "user_id"}},
+			UpdateAll: true,
+		}).Create(&profile).Error; err != nil {
+			return result, err
+		}
+		result.MemberProfiles++
+	}
+
+	zap.L().Info("人格资产导入完成",
+		zap.Int("memories", result.Memories),
+		zap.Int("jargons", result.Jargons),
+		zap.Int("expressions", result.Expressions),
+		zap.Int("user_profiles", result.UserProfiles),
+		zap.Int("member_profiles", result.MemberProfiles),
+		zap.Int("skipped", result.Skipped))
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHotCacheTTL 热数据缓存的默认有效期
+const defaultHotCacheTTL = 30 * time.Second
+
+// ttlEntry 缓存条目
+type ttlEntry[V any] struct {
+	value    V
+	expireAt time.Time
+}
+
+// ttlCache 带过期时间的进程内缓存，保存值的副本，读写都返回副本，调用方可以放心修改
+// ttl 为 0 时不缓存
+type ttlCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[K]ttlEntry[V]
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{ttl: ttl, items: make(map[K]ttlEntry[V])}
+}
+
+// get 获取未过期的缓存
+func (c *ttlCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok || time.Now().After(e.expireAt) {
+		delete(c.items, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// set 写入缓存
+func (c *ttlCache[K, V]) set(key K, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 顺便清理过期条目，避免长期运行后堆积
+	if len(c.items) > 1024 {
+		now := time.Now()
+		for k, e := range c.items {
+			if now.After(e.expireAt) {
+				delete(c.items, k)
+			}
+		}
+	}
+	c.items[key] = ttlEntry[V]{value: value, expireAt: time.Now().Add(c.ttl)}
+}
+
+// update 就地修改已缓存的值（不延长有效期），不存在时什么也不做
+func (c *ttlCache[K, V]) update(key K, fn func(v *V)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		fn(&e.value)
+		c.items[key] = e
+	}
+}
+
+// del 使缓存失效
+func (c *ttlCache[K, V]) del(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// delWhere 使满足条件的缓存失效
+func (c *ttlCache[K, V]) delWhere(match func(key K) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.items {
+		if match(k) {
+			delete(c.items, k)
+		}
+	}
+}
+
+// memberKey 群内成员画像的缓存键
+type memberKey struct {
+	groupID int64
+	userID  int64
+}
+
+// expressionQuery 表达方式检索的缓存键
+type expressionQuery struct {
+	groupID int64
+	keyword string
+	limit   int
+}
+
+// hotCaches 思考时频繁读取的数据缓存（用户档案、成员画像、群信息、情绪、表达方式检索）
+// 写操作时对应条目失效，其余情况最多延迟 ttl 后看到数据库中的变化
+type hotCaches struct {
+	users       *ttlCache[int64, GlobalUserProfile]
+	members     *ttlCache[memberKey, MemberProfile]
+	groups      *ttlCache[int64, GroupInfo]
+	mood        *ttlCache[struct{}, MoodState]
+	expressions *ttlCache[expressionQuery, []Expression]
+}
+
+// newHotCaches 创建热数据缓存，ttl 为负数时关闭缓存
+func newHotCaches(ttlSeconds int) *hotCaches {
+	ttl := defaultHotCacheTTL
+	if ttlSeconds > 0 {
+		ttl = time.Duration(ttlSeconds) * time.Second
+	} else if ttlSeconds < 0 {
+		ttl = 0
+	}
+	return &hotCaches{
+		users:       newTTLCache[int64, GlobalUserProfile](ttl),
+		members:     newTTLCache[memberKey, MemberProfile](ttl),
+		groups:      newTTLCache[int64, GroupInfo](ttl),
+		mood:        newTTLCache[struct{}, MoodState](ttl),
+		expressions: newTTLCache[expressionQuery, []Expression](ttl),
+	}
+}
+
+// invalidateExpressions 使某群的表达方式检索缓存失效
+func (h *hotCaches) invalidateExpressions(groupID int64) {
+	h.expressions.delWhere(func(k expressionQuery) bool { return k.groupID == groupID })
+}
+
+// invalidateGroup 使某群的全部缓存失效
+func (h *hotCaches) invalidateGroup(groupID int64) {
+	h.members.delWhere(func(k memberKey) bool { return k.groupID == groupID })
+	h.groups.del(groupID)
+	h.invalidateExpressions(groupID)
+}
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"fmt"
+	"mumu-bot/internal/config"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openDB 根据配置的驱动连接数据库
+func openDB(cfg *config.MemoryConfig, gormCfg *gorm.Config) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "", "mysql":
+		mysqlCfg := cfg.MySQL
+		if mysqlCfg.Host == "" {
+			mysqlCfg.Host = "127.0.0.1"
+		}
+		if mysqlCfg.Port == 0 {
+			mysqlCfg.Port = 3306
+		}
+		if mysqlCfg.DBName == "" {
+			mysqlCfg.DBName = "mumu_bot"
+		}
+
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			mysqlCfg.User,
+			mysqlCfg.Password,
+			mysqlCfg.Host,
+			mysqlCfg.Port,
+			mysqlCfg.DBName,
+		)
+		db, err := gorm.Open(mysql.Open(dsn), gormCfg)
+		if err != nil {
+			return nil, fmt.Errorf("连接 MySQL 数据库失败: %w", err)
+		}
+		return db, nil
+
+	case "postgres":
+		pgCfg := cfg.Postgres
+		if pgCfg.Host == "" {
+			pgCfg.Host = "127.0.0.1"
+		}
+		if pgCfg.Port == 0 {
+			pgCfg.Port = 5432
+		}
+		if pgCfg.DBName == "" {
+			pgCfg.DBName = "mumu_bot"
+		}
+		if pgCfg.SSLMode == "" {
+			pgCfg.SSLMode = "disable"
+		}
+
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=Local",
+			pgCfg.Host,
+			pgCfg.Port,
+			pgCfg.User,
+			pgCfg.Password,
+			pgCfg.DBName,
+			pgCfg.SSLMode,
+		)
+		db, err := gorm.Open(postgres.Open(dsn), gormCfg)
+		if err != nil {
+			return nil, fmt.Errorf("连接 PostgreSQL 数据库失败: %w", err)
+		}
+		return db, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
+
+// like 生成不区分大小写的模糊匹配条件
+// MySQL 的默认排序规则本身不区分大小写，PostgreSQL 需要使用 ILIKE
+func (m *Manager) like(column string) string {
+	if m.db.Dialector.Name() == "postgres" {
+		return column + " ILIKE ?"
+	}
+	return column + " LIKE ?"
+}
+
+// fulltextIndexes 关键词检索用到的全文索引（MySQL，ngram 分词以支持中文）
+var fulltextIndexes = []struct {
+	table   string
+	name    string
+	columns []string
+}{
+	{"memories", "idx_memories_text_ft", []string{"content", "keywords"}},
+	{"jargons", "idx_jargons_content_ft", []string{"content"}},
+	{"expressions", "idx_expressions_text_ft", []string{"situation", "style", "examples"}},
+}
+
+// createFulltextIndexes 为 MySQL 创建全文索引（迁移 v14），其他数据库跳过
+// 不支持 ngram 的数据库（如 MariaDB）创建失败时只记录警告，关键词检索使用 LIKE
+func createFulltextIndexes(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "mysql" {
+		return nil
+	}
+	for _, idx := range fulltextIndexes {
+		if tx.Migrator().HasIndex(idx.table, idx.name) {
+			continue
+		}
+		sql := fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s) WITH PARSER ngram", idx.name, idx.table, strings.Join(idx.columns, ", "))
+		if err := tx.Exec(sql).Error; err != nil {
+			zap.L().Warn("创建全文索引失败，关键词检索将使用 LIKE", zap.String("index", idx.name), zap.Error(err))
+			return nil
+		}
+	}
+	return nil
+}
+
+// dropFulltextIndexes 删除全文索引
+func dropFulltextIndexes(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "mysql" {
+		return nil
+	}
+	for _, idx := range fulltextIndexes {
+		if !tx.Migrator().HasIndex(idx.table, idx.name) {
+			continue
+		}
+		if err := tx.Migrator().DropIndex(idx.table, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasFulltextIndexes 检查 MySQL 上的全文索引是否都已创建，只读不修改表结构
+func hasFulltextIndexes(db *gorm.DB) bool {
+	if db.Dialector.Name() != "mysql" {
+		return false
+	}
+	for _, idx := range fulltextIndexes {
+		if !db.Migrator().HasIndex(idx.table, idx.name) {
+			return false
+		}
+	}
+	return true
+}
+
+// findByKeywords 按关键词（任一匹配）查询，q 中已包含其他条件、排序和数量限制
+// 有全文索引时优先使用 MATCH AGAINST，没有结果（如单字关键词达不到 ngram 长度）时回退到 LIKE
+func (m *Manager) findByKeywords(q *gorm.DB, columns []string, keywords []string, dest any) error {
+	q = q.Session(&gorm.Session{})
+	if m.fulltext {
+		if query := booleanQuery(keywords); query != "" {
+			cond := fmt.Sprintf("MATCH(%s) AGAINST(? IN BOOLEAN MODE)", strings.Join(columns, ", "))
+			tx := q.Where(cond, query).Find(dest)
+			if tx.Error == nil && tx.RowsAffected > 0 {
+				return nil
+			}
+		}
+	}
+
+	conds := make([]string, 0, len(keywords)*len(columns))
+	args := make([]any, 0, len(keywords)*len(columns))
+	for _, kw := range keywords {
+		for _, col := range columns {
+			conds = append(conds, m.like(col))
+			args = append(args, "%"+kw+"%")
+		}
+	}
+	return q.Where(strings.Join(conds, " OR "), args...).Find(dest).Error
+}
+
+// booleanQuery 把关键词转成 BOOLEAN MODE 查询串，去掉其中的操作符
+func booleanQuery(keywords []string) string {
+	clean := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		kw = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, kw)
+		if kw != "" {
+			clean = append(clean, kw)
+		}
+	}
+	return strings.Join(clean, " ")
+}
+
+// legacyMemberColumns 旧版 member_profiles 中迁移到全局档案的列，
+// 后面几列是后来才加的，旧库里不一定有，迁移时只复制实际存在的列
+var legacyMemberColumns = []string{
+	"created_at", "updated_at", "user_id", "nickname", "speak_style", "interests", "common_words", "intimacy", "last_speak", "msg_count",
+	"birthday", "games_played", "games_won", "last_cared_at",
+}
+
+// migrateMemberProfiles 将旧版（不区分群）的成员画像迁移到全局用户档案
+// 旧记录没有 group_id，迁移后删除，群内画像会在成员下次发言时重新建立
+func migrateMemberProfiles(db *gorm.DB) error {
+	migrator := db.Migrator()
+	// 旧版按 user_id 唯一，会阻止同一用户在多个群建立画像
+	for _, name := range []string{":idx_user", "idx_user"} {
+		if migrator.HasIndex(&MemberProfile{}, name) {
+			if err := migrator.DropIndex(&MemberProfile{}, name); err != nil {
+				return fmt.Errorf("删除旧成员画像索引失败: %w", err)
+			}
+		}
+	}
+	if !migrator.HasColumn(&MemberProfile{}, "interests") {
+		return nil
+	}
+
+	legacy := db.Model(&MemberProfile{}).Where("group_id = 0 OR group_id IS NULL")
+	var count int64
+	if err := legacy.Count(&count).Error; err != nil || count == 0 {
+		return err
+	}
+
+	columns := make([]string, 0, len(legacyMemberColumns))
+	for _, col := range legacyMemberColumns {
+		if migrator.HasColumn(&MemberProfile{}, col) {
+			columns = append(columns, col)
+		}
+	}
+	columnList := strings.Join(columns, ", ")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		sql := fmt.Sprintf("INSERT INTO global_user_profiles (%s) SELECT %s FROM member_profiles "+
+			"WHERE (group_id = 0 OR group_id IS NULL) AND user_id NOT IN (SELECT user_id FROM global_user_profiles)",
+			columnList, columnList)
+		if err := tx.Exec(sql).Error; err != nil {
+			return fmt.Errorf("迁移全局用户档案失败: %w", err)
+		}
+		if err := tx.Where("group_id = 0 OR group_id IS NULL").Delete(&MemberProfile{}).Error; err != nil {
+			return err
+		}
+		zap.L().Info("已迁移旧版成员画像到全局用户档案", zap.Int64("count", count))
+		return nil
+	})
+}
+
+// migrateStickerIndex 删除旧版表情包按哈希全局唯一的索引，改为按群和哈希唯一
+func migrateStickerIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasIndex(&Sticker{}, "idx_stickers_file_hash") {
+		if err := migrator.DropIndex(&Sticker{}, "idx_stickers_file_hash"); err != nil {
+			return fmt.Errorf("删除旧表情包索引失败: %w", err)
+		}
+	}
+	return nil
+}
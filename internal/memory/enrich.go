@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MemoryEnricher 为记忆生成摘要和关键词（由 Agent 用 LLM 实现）
+type MemoryEnricher interface {
+	EnrichMemory(ctx context.Context, mem *Memory) (summary string, keywords []string, err error)
+}
+
+// SetEnricher 设置记忆摘要生成器，为 nil 时不生成
+func (m *Manager) SetEnricher(e MemoryEnricher) {
+	m.enricher = e
+}
+
+// enrichAsync 异步生成记忆的摘要和关键词，已有摘要（如导入的记忆）时跳过
+// 内容在生成期间被修改时放弃写回
+func (m *Manager) enrichAsync(mem Memory) {
+	if m.enricher == nil || mem.ID == 0 || mem.Summary != "" {
+		return
+	}
+	m.enrichWG.Add(1)
+	go func() {
+		defer m.enrichWG.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		summary, keywords, err := m.enricher.EnrichMemory(ctx, &mem)
+		if err != nil {
+			zap.L().Debug("生成记忆摘要失败", zap.Uint("id", mem.ID), zap.Error(err))
+			return
+		}
+		clean := make([]string, 0, len(keywords))
+		for _, kw := range keywords {
+			if kw = strings.TrimSpace(strings.ReplaceAll(kw, ",", " ")); kw != "" {
+				clean = append(clean, kw)
+			}
+		}
+		err = m.db.Model(&Memory{}).
+			Where("id = ? AND content = ?", mem.ID, mem.Content).
+			UpdateColumns(map[string]any{
+				"summary":  truncateRunes(strings.TrimSpace(summary), 100),
+				"keywords": truncateRunes(strings.Join(clean, ","), 255),
+			}).Error
+		if err != nil {
+			zap.L().Warn("保存记忆摘要失败", zap.Uint("id", mem.ID), zap.Error(err))
+		}
+	}()
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// expressionResponseWindow 模仿发言后多久内的回复或@算作回应
+const expressionResponseWindow = 10 * time.Minute
+
+// expressionUse 一次等待回应的表达模仿
+type expressionUse struct {
+	expressionID uint
+	messageID    int64
+	at           time.Time
+}
+
+// RecordExpressionUse 记录一次模仿表达方式的发言，并开始等待群友回应
+func (m *Manager) RecordExpressionUse(groupID int64, expressionID uint, messageID int64) error {
+	now := time.Now()
+	if err := m.db.Model(&Expression{}).Where("id = ?", expressionID).UpdateColumns(map[string]any{
+		"count":        gorm.Expr("count + 1"),
+		"last_used_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	m.expressionUsesMu.Lock()
+	m.expressionUses[groupID] = append(m.expressionUses[groupID], expressionUse{
+		expressionID: expressionID,
+		messageID:    messageID,
+		at:           now,
+	})
+	m.expressionUsesMu.Unlock()
+	return nil
+}
+
+// ResolveExpressionResponse 群友回复或@沐沐时，把窗口内的模仿发言记为得到回应
+// replyTo 为群友回复的消息 ID（没有回复时为 0），mentioned 表示是否@了沐沐
+func (m *Manager) ResolveExpressionResponse(groupID, replyTo int64, mentioned bool, t time.Time) {
+	m.expressionUsesMu.Lock()
+	uses := m.expressionUses[groupID]
+	if len(uses) == 0 {
+		m.expressionUsesMu.Unlock()
+		return
+	}
+
+	var responded []uint
+	pending := uses[:0]
+	for _, u := range uses {
+		if t.Sub(u.at) > expressionResponseWindow {
+			continue
+		}
+		if (replyTo != 0 && replyTo == u.messageID) || (mentioned && replyTo == 0) {
+			responded = append(responded, u.expressionID)
+			continue
+		}
+		pending = append(pending, u)
+	}
+	if len(pending) == 0 {
+		delete(m.expressionUses, groupID)
+	} else {
+		m.expressionUses[groupID] = pending
+	}
+	m.expressionUsesMu.Unlock()
+
+	for _, id := range responded {
+		if err := m.db.Model(&Expression{}).Where("id = ?", id).
+			UpdateColumn("responses", gorm.Expr("responses + 1")).Error; err != nil {
+			zap.L().Warn("记录表达方式回应失败", zap.Uint("id", id), zap.Error(err))
+		}
+	}
+}
+
+// startExpressionPrune 启动表达方式淘汰定时任务
+func (m *Manager) startExpressionPrune() {
+	intervalHours := m.cfg.Memory.ExpressionPrune.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+
+	ticker := time.NewTicker(time.Duration(intervalHours) * time.Hour)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if n, err := m.PruneExpressions(); err != nil {
+					zap.L().Warn("淘汰表达方式失败", zap.Error(err))
+				} else if n > 0 {
+					zap.L().Info("已淘汰表达方式", zap.Int64("count", n))
+				}
+			case <-m.cleanupStop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	zap.L().Info("表达方式淘汰任务已启动")
+}
+
+// PruneExpressions 删除长期没被模仿、或模仿多次却很少得到回应的表达方式
+// 人工审核通过的表达不会因为没用过而被淘汰，但回应率过低时仍会淘汰
+func (m *Manager) PruneExpressions() (int64, error) {
+	cfg := m.cfg.Memory.ExpressionPrune
+	unusedDays := cfg.UnusedDays
+	if unusedDays <= 0 {
+		unusedDays = 60
+	}
+	minUses := cfg.MinUses
+	if minUses <= 0 {
+		minUses = 5
+	}
+	minRate := cfg.MinResponseRate
+	if minRate <= 0 {
+		minRate = 0.1
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -unusedDays)
+	unused := m.db.Where("created_at < ? AND (last_used_at IS NULL OR last_used_at < ?)", cutoff, cutoff).
+		Where("NOT (checked = ? AND rejected = ?)", true, false)
+	res := unused.Delete(&Expression{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	pruned := res.RowsAffected
+
+	res = m.db.Where("count >= ? AND responses < count * ?", minUses, minRate).Delete(&Expression{})
+	// 淘汰会跨越多个群，清空全部表达方式检索缓存
+	m.cache.expressions.delWhere(func(expressionQuery) bool { return true })
+	if res.Error != nil {
+		return pruned, res.Error
+	}
+	return pruned + res.RowsAffected, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger 把 GORM 日志接入 zap
+type gormLogger struct {
+	level   logger.LogLevel
+	slow    time.Duration // 慢查询阈值
+	showSQL bool          // 是否以 Debug 级别输出每条 SQL
+}
+
+// newGormLogger 创建 zap 适配的 GORM 日志
+func newGormLogger(showSQL bool, slow time.Duration) *gormLogger {
+	return &gormLogger{level: logger.Warn, slow: slow, showSQL: showSQL}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	nl := *l
+	nl.level = level
+	return &nl
+}
+
+func (l *gormLogger) Info(_ context.Context, msg string, data ...any) {
+	if l.level >= logger.Info {
+		zap.L().Named("gorm").Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Warn(_ context.Context, msg string, data ...any) {
+	if l.level >= logger.Warn {
+		zap.L().Named("gorm").Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Error(_ context.Context, msg string, data ...any) {
+	if l.level >= logger.Error {
+		zap.L().Named("gorm").Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace 记录每条 SQL：出错时 Error，超过慢查询阈值时 Warn，开启 SQL 输出时 Debug
+func (l *gormLogger) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		zap.L().Named("gorm").Error("SQL 执行失败",
+			zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Error(err))
+	case l.slow > 0 && elapsed > l.slow && l.level >= logger.Warn:
+		sql, rows := fc()
+		zap.L().Named("gorm").Warn("慢查询",
+			zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Duration("threshold", l.slow))
+	case l.showSQL:
+		sql, rows := fc()
+		zap.L().Named("gorm").Debug("SQL",
+			zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	}
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ==================== 亲密度演化 ====================
+
+// Interaction 与群友的一次互动
+type Interaction string
+
+const (
+	InteractionReply   Interaction = "reply"   // 对方回复了你的消息
+	InteractionMention Interaction = "mention" // 对方 @ 了你
+	InteractionPoke    Interaction = "poke"    // 对方戳了戳你
+	InteractionReplied Interaction = "replied" // 你回复或 @ 了对方
+)
+
+// interactionGains 每种互动带来的亲密度增量
+var interactionGains = map[Interaction]float64{
+	InteractionReply:   0.01,
+	InteractionMention: 0.01,
+	InteractionPoke:    0.005,
+	InteractionReplied: 0.005,
+}
+
+// intimacyGainState 某个用户当天通过互动累积的亲密度
+type intimacyGainState struct {
+	date   string
+	gained float64
+}
+
+// intimacyDailyCap 每个用户每天通过互动最多增加的亲密度，默认 0.05
+func (m *Manager) intimacyDailyCap() float64 {
+	if m.cfg.Memory.Intimacy.DailyCap > 0 {
+		return m.cfg.Memory.Intimacy.DailyCap
+	}
+	return 0.05
+}
+
+// RecordInteraction 记录一次互动：累加互动次数，并按规则增加亲密度（每天有上限）
+func (m *Manager) RecordInteraction(userID int64, kind Interaction, t time.Time) {
+	if !m.cfg.Memory.Intimacy.Enabled || userID == 0 {
+		return
+	}
+	gain := interactionGains[kind]
+
+	m.intimacyGainsMu.Lock()
+	today := t.Format(time.DateOnly)
+	state, ok := m.intimacyGains[userID]
+	if !ok || state.date != today {
+		state = &intimacyGainState{date: today}
+		m.intimacyGains[userID] = state
+	}
+	gain = min(gain, max(m.intimacyDailyCap()-state.gained, 0))
+	state.gained += gain
+	m.intimacyGainsMu.Unlock()
+
+	err := m.db.Model(&GlobalUserProfile{}).Where("user_id = ?", userID).UpdateColumns(map[string]any{
+		"interactions": gorm.Expr("interactions + 1"),
+		"intimacy":     gorm.Expr("CASE WHEN intimacy + ? > 1 THEN 1 ELSE intimacy + ? END", gain, gain),
+	}).Error
+	if err != nil {
+		m.cache.users.del(userID)
+		zap.L().Warn("记录互动失败", zap.Int64("user_id", userID), zap.String("kind", string(kind)), zap.Error(err))
+		return
+	}
+	m.cache.users.update(userID, func(p *GlobalUserProfile) {
+		p.Interactions++
+		p.Intimacy = min(p.Intimacy+gain, 1)
+	})
+}
+
+// AdjustIntimacy 把 LLM 给出的亲密度作为修正项：单次调整幅度不超过上限，返回实际生效的值
+// 未开启亲密度演化时直接采用 LLM 给出的值
+func (m *Manager) AdjustIntimacy(current, target float64) float64 {
+	target = min(max(target, 0), 1)
+	if !m.cfg.Memory.Intimacy.Enabled {
+		return target
+	}
+	maxDelta := m.cfg.Memory.Intimacy.MaxLLMDelta
+	if maxDelta <= 0 {
+		maxDelta = 0.1
+	}
+	return min(max(target, current-maxDelta), current+maxDelta)
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// jargonCacheTTL 已知黑话缓存的有效期，过期后重新从数据库加载
+	jargonCacheTTL = 5 * time.Minute
+	// jargonStaleDays 超过该天数没人使用的黑话在检索时降权
+	jargonStaleDays = 30
+)
+
+// jargonCacheEntry 某个群的已知黑话缓存
+type jargonCacheEntry struct {
+	jargons  []Jargon
+	loadedAt time.Time
+}
+
+// groupJargons 获取某群的已知黑话（只含 ID 和内容），带缓存
+func (m *Manager) groupJargons(groupID int64) ([]Jargon, error) {
+	m.jargonCacheMu.Lock()
+	defer m.jargonCacheMu.Unlock()
+
+	if entry, ok := m.jargonCache[groupID]; ok && time.Since(entry.loadedAt) < jargonCacheTTL {
+		return entry.jargons, nil
+	}
+
+	var jargons []Jargon
+	if err := m.db.Select("id", "content").Where("group_id = ?", groupID).Find(&jargons).Error; err != nil {
+		return nil, err
+	}
+	m.jargonCache[groupID] = &jargonCacheEntry{jargons: jargons, loadedAt: time.Now()}
+	return jargons, nil
+}
+
+// invalidateJargonCache 新增黑话后清除该群的缓存
+func (m *Manager) invalidateJargonCache(groupID int64) {
+	m.jargonCacheMu.Lock()
+	delete(m.jargonCache, groupID)
+	m.jargonCacheMu.Unlock()
+}
+
+// RecordJargonUsage 匹配消息中出现的本群已知黑话，累加使用次数并记录最后使用时间
+func (m *Manager) RecordJargonUsage(groupID int64, content string, t time.Time) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	jargons, err := m.groupJargons(groupID)
+	if err != nil {
+		zap.L().Warn("加载黑话失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return
+	}
+
+	var ids []uint
+	for _, j := range jargons {
+		if j.Content != "" && strings.Contains(content, j.Content) {
+			ids = append(ids, j.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	if err := m.db.Model(&Jargon{}).Where("id IN ?", ids).UpdateColumns(map[string]any{
+		"count":        gorm.Expr("count + 1"),
+		"last_used_at": t,
+	}).Error; err != nil {
+		zap.L().Warn("更新黑话使用次数失败", zap.Int64("group_id", groupID), zap.Error(err))
+	}
+}
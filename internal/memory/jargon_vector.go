@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"mumu-bot/internal/vector"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// jargonMatchThreshold 黑话与聊天内容语义匹配的相似度下限
+// 聊天上下文较长，与单条黑话的相似度普遍低于记忆检索，阈值也相应放低
+const jargonMatchThreshold = 0.5
+
+// jargonVector 内存中的黑话向量（已归一化）
+type jargonVector struct {
+	groupID int64
+	vec     []float32
+}
+
+// jargonVectorsEnabled 配置了 embedding 和向量检索时才为黑话生成向量
+// 黑话数量不多，向量保存在 jargons 表中，启动时加载到内存检索，不占用记忆的向量集合
+func (m *Manager) jargonVectorsEnabled() bool {
+	return m.embedding != nil && m.vectors != nil
+}
+
+// loadJargonVectors 加载已有的黑话向量，并在后台为还没有向量的黑话补生成
+func (m *Manager) loadJargonVectors() {
+	var jargons []Jargon
+	if err := m.db.Select("id", "group_id", "content", "meaning", "embedding").Find(&jargons).Error; err != nil {
+		zap.L().Warn("加载黑话向量失败", zap.Error(err))
+		return
+	}
+
+	var missing []Jargon
+	m.jargonVecsMu.Lock()
+	for _, j := range jargons {
+		if len(j.Embedding) == 0 {
+			missing = append(missing, j)
+			continue
+		}
+		m.jargonVecs[j.ID] = &jargonVector{groupID: j.GroupID, vec: vector.DecodeVector(j.Embedding)}
+	}
+	m.jargonVecsMu.Unlock()
+
+	if len(missing) == 0 {
+		return
+	}
+	go func() {
+		for i := range missing {
+			select {
+			case <-m.cleanupStop:
+				return
+			default:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			m.embedJargon(ctx, &missing[i])
+			cancel()
+		}
+		zap.L().Info("已为黑话补充向量", zap.Int("count", len(missing)))
+	}()
+}
+
+// embedJargon 为黑话（内容和含义）生成向量，保存到数据库并加入内存索引
+func (m *Manager) embedJargon(ctx context.Context, j *Jargon) {
+	if !m.jargonVectorsEnabled() || j.ID == 0 {
+		return
+	}
+	emb, err := m.embedding.Embed(ctx, j.Content+"："+j.Meaning)
+	if err != nil {
+		zap.L().Debug("生成黑话向量失败", zap.Uint("id", j.ID), zap.Error(err))
+		return
+	}
+	vec := vector.ToFloat32(emb)
+	if err := m.db.Model(&Jargon{}).Where("id = ?", j.ID).UpdateColumn("embedding", vector.EncodeVector(vec)).Error; err != nil {
+		zap.L().Warn("保存黑话向量失败", zap.Uint("id", j.ID), zap.Error(err))
+		return
+	}
+
+	m.jargonVecsMu.Lock()
+	m.jargonVecs[j.ID] = &jargonVector{groupID: j.GroupID, vec: vector.Normalize(vec)}
+	m.jargonVecsMu.Unlock()
+}
+
+// embedJargonAsync 后台为新保存或含义变化的黑话生成向量
+func (m *Manager) embedJargonAsync(j Jargon) {
+	if !m.jargonVectorsEnabled() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		m.embedJargon(ctx, &j)
+	}()
+}
+
+// removeGroupJargonVectors 从内存索引中移除某个群的黑话向量
+func (m *Manager) removeGroupJargonVectors(groupID int64) {
+	m.jargonVecsMu.Lock()
+	defer m.jargonVecsMu.Unlock()
+	for id, v := range m.jargonVecs {
+		if v.groupID == groupID {
+			delete(m.jargonVecs, id)
+		}
+	}
+}
+
+// searchJargonVectors 语义检索黑话，本群的结果优先，返回按顺序排列的黑话 ID
+func (m *Manager) searchJargonVectors(ctx context.Context, query string, groupID int64, limit int, threshold float64) []uint {
+	if !m.jargonVectorsEnabled() || strings.TrimSpace(query) == "" {
+		return nil
+	}
+	emb, err := m.embedding.Embed(ctx, query)
+	if err != nil {
+		zap.L().Debug("生成黑话检索向量失败", zap.Error(err))
+		return nil
+	}
+	q := vector.Normalize(vector.ToFloat32(emb))
+
+	type scored struct {
+		id      uint
+		score   float32
+		inGroup bool
+	}
+	var results []scored
+	m.jargonVecsMu.RLock()
+	for id, v := range m.jargonVecs {
+		if len(v.vec) != len(q) {
+			continue
+		}
+		var score float32
+		for i := range q {
+			score += q[i] * v.vec[i]
+		}
+		if float64(score) >= threshold {
+			results = append(results, scored{id: id, score: score, inGroup: v.groupID == groupID})
+		}
+	}
+	m.jargonVecsMu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].inGroup != results[j].inGroup {
+			return results[i].inGroup
+		}
+		return results[i].score > results[j].score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	ids := make([]uint, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.id)
+	}
+	return ids
+}
+
+// findJargonsByIDs 按给定 ID 顺序查询黑话
+func (m *Manager) findJargonsByIDs(ids []uint) ([]Jargon, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var jargons []Jargon
+	if err := m.db.Omit("embedding").Where("id IN ?", ids).Find(&jargons).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Jargon, len(jargons))
+	for _, j := range jargons {
+		byID[j.ID] = j
+	}
+	result := make([]Jargon, 0, len(jargons))
+	for _, id := range ids {
+		if j, ok := byID[id]; ok {
+			result = append(result, j)
+		}
+	}
+	return result, nil
+}
+
+// MatchJargons 找出与当前聊天内容相关的黑话：先取原文中出现的本群黑话，再按语义补充
+func (m *Manager) MatchJargons(ctx context.Context, groupID int64, chatContext string, limit int) ([]Jargon, error) {
+	if strings.TrimSpace(chatContext) == "" {
+		return nil, nil
+	}
+
+	var ids []uint
+	seen := make(map[uint]bool)
+	if jargons, err := m.groupJargons(groupID); err == nil {
+		for _, j := range jargons {
+			if len(ids) >= limit {
+				break
+			}
+			if j.Content != "" && strings.Contains(chatContext, j.Content) {
+				ids = append(ids, j.ID)
+				seen[j.ID] = true
+			}
+		}
+	}
+	if len(ids) < limit {
+		for _, id := range m.searchJargonVectors(ctx, chatContext, groupID, limit, jargonMatchThreshold) {
+			if len(ids) >= limit {
+				break
+			}
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
+			}
+		}
+	}
+	return m.findJargonsByIDs(ids)
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"mumu-bot/internal/config"
 	"mumu-bot/internal/utils"
 	"mumu-bot/internal/vector"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // EmbeddingProvider 向量嵌入接口
@@ -25,66 +29,109 @@ type Manager struct {
 	db          *gorm.DB
 	cfg         *config.Config
 	embedding   EmbeddingProvider
-	milvus      *vector.MilvusClient // Milvus 向量存储
+	vectors     vector.Store // 向量存储
+	fulltext    bool         // 是否可以使用全文索引（仅 MySQL）
 	cleanupStop chan struct{}
-}
 
-// NewManager 创建记忆管理器
-func NewManager(cfg *config.Config, embedding EmbeddingProvider) (*Manager, error) {
-	// 构建 MySQL DSN
-	mysqlCfg := cfg.Memory.MySQL
-	if mysqlCfg.Host == "" {
-		mysqlCfg.Host = "127.0.0.1"
-	}
-	if mysqlCfg.Port == 0 {
-		mysqlCfg.Port = 3306
-	}
-	if mysqlCfg.DBName == "" {
-		mysqlCfg.DBName = "mumu_bot"
-	}
+	jargonCache   map[int64]*jargonCacheEntry // 各群已知黑话，用于消息入库时统计使用次数
+	jargonCacheMu sync.Mutex
+	jargonVecs    map[uint]*jargonVector // 黑话向量的内存索引
+	jargonVecsMu  sync.RWMutex
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		mysqlCfg.User,
-		mysqlCfg.Password,
-		mysqlCfg.Host,
-		mysqlCfg.Port,
-		mysqlCfg.DBName,
-	)
+	expressionUses   map[int64][]expressionUse // 各群等待回应的表达模仿
+	expressionUsesMu sync.Mutex
 
-	db, err := gorm.Open(mysql.Open(dsn))
-	if err != nil {
-		return nil, fmt.Errorf("连接 MySQL 数据库失败: %w", err)
-	}
-
-	// 迁移所有表
-	if err := db.AutoMigrate(
-		&Memory{},
-		&MemberProfile{},
-		&Expression{},
-		&Jargon{},
-		&MessageLog{},
-		&Sticker{},
-		&MoodState{},
-	); err != nil {
-		return nil, fmt.Errorf("数据库迁移失败: %w", err)
-	}
-
-	// 初始化 Milvus 向量存储
-	var milvusClient *vector.MilvusClient
-	if cfg.Memory.Milvus.Enabled && embedding != nil {
-		milvusCfg := &vector.MilvusConfig{
+	msgBuf       []MessageLog // 等待批量写入的消息日志
+	msgBufMu     sync.Mutex
+	msgFlushMu   sync.Mutex    // 串行化落盘，读取前落盘时能等到正在写入的那一批
+	msgFlushDone chan struct{} // 批量写入任务退出信号，为 nil 时逐条写入
+
+	backupMu sync.Mutex // 避免定时备份和手动备份同时执行
+
+	moodChanges   []MoodChange // 最近的主动情绪变化（不含自然衰减）
+	moodChangesMu sync.Mutex
+
+	intimacyGains   map[int64]*intimacyGainState // 各用户当天的亲密度增量，用于限制每日上限（重启后重新计算）
+	intimacyGainsMu sync.Mutex
+
+	enricher MemoryEnricher // 记忆摘要和关键词生成器（可选）
+	cache    *hotCaches     // 思考时频繁读取的热数据缓存
+	enrichWG sync.WaitGroup
+}
+
+// openVectorStore 根据配置创建向量存储
+func openVectorStore(cfg *config.Config, db *gorm.DB, provider string) (vector.Store, error) {
+	switch provider {
+	case "milvus":
+		return vector.NewMilvusClient(&vector.MilvusConfig{
 			Address:        cfg.Memory.Milvus.Address,
 			DBName:         cfg.Memory.Milvus.DBName,
 			CollectionName: cfg.Memory.Milvus.CollectionName,
 			VectorDim:      cfg.Memory.Milvus.VectorDim,
 			MetricType:     cfg.Memory.Milvus.MetricType,
+		})
+	case "qdrant":
+		q := cfg.Memory.Vector.Qdrant
+		return vector.NewQdrantClient(&vector.QdrantConfig{
+			Address:        q.Address,
+			APIKey:         q.APIKey,
+			CollectionName: q.CollectionName,
+			VectorDim:      q.VectorDim,
+			Distance:       q.Distance,
+		})
+	case "local":
+		store, err := vector.NewLocalStore(db)
+		if err != nil {
+			return nil, err
 		}
-		milvusClient, err = vector.NewMilvusClient(milvusCfg)
+		zap.L().Info("进程内向量索引已加载", zap.Int("vectors", store.Len()))
+		return store, nil
+	case "pgvector":
+		return vector.NewPGVectorStore(db, &vector.PGVectorConfig{
+			TableName: cfg.Memory.Vector.PGVector.TableName,
+			VectorDim: cfg.Memory.Vector.PGVector.VectorDim,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的向量存储: %s", provider)
+	}
+}
+
+// NewManager 创建记忆管理器
+func NewManager(cfg *config.Config, embedding EmbeddingProvider) (*Manager, error) {
+	slowMs := cfg.Memory.SlowQueryMs
+	if slowMs <= 0 {
+		slowMs = 200
+	}
+	db, err := openDB(&cfg.Memory, &gorm.Config{
+		Logger: newGormLogger(cfg.Debug.ShowSQL, time.Duration(slowMs)*time.Millisecond),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行数据库迁移，关闭自动迁移时只检查是否有未执行的迁移
+	mg := &Migrator{db: db}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	if cfg.Memory.AutoMigrate == nil || *cfg.Memory.AutoMigrate {
+		if _, err := mg.Up(); err != nil {
+			return nil, fmt.Errorf("数据库迁移失败: %w", err)
+		}
+	} else if pending, err := mg.Pending(); err == nil && pending > 0 {
+		zap.L().Warn("存在未执行的数据库迁移，请执行 migrate up", zap.Int("pending", pending))
+	}
+
+	// 初始化向量存储
+	var store vector.Store
+	if provider := cfg.Memory.VectorProvider(); provider != "" && embedding != nil {
+		store, err = openVectorStore(cfg, db, provider)
 		if err != nil {
-			// Milvus 连接失败不影响整体运行，但向量检索功能将不可用
-			zap.L().Warn("Milvus 连接失败，向量检索功能将不可用", zap.Error(err))
+			// 向量存储连接失败不影响整体运行，但向量检索功能将不可用
+			store = nil
+			zap.L().Warn("向量存储连接失败，向量检索功能将不可用", zap.String("provider", provider), zap.Error(err))
 		} else {
-			zap.L().Info("Milvus 向量存储已连接")
+			zap.L().Info("向量存储已连接", zap.String("provider", provider))
 		}
 	}
 
@@ -92,8 +139,25 @@ func NewManager(cfg *config.Config, embedding EmbeddingProvider) (*Manager, erro
 		db:          db,
 		cfg:         cfg,
 		embedding:   embedding,
-		milvus:      milvusClient,
+		vectors:     store,
+		fulltext:    hasFulltextIndexes(db),
 		cleanupStop: make(chan struct{}),
+		jargonCache: make(map[int64]*jargonCacheEntry),
+		jargonVecs:  make(map[uint]*jargonVector),
+		cache:       newHotCaches(cfg.Memory.CacheTTL),
+
+		expressionUses: make(map[int64][]expressionUse),
+		intimacyGains:  make(map[int64]*intimacyGainState),
+	}
+
+	// 加载黑话向量
+	if m.jargonVectorsEnabled() {
+		m.loadJargonVectors()
+	}
+
+	// 启动消息日志批量写入任务
+	if m.messageBatchSize() > 1 {
+		m.startMessageFlusher()
 	}
 
 	// 启动消息日志清理任务
@@ -102,18 +166,57 @@ func NewManager(cfg *config.Config, embedding EmbeddingProvider) (*Manager, erro
 	// 启动情绪衰减任务
 	m.startMoodDecay()
 
+	// 启动记忆衰减任务
+	if cfg.Memory.Decay.Enabled {
+		m.startMemoryDecay()
+	}
+
+	// 启动记忆过期归档任务
+	if cfg.Memory.TTL.Enabled {
+		m.startMemoryTTL()
+	}
+
+	// 启动表情包清理任务
+	if cfg.Sticker.Cleanup.Enabled {
+		m.startStickerCleanup()
+	}
+
+	// 启动表达方式淘汰任务
+	if cfg.Memory.ExpressionPrune.Enabled {
+		m.startExpressionPrune()
+	}
+
+	// 启动定时备份任务
+	if cfg.Backup.Enabled {
+		m.startAutoBackup()
+	}
+
 	return m, nil
 }
 
 // ==================== 短期记忆 ====================
 
 // AddMessage 添加消息到短期记忆
+// 消息先进入缓冲区，攒够一批或到达刷新间隔时批量写入
 func (m *Manager) AddMessage(msg MessageLog) error {
-	return m.db.Create(&msg).Error
+	if m.msgFlushDone == nil {
+		return m.db.Create(&msg).Error
+	}
+
+	m.msgBufMu.Lock()
+	m.msgBuf = append(m.msgBuf, msg)
+	full := len(m.msgBuf) >= m.messageBatchSize()
+	m.msgBufMu.Unlock()
+
+	if full {
+		m.flushMessages()
+	}
+	return nil
 }
 
 // GetRecentMessages 获取最近的消息记录
 func (m *Manager) GetRecentMessages(groupID int64, limit, offset int) []MessageLog {
+	m.flushMessages()
 	var dbMsgs []MessageLog
 	q := m.db.Where("group_id = ?", groupID).Order("created_at DESC").Limit(limit)
 	if offset > 0 {
@@ -128,10 +231,132 @@ func (m *Manager) GetRecentMessages(groupID int64, limit, offset int) []MessageL
 	return dbMsgs
 }
 
+// GetMessagesSince 获取某个时间之后的消息记录（按时间正序，最多 limit 条，超出时保留最新的）
+func (m *Manager) GetMessagesSince(groupID int64, since time.Time, limit int) []MessageLog {
+	m.flushMessages()
+	var dbMsgs []MessageLog
+	m.db.Where("group_id = ? AND created_at >= ?", groupID, since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&dbMsgs)
+
+	// 反转，按时间正序排列
+	for i, j := 0, len(dbMsgs)-1; i < j; i, j = i+1, j-1 {
+		dbMsgs[i], dbMsgs[j] = dbMsgs[j], dbMsgs[i]
+	}
+	return dbMsgs
+}
+
+// GetUnsummarizedMessages 获取尚未被话题摘要处理的消息（按时间正序，最早的 limit 条）
+func (m *Manager) GetUnsummarizedMessages(groupID int64, limit int) []MessageLog {
+	var msgs []MessageLog
+	m.db.Where("group_id = ? AND summarized = ?", groupID, false).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&msgs)
+	return msgs
+}
+
+// MarkMessagesSummarized 标记消息已被摘要
+func (m *Manager) MarkMessagesSummarized(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.db.Model(&MessageLog{}).Where("id IN ?", ids).Update("summarized", true).Error
+}
+
+// GetSourceMessageIDs 为记忆挑选来源消息：指定用户时取其最近的发言，否则取群内最近的消息
+func (m *Manager) GetSourceMessageIDs(groupID, userID int64, limit int) []string {
+	// 刚收到的消息可能还在缓冲区中
+	m.flushMessages()
+
+	var ids []string
+	q := m.db.Model(&MessageLog{}).Where("group_id = ?", groupID)
+	if userID != 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	q.Order("created_at DESC").Limit(limit).Pluck("message_id", &ids)
+	return ids
+}
+
+// GetSourceMessages 按消息 ID 查找记忆的来源消息，已清理的从归档中查找
+func (m *Manager) GetSourceMessages(mem *Memory) []MessageLog {
+	if mem.SourceMsgID == "" {
+		return nil
+	}
+	ids := strings.Split(mem.SourceMsgID, ",")
+
+	var msgs []MessageLog
+	m.db.Where("group_id = ? AND message_id IN ?", mem.GroupID, ids).Order("created_at ASC").Find(&msgs)
+	if len(msgs) == len(ids) {
+		return msgs
+	}
+
+	found := make(map[string]bool, len(msgs))
+	for _, msg := range msgs {
+		found[msg.MessageID] = true
+	}
+	var archived []MessageArchive
+	m.db.Where("group_id = ? AND message_id IN ?", mem.GroupID, ids).Order("created_at ASC").Find(&archived)
+	for _, a := range archived {
+		if found[a.MessageID] {
+			continue
+		}
+		msgs = append(msgs, MessageLog{
+			CreatedAt: a.CreatedAt,
+			MessageID: a.MessageID,
+			GroupID:   a.GroupID,
+			UserID:    a.UserID,
+			Nickname:  a.Nickname,
+			Content:   a.Content,
+			MsgType:   a.MsgType,
+			Forwards:  a.Forwards,
+		})
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].CreatedAt.Before(msgs[j].CreatedAt) })
+	return msgs
+}
+
+// CountMessagesSince 统计某个时间之后的消息数
+func (m *Manager) CountMessagesSince(groupID int64, since time.Time) int64 {
+	var count int64
+	m.db.Model(&MessageLog{}).Where("group_id = ? AND created_at >= ?", groupID, since).Count(&count)
+	return count
+}
+
 // ==================== 长期记忆 ====================
 
+// personaCtxKey 人格上下文键
+type personaCtxKey struct{}
+
+// WithPersona 在 context 中标记当前人格，用于记忆的人格隔离
+func WithPersona(ctx context.Context, persona string) context.Context {
+	return context.WithValue(ctx, personaCtxKey{}, persona)
+}
+
+// personaFromContext 获取 context 中的人格，未开启人格隔离时返回 false
+func (m *Manager) personaFromContext(ctx context.Context) (string, bool) {
+	if !m.cfg.Memory.PersonaIsolation {
+		return "", false
+	}
+	persona, _ := ctx.Value(personaCtxKey{}).(string)
+	return persona, true
+}
+
 // SaveMemory 保存长期记忆
-func (m *Manager) SaveMemory(ctx context.Context, mem *Memory) error {
+// 写入前先用 embedding 查找语义相近的已有记忆，相似度超过阈值时合并到已有记忆（mem 会被替换为合并后的记录），返回 merged=true
+func (m *Manager) SaveMemory(ctx context.Context, mem *Memory) (bool, error) {
+	// 话题摘要和日记是按时间记录的，不做去重
+	dedup := mem.Type != MemoryTypeTopicSummary && !strings.HasPrefix(mem.Content, DiaryPrefix)
+	return m.saveMemory(ctx, mem, dedup)
+}
+
+// saveMemory 保存长期记忆，dedup 为 false 时跳过语义去重
+func (m *Manager) saveMemory(ctx context.Context, mem *Memory, dedup bool) (bool, error) {
+	if persona, ok := m.personaFromContext(ctx); ok && mem.Persona == "" {
+		mem.Persona = persona
+	}
+
 	// 生成 embedding
 	var embedding []float64
 	if m.embedding != nil {
@@ -140,28 +365,89 @@ func (m *Manager) SaveMemory(ctx context.Context, mem *Memory) error {
 		}
 	}
 
+	if dedup && mem.ID == 0 && m.vectors != nil && len(embedding) > 0 {
+		if existing := m.findDuplicateMemory(ctx, mem, embedding); existing != nil {
+			if err := m.mergeIntoMemory(ctx, existing, mem, embedding); err != nil {
+				return false, err
+			}
+			*mem = *existing
+			return true, nil
+		}
+	}
+
 	// 保存到 MySQL
 	if err := m.db.Save(mem).Error; err != nil {
-		return err
+		return false, err
 	}
 
-	// 保存向量到 Milvus
-	if m.milvus != nil && len(embedding) > 0 {
-		if _, err := m.milvus.Insert(ctx, mem.ID, mem.GroupID, string(mem.Type), embedding); err != nil {
+	// 保存向量
+	if m.vectors != nil && len(embedding) > 0 {
+		if err := m.vectors.Insert(ctx, mem.ID, mem.GroupID, string(mem.Type), embedding); err != nil {
 			// 向量插入失败只记录日志，不影响主流程
-			zap.L().Warn("Milvus 插入向量失败", zap.Error(err))
+			zap.L().Warn("插入向量失败", zap.Error(err))
+		}
+	}
+
+	m.enrichAsync(*mem)
+	m.enforceMemoryQuota(ctx, mem.GroupID, mem.ID)
+	return false, nil
+}
+
+// findDuplicateMemory 查找与新记忆语义重复的已有记忆（同群、同类型、同人格）
+func (m *Manager) findDuplicateMemory(ctx context.Context, mem *Memory, embedding []float64) *Memory {
+	threshold := m.cfg.Memory.LongTerm.DedupThreshold
+	if threshold <= 0 {
+		threshold = 0.92
+	}
+	results, err := m.vectors.Search(ctx, embedding, mem.GroupID, string(mem.Type), 3, threshold)
+	if err != nil {
+		zap.L().Debug("记忆去重检索失败", zap.Error(err))
+		return nil
+	}
+	// 结果按相似度从高到低，取第一条人格一致的记忆（向量存储中没有人格字段）
+	for _, r := range results {
+		var existing Memory
+		if err := m.db.First(&existing, r.MemoryID).Error; err != nil {
+			continue
+		}
+		if existing.Persona == mem.Persona {
+			return &existing
 		}
 	}
+	return nil
+}
 
+// mergeIntoMemory 把新记忆合并到已有记忆：以新内容为准，重要性取较大值，并更新向量
+func (m *Manager) mergeIntoMemory(ctx context.Context, existing, mem *Memory, embedding []float64) error {
+	existing.Content = mem.Content
+	existing.Summary, existing.Keywords = mem.Summary, mem.Keywords
+	existing.SourceMsgID = mem.SourceMsgID
+	existing.Importance = max(existing.Importance, mem.Importance)
+	existing.Archived = false
+	if existing.UserID != mem.UserID {
+		existing.UserID = 0
+	}
+	if err := m.db.Save(existing).Error; err != nil {
+		return err
+	}
+
+	if err := m.vectors.Delete(ctx, []uint{existing.ID}); err != nil {
+		zap.L().Warn("删除向量失败", zap.Error(err))
+	}
+	if err := m.vectors.Insert(ctx, existing.ID, existing.GroupID, string(existing.Type), embedding); err != nil {
+		zap.L().Warn("插入向量失败", zap.Error(err))
+	}
+	m.enrichAsync(*existing)
+	zap.L().Debug("新记忆与已有记忆重复，已合并", zap.Uint("id", existing.ID))
 	return nil
 }
 
 // QueryMemory 查询相关记忆
 func (m *Manager) QueryMemory(ctx context.Context, query string, groupID int64, memType MemoryType, limit int) ([]Memory, error) {
-	// 尝试 Milvus 向量搜索
-	if m.milvus != nil && m.embedding != nil {
+	// 尝试向量搜索
+	if m.vectors != nil && m.embedding != nil {
 		if emb, err := m.embedding.Embed(ctx, query); err == nil {
-			if results, err := m.milvusVectorSearch(ctx, emb, groupID, memType, limit); err == nil && len(results) > 0 {
+			if results, err := m.vectorSearch(ctx, emb, groupID, memType, limit); err == nil && len(results) > 0 {
 				return results, nil
 			}
 		}
@@ -169,10 +455,13 @@ func (m *Manager) QueryMemory(ctx context.Context, query string, groupID int64,
 
 	// 回退到关键词搜索
 	var memories []Memory
-	q := m.db.Model(&Memory{})
+	q := m.db.Model(&Memory{}).Where("archived = ?", false)
 	if groupID != 0 {
 		q = q.Where("group_id = ?", groupID)
 	}
+	if persona, ok := m.personaFromContext(ctx); ok {
+		q = q.Where("persona = ?", persona)
+	}
 	if memType != "" {
 		q = q.Where("type = ?", memType)
 	}
@@ -180,31 +469,303 @@ func (m *Manager) QueryMemory(ctx context.Context, query string, groupID int64,
 	if len(keywords) == 0 {
 		return memories, nil
 	}
-	likeConditions := make([]string, 0, len(keywords))
-	args := make([]interface{}, 0, len(keywords))
-	for _, kw := range keywords {
-		likeConditions = append(likeConditions, "content LIKE ?")
-		args = append(args, "%"+kw+"%")
+	q = q.Order("importance DESC, updated_at DESC").Limit(limit * rankCandidateFactor)
+	if err := m.findByKeywords(q, []string{"content", "keywords"}, keywords, &memories); err != nil {
+		return memories, err
 	}
-	err := q.Where(strings.Join(likeConditions, " OR "), args...).
-		Order("importance DESC, updated_at DESC").
+
+	// 关键词检索没有相似度，按重要性和新近度排序
+	memories = m.rankMemories(memories, nil, limit)
+	m.touchMemories(memories)
+	return memories, nil
+}
+
+// GetRecentDiaries 获取最近的日记
+func (m *Manager) GetRecentDiaries(groupID int64, limit int) ([]Memory, error) {
+	var diaries []Memory
+	err := m.db.Where("group_id = ? AND type = ? AND content LIKE ?", groupID, MemoryTypeSelfExperience, DiaryPrefix+"%").
+		Order("created_at DESC").
 		Limit(limit).
-		Find(&memories).Error
+		Find(&diaries).Error
+	return diaries, err
+}
+
+// ForgetMemories 删除群内包含关键词的长期记忆，返回删除条数
+func (m *Manager) ForgetMemories(ctx context.Context, groupID int64, keyword string) (int64, error) {
+	var ids []uint
+	if err := m.db.Model(&Memory{}).
+		Where("group_id = ?", groupID).Where(m.like("content"), "%"+keyword+"%").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := m.db.Where("id IN ?", ids).Delete(&Memory{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if m.vectors != nil {
+		if err := m.vectors.Delete(ctx, ids); err != nil {
+			zap.L().Warn("删除向量失败", zap.Error(err))
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+// MemoryEdit 记忆编辑内容，为 nil 的字段保持不变
+type MemoryEdit struct {
+	Content    *string     `json:"content,omitempty"`
+	Importance *float64    `json:"importance,omitempty"`
+	Type       *MemoryType `json:"type,omitempty"`
+}
+
+// EditMemory 编辑一条记忆，内容或类型变化时重新生成向量
+// groupID 不为 0 时只能编辑该群的记忆
+func (m *Manager) EditMemory(ctx context.Context, id uint, groupID int64, edit *MemoryEdit) (*Memory, error) {
+	var mem Memory
+	q := m.db.Where("id = ?", id)
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
+	}
+	if persona, ok := m.personaFromContext(ctx); ok {
+		q = q.Where("persona = ?", persona)
+	}
+	if err := q.First(&mem).Error; err != nil {
+		return nil, err
+	}
+
+	reembed, contentChanged := false, false
+	if edit.Content != nil && *edit.Content != mem.Content {
+		mem.Content = *edit.Content
+		// 旧的摘要和关键词已不准确，重新生成
+		mem.Summary, mem.Keywords = "", ""
+		reembed, contentChanged = true, true
+	}
+	if edit.Type != nil && *edit.Type != mem.Type {
+		mem.Type = *edit.Type
+		reembed = true
+	}
+	if edit.Importance != nil {
+		mem.Importance = *edit.Importance
+	}
+	if err := m.db.Save(&mem).Error; err != nil {
+		return nil, err
+	}
+	if contentChanged {
+		m.enrichAsync(mem)
+	}
+
+	if reembed && m.vectors != nil && m.embedding != nil {
+		if err := m.vectors.Delete(ctx, []uint{mem.ID}); err != nil {
+			zap.L().Warn("删除向量失败", zap.Error(err))
+		}
+		if emb, err := m.embedding.Embed(ctx, mem.Content); err != nil {
+			zap.L().Warn("生成向量失败", zap.Uint("id", mem.ID), zap.Error(err))
+		} else if err := m.vectors.Insert(ctx, mem.ID, mem.GroupID, string(mem.Type), emb); err != nil {
+			zap.L().Warn("插入向量失败", zap.Error(err))
+		}
+	}
+	return &mem, nil
+}
+
+// DeleteMemory 软删除一条记忆并移除向量，删除原因保留在记录上供审计
+// groupID 不为 0 时只能删除该群的记忆
+func (m *Manager) DeleteMemory(ctx context.Context, id uint, groupID int64, reason string) (*Memory, error) {
+	var mem Memory
+	q := m.db.Where("id = ?", id)
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
+	}
+	if persona, ok := m.personaFromContext(ctx); ok {
+		q = q.Where("persona = ?", persona)
+	}
+	if err := q.First(&mem).Error; err != nil {
+		return nil, err
+	}
+
+	mem.DeleteReason = truncateRunes(reason, 255)
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&mem).UpdateColumn("delete_reason", mem.DeleteReason).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&mem).Error
+	})
 	if err != nil {
-		return memories, err
+		return nil, err
+	}
+	if m.vectors != nil {
+		if err := m.vectors.Delete(ctx, []uint{mem.ID}); err != nil {
+			zap.L().Warn("删除向量失败", zap.Error(err))
+		}
+	}
+	zap.L().Named("audit").Info("删除记忆",
+		zap.Uint("id", mem.ID),
+		zap.Int64("group_id", mem.GroupID),
+		zap.String("content", mem.Content),
+		zap.String("reason", mem.DeleteReason))
+	return &mem, nil
+}
+
+// ListConsolidationCandidates 获取参与巩固的记忆（最近的 limit 条，不含日记）
+func (m *Manager) ListConsolidationCandidates(ctx context.Context, groupID int64, memType MemoryType, limit int) ([]Memory, error) {
+	var memories []Memory
+	q := m.db.Where("group_id = ? AND type = ? AND archived = ? AND content NOT LIKE ?", groupID, memType, false, DiaryPrefix+"%")
+	if persona, ok := m.personaFromContext(ctx); ok {
+		q = q.Where("persona = ?", persona)
+	}
+	err := q.Order("created_at DESC").Limit(limit).Find(&memories).Error
+	return memories, err
+}
+
+// ReplaceMemories 用合并后的记忆替换多条旧记忆：保存新记忆，软删除旧记录并移除其向量
+func (m *Manager) ReplaceMemories(ctx context.Context, ids []uint, merged *Memory) error {
+	// 合并后的记忆与被替换的旧记忆必然相似，不能再去重
+	if _, err := m.saveMemory(ctx, merged, false); err != nil {
+		return err
+	}
+	if err := m.db.Where("id IN ?", ids).Delete(&Memory{}).Error; err != nil {
+		return err
+	}
+	if m.vectors != nil {
+		if err := m.vectors.Delete(ctx, ids); err != nil {
+			zap.L().Warn("删除向量失败", zap.Error(err))
+		}
 	}
+	return nil
+}
+
+// startMemoryDecay 启动记忆衰减定时任务
+func (m *Manager) startMemoryDecay() {
+	intervalHours := m.cfg.Memory.Decay.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+	interval := time.Duration(intervalHours) * time.Hour
 
-	if len(memories) > 0 {
-		memoryIDs := make([]uint, 0, len(memories))
-		for _, mem := range memories {
-			memoryIDs = append(memoryIDs, mem.ID)
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.ApplyMemoryDecay(interval); err != nil {
+					zap.L().Warn("记忆衰减失败", zap.Error(err))
+				}
+			case <-m.cleanupStop:
+				ticker.Stop()
+				return
+			}
 		}
-		_ = m.db.Model(&Memory{}).Where("id IN ?", memoryIDs).Updates(map[string]any{
-			"access_count": gorm.Expr("access_count + 1"),
+	}()
+	zap.L().Info("记忆衰减任务已启动")
+}
+
+// ApplyMemoryDecay 按遗忘曲线降低长期未被访问的记忆的重要性，低于阈值时归档
+// 每次衰减 importance *= e^(-elapsed/S)，稳定度 S = stability_days * (1 + ln(1 + access_count))，
+// 被检索得越多的记忆忘得越慢
+func (m *Manager) ApplyMemoryDecay(elapsed time.Duration) error {
+	cfg := m.cfg.Memory.Decay
+	graceDays := cfg.GraceDays
+	if graceDays <= 0 {
+		graceDays = 7
+	}
+	stabilityDays := cfg.StabilityDays
+	if stabilityDays <= 0 {
+		stabilityDays = 30
+	}
+	archiveThreshold := cfg.ArchiveThreshold
+	if archiveThreshold <= 0 {
+		archiveThreshold = 0.2
+	}
+	protect := cfg.ProtectImportance
+	if protect <= 0 {
+		protect = 0.9
+	}
+
+	idleBefore := time.Now().AddDate(0, 0, -graceDays)
+	days := elapsed.Hours() / 24
+	decayed, archived := 0, 0
+
+	var batch []Memory
+	err := m.db.Select("id", "importance", "access_count").
+		Where("archived = ? AND importance < ?", false, protect).
+		Where("COALESCE(last_access_at, created_at) < ?", idleBefore).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, mem := range batch {
+				stability := stabilityDays * (1 + math.Log1p(float64(mem.AccessCount)))
+				importance := mem.Importance * math.Exp(-days/stability)
+				updates := map[string]any{"importance": importance}
+				if importance < archiveThreshold {
+					updates["archived"] = true
+					archived++
+				}
+				// 用 UpdateColumns 避免刷新 updated_at
+				if err := m.db.Model(&Memory{}).Where("id = ?", mem.ID).UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+				decayed++
+			}
+			return nil
 		}).Error
+	if err != nil {
+		return err
 	}
 
-	return memories, nil
+	if decayed > 0 {
+		zap.L().Info("记忆衰减完成", zap.Int("decayed", decayed), zap.Int("archived", archived))
+	}
+	return nil
+}
+
+// startMemoryTTL 启动记忆过期归档定时任务
+func (m *Manager) startMemoryTTL() {
+	intervalHours := m.cfg.Memory.TTL.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+
+	ticker := time.NewTicker(time.Duration(intervalHours) * time.Hour)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.ArchiveExpiredMemories(); err != nil {
+					zap.L().Warn("记忆过期归档失败", zap.Error(err))
+				}
+			case <-m.cleanupStop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	zap.L().Info("记忆过期归档任务已启动")
+}
+
+// ArchiveExpiredMemories 按类型的保留天数归档过期的记忆，归档后保留数据但不再参与检索
+func (m *Manager) ArchiveExpiredMemories() error {
+	days := m.cfg.Memory.TTL.Days
+	if len(days) == 0 {
+		days = map[string]int{string(MemoryTypeConversation): 90}
+	}
+
+	now := time.Now()
+	for memType, d := range days {
+		if d <= 0 {
+			continue // 永久保留
+		}
+		result := m.db.Model(&Memory{}).
+			Where("type = ? AND archived = ? AND created_at < ?", memType, false, now.AddDate(0, 0, -d)).
+			UpdateColumn("archived", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			zap.L().Info("已归档过期记忆", zap.String("type", memType), zap.Int64("count", result.RowsAffected))
+		}
+	}
+	return nil
 }
 
 // startMessageLogCleanup 启动消息日志清理定时任务
@@ -274,21 +835,30 @@ func (m *Manager) cleanupMessageLogs(keepLatest int) {
 			continue
 		}
 
-		result := m.db.Where("group_id = ? AND id NOT IN ?", groupID, keepIDs).Delete(&MessageLog{})
-		if result.Error != nil {
-			zap.L().Warn("清理消息日志失败：删除旧记录失败", zap.Int64("group_id", groupID), zap.Error(result.Error))
-			continue
+		var deleted int64
+		if m.cfg.Memory.MessageLogCleanup.Archive != "" {
+			var err error
+			if deleted, err = m.deleteMessageLogsWithArchive(groupID, keepIDs); err != nil {
+				zap.L().Warn("清理消息日志失败：归档旧记录失败", zap.Int64("group_id", groupID), zap.Error(err))
+				continue
+			}
+		} else {
+			result := m.db.Where("group_id = ? AND id NOT IN ?", groupID, keepIDs).Delete(&MessageLog{})
+			if result.Error != nil {
+				zap.L().Warn("清理消息日志失败：删除旧记录失败", zap.Int64("group_id", groupID), zap.Error(result.Error))
+				continue
+			}
+			deleted = result.RowsAffected
 		}
-		if result.RowsAffected > 0 {
-			zap.L().Info("消息日志已清理", zap.Int64("group_id", groupID), zap.Int("deleted", int(result.RowsAffected)))
+		if deleted > 0 {
+			zap.L().Info("消息日志已清理", zap.Int64("group_id", groupID), zap.Int("deleted", int(deleted)))
 		}
 	}
 }
 
-// milvusVectorSearch 使用 Milvus 进行向量搜索
-func (m *Manager) milvusVectorSearch(ctx context.Context, queryEmb []float64, groupID int64, memType MemoryType, limit int) ([]Memory, error) {
-	// 在 Milvus 中搜索
-	results, err := m.milvus.Search(ctx, queryEmb, groupID, string(memType), limit, m.cfg.Memory.LongTerm.SimilarityThreshold)
+// vectorSearch 使用向量存储进行语义搜索
+func (m *Manager) vectorSearch(ctx context.Context, queryEmb []float64, groupID int64, memType MemoryType, limit int) ([]Memory, error) {
+	results, err := m.vectors.Search(ctx, queryEmb, groupID, string(memType), limit*rankCandidateFactor, m.cfg.Memory.LongTerm.SimilarityThreshold)
 	if err != nil {
 		return nil, err
 	}
@@ -304,31 +874,313 @@ func (m *Manager) milvusVectorSearch(ctx context.Context, queryEmb []float64, gr
 	}
 
 	var memories []Memory
-	if err := m.db.Where("id IN ?", memoryIDs).Find(&memories).Error; err != nil {
+	q := m.db.Where("id IN ? AND archived = ?", memoryIDs, false)
+	if persona, ok := m.personaFromContext(ctx); ok {
+		// 向量存储中没有人格字段，在数据库侧过滤
+		q = q.Where("persona = ?", persona)
+	}
+	if err := q.Find(&memories).Error; err != nil {
 		return nil, err
 	}
 
-	// 更新访问计数
-	for _, mem := range memories {
-		m.db.Model(&mem).Updates(map[string]any{
-			"access_count": gorm.Expr("access_count + 1"),
-		})
+	scores := make(map[uint]float64, len(results))
+	for _, r := range results {
+		scores[r.MemoryID] = float64(r.Score)
+	}
+	memories = m.rankMemories(memories, scores, limit)
+	m.touchMemories(memories)
+	return memories, nil
+}
+
+// ==================== 话题摘要 ====================
+
+// SaveTopicSummary 保存话题摘要，同时写入一条 topic_summary 类型的长期记忆用于向量检索
+func (m *Manager) SaveTopicSummary(ctx context.Context, ts *TopicSummary) error {
+	content := fmt.Sprintf("话题「%s」（%s ~ %s）：%s", ts.Topic,
+		ts.StartTime.Format("2006-01-02 15:04"), ts.EndTime.Format("15:04"), ts.Summary)
+	if ts.Participants != "" {
+		content += "。参与者：" + ts.Participants
+	}
+	mem := &Memory{
+		Type:       MemoryTypeTopicSummary,
+		GroupID:    ts.GroupID,
+		Content:    content,
+		Importance: 0.5,
+	}
+	if _, err := m.SaveMemory(ctx, mem); err != nil {
+		return err
 	}
+	ts.MemoryID = mem.ID
+	return m.db.Create(ts).Error
+}
 
-	// 按照搜索结果的顺序排序
-	memoryMap := make(map[uint]Memory)
-	for _, mem := range memories {
-		memoryMap[mem.ID] = mem
+// ListTopicSummaries 分页列出话题摘要
+func (m *Manager) ListTopicSummaries(groupID int64, page, pageSize int) ([]TopicSummary, int64, error) {
+	var summaries []TopicSummary
+	var total int64
+	q := m.db.Model(&TopicSummary{})
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
 	}
+	q.Count(&total)
+	err := q.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&summaries).Error
+	return summaries, total, err
+}
 
-	sortedMemories := make([]Memory, 0, len(results))
-	for _, r := range results {
-		if mem, ok := memoryMap[r.MemoryID]; ok {
-			sortedMemories = append(sortedMemories, mem)
+// SearchTopicSummaries 按关键词和时间范围检索话题摘要，按时间倒序
+// 关键词按空白拆分，任意一个词命中话题、摘要、关键词或参与者即可；since/until 为零值时不限制
+func (m *Manager) SearchTopicSummaries(groupID int64, keyword string, since, until time.Time, limit int) ([]TopicSummary, error) {
+	var summaries []TopicSummary
+	q := m.db.Model(&TopicSummary{}).Where("group_id = ?", groupID)
+	if keywords := strings.Fields(keyword); len(keywords) > 0 {
+		conds := make([]string, 0, len(keywords)*4)
+		args := make([]any, 0, len(keywords)*4)
+		for _, kw := range keywords {
+			for _, column := range []string{"topic", "summary", "keywords", "participants"} {
+				conds = append(conds, m.like(column))
+				args = append(args, "%"+kw+"%")
+			}
 		}
+		q = q.Where(strings.Join(conds, " OR "), args...)
+	}
+	if !since.IsZero() {
+		q = q.Where("end_time >= ?", since)
+	}
+	if !until.IsZero() {
+		q = q.Where("start_time < ?", until)
+	}
+	err := q.Order("start_time DESC").Limit(limit).Find(&summaries).Error
+	return summaries, err
+}
+
+// ==================== 群信息 ====================
+
+// RecordDiscoveredGroup 记录收到过消息的未启用群（已存在时不做修改）
+func (m *Manager) RecordDiscoveredGroup(groupID int64) error {
+	m.cache.groups.del(groupID)
+	return m.db.Where(GroupInfo{GroupID: groupID}).FirstOrCreate(&GroupInfo{GroupID: groupID}).Error
+}
+
+// ListDiscoveredGroups 列出已发现但未启用的群
+func (m *Manager) ListDiscoveredGroups() ([]GroupInfo, error) {
+	var groups []GroupInfo
+	err := m.db.Where("enabled = ?", false).Order("created_at DESC").Find(&groups).Error
+	return groups, err
+}
+
+// ListEnabledGroupInfos 列出运行时启用的群（用于重启后恢复）
+func (m *Manager) ListEnabledGroupInfos() ([]GroupInfo, error) {
+	var groups []GroupInfo
+	err := m.db.Where("enabled = ?", true).Find(&groups).Error
+	return groups, err
+}
+
+// SaveGroupInfo 保存群信息（按群号更新或创建）
+func (m *Manager) SaveGroupInfo(info *GroupInfo) error {
+	var existing GroupInfo
+	if err := m.db.Where("group_id = ?", info.GroupID).First(&existing).Error; err == nil {
+		info.ID = existing.ID
+		info.CreatedAt = existing.CreatedAt
+	}
+	if err := m.db.Save(info).Error; err != nil {
+		m.cache.groups.del(info.GroupID)
+		return err
+	}
+	m.cache.groups.set(info.GroupID, *info)
+	return nil
+}
+
+// GetGroupInfo 获取群信息
+func (m *Manager) GetGroupInfo(groupID int64) (*GroupInfo, error) {
+	if info, ok := m.cache.groups.get(groupID); ok {
+		return &info, nil
+	}
+	var info GroupInfo
+	if err := m.db.Where("group_id = ?", groupID).First(&info).Error; err != nil {
+		return nil, err
+	}
+	m.cache.groups.set(groupID, info)
+	return &info, nil
+}
+
+// ==================== 小游戏 ====================
+
+// SaveGameSession 保存小游戏会话
+func (m *Manager) SaveGameSession(gs *GameSession) error {
+	return m.db.Save(gs).Error
+}
+
+// ListActiveGameSessions 列出进行中的小游戏会话
+func (m *Manager) ListActiveGameSessions() ([]GameSession, error) {
+	var sessions []GameSession
+	err := m.db.Where("active = ?", true).Find(&sessions).Error
+	return sessions, err
+}
+
+// RecordGamePlay 记录成员参与小游戏
+func (m *Manager) RecordGamePlay(userID int64, won bool) error {
+	updates := map[string]interface{}{"games_played": gorm.Expr("games_played + 1")}
+	if won {
+		updates["games_won"] = gorm.Expr("games_won + 1")
+	}
+	m.cache.users.del(userID)
+	return m.db.Model(&GlobalUserProfile{}).Where("user_id = ?", userID).Updates(updates).Error
+}
+
+// ==================== 决策记录 ====================
+
+// SaveDecisionLog 保存一次思考决策记录
+func (m *Manager) SaveDecisionLog(log *DecisionLog) error {
+	return m.db.Create(log).Error
+}
+
+// ListDecisionLogs 分页列出决策记录，groupID 为 0 时不筛选群，action 为空时不筛选动作
+func (m *Manager) ListDecisionLogs(groupID int64, action string, page, pageSize int) ([]DecisionLog, int64, error) {
+	var logs []DecisionLog
+	var total int64
+	q := m.db.Model(&DecisionLog{})
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
+	}
+	if action != "" {
+		q = q.Where("action = ?", action)
+	}
+	q.Count(&total)
+	err := q.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
+
+// CleanupDecisionLogs 删除指定时间之前的决策记录
+func (m *Manager) CleanupDecisionLogs(before time.Time) (int64, error) {
+	res := m.db.Where("created_at < ?", before).Delete(&DecisionLog{})
+	return res.RowsAffected, res.Error
+}
+
+// ==================== 工具调用记录 ====================
+
+// SaveToolCallLog 保存一次工具调用记录
+func (m *Manager) SaveToolCallLog(log *ToolCallLog) error {
+	return m.db.Create(log).Error
+}
+
+// ListToolCallLogs 分页列出工具调用记录，groupID 为 0、toolName 为空、success 为 nil 时不按对应条件筛选
+func (m *Manager) ListToolCallLogs(groupID int64, toolName string, success *bool, page, pageSize int) ([]ToolCallLog, int64, error) {
+	var logs []ToolCallLog
+	var total int64
+	q := m.db.Model(&ToolCallLog{})
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
 	}
+	if toolName != "" {
+		q = q.Where("tool = ?", toolName)
+	}
+	if success != nil {
+		q = q.Where("success = ?", *success)
+	}
+	q.Count(&total)
+	err := q.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
 
-	return sortedMemories, nil
+// CleanupToolCallLogs 删除指定时间之前的工具调用记录
+func (m *Manager) CleanupToolCallLogs(before time.Time) (int64, error) {
+	res := m.db.Where("created_at < ?", before).Delete(&ToolCallLog{})
+	return res.RowsAffected, res.Error
+}
+
+// ==================== 定时提醒 ====================
+
+// CreateReminder 创建定时提醒
+func (m *Manager) CreateReminder(r *Reminder) error {
+	return m.db.Create(r).Error
+}
+
+// GetDueReminders 获取已到时间且未触发的提醒
+func (m *Manager) GetDueReminders(now time.Time) ([]Reminder, error) {
+	var reminders []Reminder
+	err := m.db.Where("done = ? AND remind_at <= ?", false, now).
+		Order("remind_at ASC").Find(&reminders).Error
+	return reminders, err
+}
+
+// MarkReminderDone 标记提醒已触发
+func (m *Manager) MarkReminderDone(id uint) error {
+	return m.db.Model(&Reminder{}).Where("id = ?", id).Update("done", true).Error
+}
+
+// GetPendingReminders 获取群内还没触发的提醒，userID 不为 0 时只查提醒该群友的
+func (m *Manager) GetPendingReminders(groupID, userID int64, limit int) ([]Reminder, error) {
+	var reminders []Reminder
+	query := m.db.Where("group_id = ? AND done = ?", groupID, false)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("remind_at ASC").Limit(limit).Find(&reminders).Error
+	return reminders, err
+}
+
+// CancelReminder 取消群内一条还没触发的提醒，返回是否找到
+func (m *Manager) CancelReminder(groupID int64, id uint) (bool, error) {
+	res := m.db.Where("id = ? AND group_id = ? AND done = ?", id, groupID, false).Delete(&Reminder{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ==================== 群投票 ====================
+
+// CreatePoll 创建投票
+func (m *Manager) CreatePoll(p *Poll) error {
+	return m.db.Create(p).Error
+}
+
+// GetPoll 获取群内的一个投票
+func (m *Manager) GetPoll(groupID int64, id uint) (*Poll, error) {
+	var p Poll
+	if err := m.db.Where("id = ? AND group_id = ?", id, groupID).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetOpenPolls 获取群内进行中的投票，按创建时间倒序
+func (m *Manager) GetOpenPolls(groupID int64) ([]Poll, error) {
+	var polls []Poll
+	err := m.db.Where("group_id = ? AND closed = ?", groupID, false).Order("id DESC").Find(&polls).Error
+	return polls, err
+}
+
+// GetDuePolls 获取已到截止时间但还没结束的投票
+func (m *Manager) GetDuePolls(now time.Time) ([]Poll, error) {
+	var polls []Poll
+	err := m.db.Where("closed = ? AND close_at <= ?", false, now).Order("close_at ASC").Find(&polls).Error
+	return polls, err
+}
+
+// ClosePoll 结束投票并记录结果，返回是否由本次调用结束（已结束的返回 false）
+func (m *Manager) ClosePoll(id uint, result string) (bool, error) {
+	res := m.db.Model(&Poll{}).Where("id = ? AND closed = ?", id, false).
+		Updates(map[string]any{"closed": true, "result": result})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ==================== Token 用量 ====================
+
+// RecordTokenUsage 记录一次 token 用量
+func (m *Manager) RecordTokenUsage(usage *TokenUsage) error {
+	if usage.Date == "" {
+		usage.Date = time.Now().Format(time.DateOnly)
+	}
+	return m.db.Create(usage).Error
+}
+
+// GetDailyTokenUsage 获取某天的 token 总用量，groupID 为 0 时统计所有群
+func (m *Manager) GetDailyTokenUsage(date string, groupID int64) (int64, error) {
+	var total int64
+	q := m.db.Model(&TokenUsage{}).Where("date = ?", date)
+	if groupID != 0 {
+		q = q.Where("group_id = ?", groupID)
+	}
+	err := q.Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error
+	return total, err
 }
 
 // ==================== 表达学习 ====================
@@ -338,6 +1190,7 @@ func (m *Manager) SaveExpression(exp *Expression) (bool, error) {
 	if exp == nil {
 		return false, nil
 	}
+	defer m.cache.invalidateExpressions(exp.GroupID)
 
 	if exp.GroupID != 0 && exp.Situation != "" && exp.Style != "" {
 		var existing Expression
@@ -367,24 +1220,25 @@ func (m *Manager) SaveExpression(exp *Expression) (bool, error) {
 
 // SearchExpressions 搜索表达方式（关键词匹配）
 func (m *Manager) SearchExpressions(groupID int64, keyword string, limit int) ([]Expression, error) {
+	key := expressionQuery{groupID: groupID, keyword: keyword, limit: limit}
+	if cached, ok := m.cache.expressions.get(key); ok {
+		return slices.Clone(cached), nil
+	}
+
 	var expressions []Expression
 	q := m.db.Model(&Expression{}).
 		Where("group_id = ? AND rejected = ?", groupID, false)
 
-	if keyword != "" {
-		keywords := strings.Fields(keyword)
-		if len(keywords) > 0 {
-			likeConditions := make([]string, 0, len(keywords))
-			args := make([]interface{}, 0, len(keywords))
-			for _, kw := range keywords {
-				likeConditions = append(likeConditions, "situation LIKE ? OR style LIKE ? OR examples LIKE ?")
-				args = append(args, "%"+kw+"%", "%"+kw+"%", "%"+kw+"%")
-			}
-			q = q.Where(strings.Join(likeConditions, " OR "), args...)
-		}
+	q = q.Order("checked DESC, updated_at DESC").Limit(limit)
+	var err error
+	if keywords := strings.Fields(keyword); len(keywords) > 0 {
+		err = m.findByKeywords(q, []string{"situation", "style", "examples"}, keywords, &expressions)
+	} else {
+		err = q.Find(&expressions).Error
+	}
+	if err == nil {
+		m.cache.expressions.set(key, slices.Clone(expressions))
 	}
-
-	err := q.Order("checked DESC, updated_at DESC").Limit(limit).Find(&expressions).Error
 	return expressions, err
 }
 
@@ -398,6 +1252,8 @@ func (m *Manager) ReviewExpression(id uint, approve bool) error {
 	} else {
 		updates["rejected"] = true
 	}
+	// 不知道所属的群，清空全部表达方式检索缓存
+	m.cache.expressions.delWhere(func(expressionQuery) bool { return true })
 	return m.db.Model(&Expression{}).Where("id = ?", id).Updates(updates).Error
 }
 
@@ -411,29 +1267,55 @@ func (m *Manager) GetUncheckedExpressions(groupID int64, limit int) ([]Expressio
 
 // ==================== 黑话管理 ====================
 
-// SearchJargons 搜索黑话（通过关键词匹配，本群优先）
-func (m *Manager) SearchJargons(groupID int64, keyword string, limit int) ([]Jargon, error) {
+// SearchJargons 搜索黑话（语义检索和关键词匹配结合，本群优先）
+func (m *Manager) SearchJargons(ctx context.Context, groupID int64, keyword string, limit int) ([]Jargon, error) {
 	var jargons []Jargon
-	q := m.db.Model(&Jargon{})
+	q := m.db.Model(&Jargon{}).Omit("embedding")
+
+	// 本群优先排序：本群的排在前面，然后按 verified 降序，再按热度（长期没人用的排在后面）
+	staleBefore := time.Now().AddDate(0, 0, -jargonStaleDays)
+	q = q.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "CASE WHEN group_id = ? THEN 0 ELSE 1 END, verified DESC, CASE WHEN last_used_at > ? THEN 0 ELSE 1 END, count DESC",
+		Vars:               []any{groupID, staleBefore},
+		WithoutParentheses: true,
+	}}).Limit(limit)
+
+	// 使用 strings.Fields 切割关键词，任一匹配即可
+	keywords := strings.Fields(keyword)
+	if len(keywords) == 0 {
+		err := q.Find(&jargons).Error
+		return jargons, err
+	}
+	if err := m.findByKeywords(q, []string{"content"}, keywords, &jargons); err != nil {
+		return jargons, err
+	}
 
-	// 使用 strings.Fields 切割关键词，挨个模糊匹配
-	if keyword != "" {
-		keywords := strings.Fields(keyword)
-		if len(keywords) > 0 {
-			likeConditions := make([]string, 0, len(keywords))
-			args := make([]interface{}, 0, len(keywords))
-			for _, kw := range keywords {
-				likeConditions = append(likeConditions, "content LIKE ?")
-				args = append(args, "%"+kw+"%")
-			}
-			q = q.Where(strings.Join(likeConditions, " OR "), args...)
+	// 语义相近的黑话排在前面，再补上关键词匹配到的
+	threshold := m.cfg.Memory.LongTerm.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = 0.7
+	}
+	ids := m.searchJargonVectors(ctx, keyword, groupID, limit, threshold)
+	if len(ids) == 0 {
+		return jargons, nil
+	}
+	semantic, err := m.findJargonsByIDs(ids)
+	if err != nil {
+		return jargons, nil
+	}
+	seen := make(map[uint]bool, len(semantic))
+	for _, j := range semantic {
+		seen[j.ID] = true
+	}
+	for _, j := range jargons {
+		if len(semantic) >= limit {
+			break
+		}
+		if !seen[j.ID] {
+			semantic = append(semantic, j)
 		}
 	}
-
-	// 本群优先排序：本群的排在前面，然后按 verified 降序
-	err := q.Order(fmt.Sprintf("CASE WHEN group_id = %d THEN 0 ELSE 1 END, verified DESC", groupID)).
-		Limit(limit).Find(&jargons).Error
-	return jargons, err
+	return semantic, nil
 }
 
 // SaveJargon 保存黑话/术语
@@ -442,7 +1324,13 @@ func (m *Manager) SaveJargon(jargon *Jargon) error {
 	err := m.db.Where("group_id = ? AND content = ?", jargon.GroupID, jargon.Content).First(&existing).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return m.db.Create(jargon).Error
+		jargon.Embedding = nil
+		if err := m.db.Create(jargon).Error; err != nil {
+			return err
+		}
+		m.invalidateJargonCache(jargon.GroupID)
+		m.embedJargonAsync(*jargon)
+		return nil
 	} else if err != nil {
 		return err
 	}
@@ -451,7 +1339,14 @@ func (m *Manager) SaveJargon(jargon *Jargon) error {
 		"meaning": jargon.Meaning,
 		"context": jargon.Context,
 	}
-	return m.db.Model(&existing).Updates(updates).Error
+	if err := m.db.Model(&existing).Updates(updates).Error; err != nil {
+		return err
+	}
+	if existing.Meaning != jargon.Meaning {
+		existing.Meaning = jargon.Meaning
+		m.embedJargonAsync(existing)
+	}
+	return nil
 }
 
 // ReviewJargon 审核黑话
@@ -470,58 +1365,244 @@ func (m *Manager) GetUnverifiedJargons(groupID int64, limit int) ([]Jargon, erro
 	return jargons, err
 }
 
+// ListJargons 分页列出群内黑话，verified 为 nil 时不按验证状态过滤，按出现次数倒序
+func (m *Manager) ListJargons(groupID int64, verified *bool, page, pageSize int) ([]Jargon, int64, error) {
+	var items []Jargon
+	var total int64
+
+	q := m.db.Model(&Jargon{}).Where("group_id = ?", groupID)
+	if verified != nil {
+		q = q.Where("verified = ?", *verified)
+	}
+	q.Count(&total)
+
+	err := q.Order("count DESC, id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error
+	return items, total, err
+}
+
 // ==================== 成员画像 ====================
 
-// GetMemberProfile 获取成员画像
-func (m *Manager) GetMemberProfile(userID int64) (*MemberProfile, error) {
-	var profile MemberProfile
+// GetUserProfile 获取全局用户档案
+func (m *Manager) GetUserProfile(userID int64) (*GlobalUserProfile, error) {
+	if profile, ok := m.cache.users.get(userID); ok {
+		return &profile, nil
+	}
+	var profile GlobalUserProfile
 	err := m.db.Where("user_id = ?", userID).First(&profile).Error
 	if err != nil {
 		return nil, err
 	}
+	m.cache.users.set(userID, profile)
 	return &profile, nil
 }
 
-// GetOrCreateMemberProfile 获取或创建成员画像
-func (m *Manager) GetOrCreateMemberProfile(userID int64, nickname string) (*MemberProfile, error) {
-	var profile MemberProfile
+// GetOrCreateUserProfile 获取或创建全局用户档案
+func (m *Manager) GetOrCreateUserProfile(userID int64, nickname string) (*GlobalUserProfile, error) {
+	if profile, ok := m.cache.users.get(userID); ok {
+		return &profile, nil
+	}
+	var profile GlobalUserProfile
 	err := m.db.Where("user_id = ?", userID).First(&profile).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		profile = MemberProfile{
+		profile = GlobalUserProfile{
+			UserID:   userID,
+			Nickname: nickname,
+			Intimacy: 0.3, // 初始亲密度
+		}
+		if err := m.db.Create(&profile).Error; err != nil {
+			return nil, err
+		}
+		m.cache.users.set(userID, profile)
+		return &profile, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.cache.users.set(userID, profile)
+	return &profile, nil
+}
+
+// UpdateUserProfile 只更新全局用户档案的指定列（列名）
+// 档案可能来自缓存，整行保存会覆盖互动次数、亲密度、发言统计等并发的原子更新
+func (m *Manager) UpdateUserProfile(profile *GlobalUserProfile, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	err := m.db.Model(profile).Select(columns).Updates(profile).Error
+	m.cache.users.del(profile.UserID)
+	return err
+}
+
+// GetMemberProfile 获取群内成员画像
+func (m *Manager) GetMemberProfile(groupID, userID int64) (*MemberProfile, error) {
+	key := memberKey{groupID: groupID, userID: userID}
+	if profile, ok := m.cache.members.get(key); ok {
+		return &profile, nil
+	}
+	var profile MemberProfile
+	err := m.db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	m.cache.members.set(key, profile)
+	return &profile, nil
+}
+
+// FindMembersByName 按昵称模糊查找群内成员，匹配本群群名片和全局昵称（仅限在本群出现过的人）
+func (m *Manager) FindMembersByName(groupID int64, name string, limit int) ([]MemberProfile, error) {
+	var members []MemberProfile
+	if err := m.db.Where("group_id = ?", groupID).Where(m.like("nickname"), "%"+name+"%").
+		Order("last_speak DESC").Limit(limit).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	var globals []GlobalUserProfile
+	if err := m.db.Where(m.like("nickname"), "%"+name+"%").
+		Where("user_id IN (?)", m.db.Model(&MemberProfile{}).Select("user_id").Where("group_id = ?", groupID)).
+		Order("last_speak DESC").Limit(limit).Find(&globals).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(members))
+	for _, mp := range members {
+		seen[mp.UserID] = true
+	}
+	for _, g := range globals {
+		if !seen[g.UserID] && len(members) < limit {
+			members = append(members, MemberProfile{GroupID: groupID, UserID: g.UserID, Nickname: g.Nickname, LastSpeak: g.LastSpeak})
+		}
+	}
+	return members, nil
+}
+
+// GetOrCreateMemberProfile 获取或创建群内成员画像，同时确保全局档案存在
+func (m *Manager) GetOrCreateMemberProfile(groupID, userID int64, nickname string) (*MemberProfile, error) {
+	if _, err := m.GetOrCreateUserProfile(userID, nickname); err != nil {
+		return nil, err
+	}
+
+	profile, err := m.GetMemberProfile(groupID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &MemberProfile{
+			GroupID:   groupID,
 			UserID:    userID,
 			Nickname:  nickname,
 			Activity:  0.5, // 初始活跃度
-			Intimacy:  0.3, // 初始亲密度
 			LastSpeak: time.Now(),
 		}
-		if err := m.db.Create(&profile).Error; err != nil {
+		if err := m.db.Create(profile).Error; err != nil {
 			return nil, err
 		}
-		return &profile, nil
+		m.cache.members.set(memberKey{groupID: groupID, userID: userID}, *profile)
+		return profile, nil
 	}
-	return &profile, err
+	return profile, err
 }
 
-// UpdateMemberProfile 更新成员画像
-func (m *Manager) UpdateMemberProfile(profile *MemberProfile) error {
-	// 计算活跃度：基于最近发言时间和消息数量
+// UpdateMemberProfile 只更新群内成员画像的指定列（列名），不会覆盖发言统计
+func (m *Manager) UpdateMemberProfile(profile *MemberProfile, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	err := m.db.Model(profile).Select(columns).Updates(profile).Error
+	m.cache.members.del(memberKey{groupID: profile.GroupID, userID: profile.UserID})
+	return err
+}
+
+// memberActivity 计算成员发言后的活跃度：基于最近发言时间和消息数量
+func memberActivity(activity float64, lastSpeak time.Time) float64 {
 	// 活跃度衰减：每天降低0.1，最低0.1
-	daysSinceLastSpeak := time.Since(profile.LastSpeak).Hours() / 24
+	daysSinceLastSpeak := time.Since(lastSpeak).Hours() / 24
 	if daysSinceLastSpeak > 0 {
-		profile.Activity -= 0.1 * daysSinceLastSpeak
-		if profile.Activity < 0.1 {
-			profile.Activity = 0.1
+		activity -= 0.1 * daysSinceLastSpeak
+		if activity < 0.1 {
+			activity = 0.1
 		}
 	}
 	// 发言增加活跃度
-	if time.Since(profile.LastSpeak) < time.Hour {
-		profile.Activity += 0.05
-		if profile.Activity > 1.0 {
-			profile.Activity = 1.0
+	if time.Since(lastSpeak) < time.Hour {
+		activity += 0.05
+		if activity > 1.0 {
+			activity = 1.0
 		}
 	}
-	return m.db.Save(profile).Error
+	return activity
+}
+
+// RecordMemberMessage 记录成员发言：更新群内画像和全局档案的发言统计
+func (m *Manager) RecordMemberMessage(groupID, userID int64, nickname string, t time.Time) error {
+	mp, err := m.GetOrCreateMemberProfile(groupID, userID, nickname)
+	if err != nil {
+		return err
+	}
+	activity := memberActivity(mp.Activity, t)
+	key := memberKey{groupID: groupID, userID: userID}
+	if err := m.db.Model(&MemberProfile{}).Where("id = ?", mp.ID).Updates(map[string]any{
+		"msg_count":  gorm.Expr("msg_count + 1"),
+		"last_speak": t,
+		"nickname":   nickname,
+		"activity":   activity,
+	}).Error; err != nil {
+		m.cache.members.del(key)
+		return err
+	}
+	m.cache.members.update(key, func(p *MemberProfile) {
+		p.MsgCount++
+		p.LastSpeak = t
+		p.Nickname = nickname
+		p.Activity = activity
+	})
+
+	if err := m.db.Model(&GlobalUserProfile{}).Where("user_id = ?", userID).Updates(map[string]any{
+		"msg_count":  gorm.Expr("msg_count + 1"),
+		"last_speak": t,
+		"nickname":   nickname,
+	}).Error; err != nil {
+		m.cache.users.del(userID)
+		return err
+	}
+	m.cache.users.update(userID, func(p *GlobalUserProfile) {
+		p.MsgCount++
+		p.LastSpeak = t
+		p.Nickname = nickname
+	})
+	return nil
+}
+
+// GetInactiveIntimates 获取亲密度高但很久没发言、且近期没有被主动关心过的用户
+func (m *Manager) GetInactiveIntimates(minIntimacy float64, inactiveSince, caredBefore time.Time, limit int) ([]GlobalUserProfile, error) {
+	var profiles []GlobalUserProfile
+	err := m.db.Where("intimacy >= ? AND last_speak < ? AND last_speak > ?", minIntimacy, inactiveSince, time.Time{}).
+		Where("last_cared_at IS NULL OR last_cared_at < ?", caredBefore).
+		Order("intimacy DESC").Limit(limit).Find(&profiles).Error
+	return profiles, err
+}
+
+// MarkMemberCared 记录主动私聊关心的时间
+func (m *Manager) MarkMemberCared(userID int64, t time.Time) error {
+	err := m.db.Model(&GlobalUserProfile{}).Where("user_id = ?", userID).Update("last_cared_at", t).Error
+	m.cache.users.del(userID)
+	return err
+}
+
+// GetUserMemories 获取与某个成员相关的记忆，按重要性排序
+func (m *Manager) GetUserMemories(ctx context.Context, userID int64, limit int) ([]Memory, error) {
+	var mems []Memory
+	q := m.db.Where("user_id = ? AND archived = ?", userID, false)
+	if persona, ok := m.personaFromContext(ctx); ok {
+		q = q.Where("persona = ?", persona)
+	}
+	err := q.Order("importance DESC, created_at DESC").Limit(limit).Find(&mems).Error
+	return mems, err
+}
+
+// GetBirthdayMembers 获取群内生日为指定日期（MM-DD）的成员
+func (m *Manager) GetBirthdayMembers(groupID int64, date string) ([]GlobalUserProfile, error) {
+	var profiles []GlobalUserProfile
+	err := m.db.Where("birthday = ?", date).
+		Where("user_id IN (?)", m.db.Model(&MemberProfile{}).Select("user_id").Where("group_id = ?", groupID)).
+		Find(&profiles).Error
+	return profiles, err
 }
 
 // ==================== 统计 ====================
@@ -531,7 +1612,7 @@ func (m *Manager) GetStats() map[string]int64 {
 	stats := make(map[string]int64)
 	var memories, members, messages, expressions, jargons int64
 	m.db.Model(&Memory{}).Count(&memories)
-	m.db.Model(&MemberProfile{}).Count(&members)
+	m.db.Model(&GlobalUserProfile{}).Count(&members)
 	m.db.Model(&MessageLog{}).Count(&messages)
 	m.db.Model(&Expression{}).Count(&expressions)
 	m.db.Model(&Jargon{}).Count(&jargons)
@@ -590,8 +1671,20 @@ func (m *Manager) ListMessageLogs(groupID int64, page, pageSize int) ([]MessageL
 	return items, total, err
 }
 
-// GetMessageLogByID 根据消息ID获取消息日志
+// GetMessageLogByID 根据消息ID获取消息日志（包括还在缓冲区等待写入的）
 func (m *Manager) GetMessageLogByID(messageID string) (*MessageLog, error) {
+	m.msgBufMu.Lock()
+	for i := len(m.msgBuf) - 1; i >= 0; i-- {
+		if m.msgBuf[i].MessageID == messageID {
+			log := m.msgBuf[i]
+			m.msgBufMu.Unlock()
+			return &log, nil
+		}
+	}
+	m.msgBufMu.Unlock()
+
+	// 缓冲区里没有时可能正在落盘，等这一批写完再查
+	m.flushMessages()
 	var log MessageLog
 	err := m.db.Where("message_id = ?", messageID).First(&log).Error
 	if err != nil {
@@ -607,9 +1700,15 @@ func (m *Manager) Close() error {
 		close(m.cleanupStop)
 		m.cleanupStop = nil
 	}
-	// 关闭 Milvus 连接
-	if m.milvus != nil {
-		_ = m.milvus.Close()
+	// 等待缓冲的消息日志落盘
+	if m.msgFlushDone != nil {
+		<-m.msgFlushDone
+	}
+	// 等待正在生成的记忆摘要写回
+	m.enrichWG.Wait()
+	// 关闭向量存储连接
+	if m.vectors != nil {
+		_ = m.vectors.Close()
 	}
 	// 关闭 MySQL 连接
 	if sqlDB, err := m.db.DB(); err == nil {
@@ -622,11 +1721,22 @@ func (m *Manager) GetDB() *gorm.DB { return m.db }
 
 // ==================== 表情包管理 ====================
 
+// 表情包检索范围
+const (
+	StickerScopeGlobal     = "global"      // 全局共享
+	StickerScopeGroupFirst = "group_first" // 本群收集的优先
+	StickerScopeGroupOnly  = "group_only"  // 仅本群收集的
+)
+
 // SaveSticker 保存表情包（通过哈希去重）
 func (m *Manager) SaveSticker(sticker *Sticker) (bool, error) {
-	// 先检查哈希是否已存在
+	// 先检查哈希是否已存在，按群隔离时每个群各自收藏
 	var existing Sticker
-	err := m.db.Where("file_hash = ?", sticker.FileHash).First(&existing).Error
+	q := m.db.Where("file_hash = ?", sticker.FileHash)
+	if m.cfg.Sticker.Scope == StickerScopeGroupFirst || m.cfg.Sticker.Scope == StickerScopeGroupOnly {
+		q = q.Where("group_id = ?", sticker.GroupID)
+	}
+	err := q.First(&existing).Error
 	if err == nil {
 		// 已存在，返回重复标记
 		return true, nil
@@ -652,21 +1762,45 @@ func (m *Manager) GetStickerByID(id uint) (*Sticker, error) {
 	return &sticker, nil
 }
 
-// SearchStickers 搜索表情包
-func (m *Manager) SearchStickers(keyword string, limit int) ([]Sticker, error) {
+// SearchStickers 搜索表情包，关键词匹配描述、主体和图上文字，filter 按标签过滤
+func (m *Manager) SearchStickers(keyword string, filter StickerFilter, limit int) ([]Sticker, error) {
 	var stickers []Sticker
 	q := m.db.Model(&Sticker{})
 	if keyword != "" {
 		keywords := strings.Fields(keyword)
-		likeConditions := make([]string, 0, len(keywords))
-		args := make([]interface{}, 0, len(keywords))
+		likeConditions := make([]string, 0, len(keywords)*3)
+		args := make([]interface{}, 0, len(keywords)*3)
 		for _, kw := range keywords {
-			likeConditions = append(likeConditions, "description LIKE ?")
-			args = append(args, "%"+kw+"%")
+			for _, column := range []string{"description", "subject", "text"} {
+				likeConditions = append(likeConditions, m.like(column))
+				args = append(args, "%"+kw+"%")
+			}
 		}
 		q = q.Where(strings.Join(likeConditions, " OR "), args...)
 	}
-	err := q.Order("use_count DESC, updated_at DESC").Limit(limit).Find(&stickers).Error
+	if filter.Emotion != "" {
+		q = q.Where("emotion = ?", filter.Emotion)
+	}
+	if filter.Subject != "" {
+		q = q.Where(m.like("subject"), "%"+filter.Subject+"%")
+	}
+	if filter.HasText != nil {
+		q = q.Where("has_text = ?", *filter.HasText)
+	}
+
+	switch m.cfg.Sticker.Scope {
+	case StickerScopeGroupOnly:
+		q = q.Where("group_id IN ?", []int64{filter.GroupID, 0}).Order("use_count DESC, updated_at DESC")
+	case StickerScopeGroupFirst:
+		q = q.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN group_id = ? THEN 0 ELSE 1 END, use_count DESC, updated_at DESC",
+			Vars:               []any{filter.GroupID},
+			WithoutParentheses: true,
+		}})
+	default:
+		q = q.Order("use_count DESC, updated_at DESC")
+	}
+	err := q.Limit(limit).Find(&stickers).Error
 	return stickers, err
 }
 
@@ -710,6 +1844,9 @@ func (m *Manager) startMoodDecay() {
 
 // GetMoodState 获取当前情绪状态
 func (m *Manager) GetMoodState() (*MoodState, error) {
+	if mood, ok := m.cache.mood.get(struct{}{}); ok {
+		return &mood, nil
+	}
 	var mood MoodState
 	err := m.db.First(&mood).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -722,14 +1859,39 @@ func (m *Manager) GetMoodState() (*MoodState, error) {
 		if err := m.db.Create(&mood).Error; err != nil {
 			return nil, err
 		}
+		m.cache.mood.set(struct{}{}, mood)
 		return &mood, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	m.cache.mood.set(struct{}{}, mood)
 	return &mood, nil
 }
 
+// MoodChange 一次主动情绪变化
+type MoodChange struct {
+	Time             time.Time `json:"time"`
+	ValenceDelta     float64   `json:"valence_delta"`
+	EnergyDelta      float64   `json:"energy_delta"`
+	SociabilityDelta float64   `json:"sociability_delta"`
+	Reason           string    `json:"reason,omitempty"`
+}
+
+// maxMoodChanges 保留的最近情绪变化条数
+const maxMoodChanges = 10
+
+// GetRecentMoodChanges 获取最近的情绪变化，按时间倒序（只保存在内存中，重启后清空）
+func (m *Manager) GetRecentMoodChanges() []MoodChange {
+	m.moodChangesMu.Lock()
+	defer m.moodChangesMu.Unlock()
+	changes := make([]MoodChange, 0, len(m.moodChanges))
+	for i := len(m.moodChanges) - 1; i >= 0; i-- {
+		changes = append(changes, m.moodChanges[i])
+	}
+	return changes
+}
+
 // UpdateMoodState 更新情绪状态（增量更新）
 func (m *Manager) UpdateMoodState(valenceDelta, energyDelta, sociabilityDelta float64, reason string) (*MoodState, error) {
 	mood, err := m.GetMoodState()
@@ -744,8 +1906,23 @@ func (m *Manager) UpdateMoodState(valenceDelta, energyDelta, sociabilityDelta fl
 	mood.LastReason = reason
 
 	if err := m.db.Save(mood).Error; err != nil {
+		m.cache.mood.del(struct{}{})
 		return nil, err
 	}
+	m.cache.mood.set(struct{}{}, *mood)
+
+	m.moodChangesMu.Lock()
+	m.moodChanges = append(m.moodChanges, MoodChange{
+		Time:             time.Now(),
+		ValenceDelta:     valenceDelta,
+		EnergyDelta:      energyDelta,
+		SociabilityDelta: sociabilityDelta,
+		Reason:           reason,
+	})
+	if len(m.moodChanges) > maxMoodChanges {
+		m.moodChanges = m.moodChanges[len(m.moodChanges)-maxMoodChanges:]
+	}
+	m.moodChangesMu.Unlock()
 	return mood, nil
 }
 
@@ -764,5 +1941,10 @@ func (m *Manager) ApplyMoodDecay() error {
 	mood.Energy += (0.5 - mood.Energy) * 0.05
 	mood.Sociability += (0.5 - mood.Sociability) * 0.05
 
-	return m.db.Save(mood).Error
+	if err := m.db.Save(mood).Error; err != nil {
+		m.cache.mood.del(struct{}{})
+		return err
+	}
+	m.cache.mood.set(struct{}{}, *mood)
+	return nil
 }
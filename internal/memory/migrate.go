@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"fmt"
+	"mumu-bot/internal/config"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ==================== 数据库迁移 ====================
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int `gorm:"primarykey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// migration 一个版本化的 schema 变更，Down 为空表示不可回滚
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// migrations 按版本号递增排列，已发布的迁移不要修改，新的 schema 变更追加新版本
+// 迁移只使用 migrate_schema.go 中冻结的表结构快照；早期版本都是幂等的，已有数据库（之前依赖 AutoMigrate）执行 up 是安全的
+var migrations = []migration{
+	{
+		// 初始版本不可回滚，避免 migrate down 时把全部数据删掉
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(v1Models...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "global_user_profiles",
+		Up:      migrateMemberProfiles,
+	},
+	{
+		Version: 3,
+		Name:    "sticker_group_index",
+		Up:      migrateStickerIndex,
+	},
+	{
+		Version: 4,
+		Name:    "memory_summary_keywords",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &v4Memory{}, "Summary", "Keywords"); err != nil {
+				return err
+			}
+			// 全文索引改为覆盖内容和关键词，旧索引不再使用
+			if tx.Migrator().HasIndex(&v4Memory{}, "idx_memories_content_ft") {
+				return tx.Migrator().DropIndex(&v4Memory{}, "idx_memories_content_ft")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v4Memory{}, "summary", "keywords")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "memory_source_msg_id",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v5Memory{}, "SourceMsgID")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v5Memory{}, "source_msg_id")
+		},
+	},
+	{
+		Version: 6,
+		Name:    "group_info_atmosphere",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v6GroupInfo{}, "Atmosphere", "HotTopics", "Rules")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v6GroupInfo{}, "atmosphere", "hot_topics", "rules")
+		},
+	}, {
+		Version: 7,
+		Name:    "jargon_embedding",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v7Jargon{}, "Embedding")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v7Jargon{}, "embedding")
+		},
+	}, {
+		Version: 8,
+		Name:    "user_profile_timezone_address",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v8GlobalUserProfile{}, "Timezone", "PreferredAddress")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v8GlobalUserProfile{}, "timezone", "preferred_address")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "user_profile_interactions",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v9GlobalUserProfile{}, "Interactions")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v9GlobalUserProfile{}, "interactions")
+		},
+	},
+	{
+		Version: 10,
+		Name:    "tool_usages",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v10ToolUsage{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v10ToolUsage{})
+		},
+	}, {
+		Version: 11,
+		Name:    "memory_delete_reason",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &v11Memory{}, "DeleteReason")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &v11Memory{}, "delete_reason")
+		},
+	}, {
+		Version: 12,
+		Name:    "polls",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v12Poll{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v12Poll{})
+		},
+	}, {
+		Version: 13,
+		Name:    "tool_call_logs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v13ToolCallLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v13ToolCallLog{})
+		},
+	}, {
+		Version: 14,
+		Name:    "fulltext_indexes",
+		Up:      createFulltextIndexes,
+		Down:    dropFulltextIndexes,
+	},
+}
+
+// MigrationStatus 单个迁移的执行状态
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator 数据库迁移器，供 migrate 子命令在不启动记忆管理器的情况下使用
+type Migrator struct {
+	db *gorm.DB
+}
+
+// NewMigrator 连接数据库并创建迁移器
+func NewMigrator(cfg *config.Config) (*Migrator, error) {
+	db, err := openDB(&cfg.Memory, &gorm.Config{
+		Logger: newGormLogger(cfg.Debug.ShowSQL, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return &Migrator{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (mg *Migrator) Close() error {
+	if sqlDB, err := mg.db.DB(); err == nil {
+		return sqlDB.Close()
+	}
+	return nil
+}
+
+// applied 已执行的迁移
+func (mg *Migrator) applied() (map[int]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := mg.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]SchemaMigration, len(records))
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// Status 列出全部迁移及执行状态
+func (mg *Migrator) Status() ([]MigrationStatus, error) {
+	done, err := mg.applied()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]MigrationStatus, 0, len(migrations))
+	for _, mi := range migrations {
+		r, ok := done[mi.Version]
+		result = append(result, MigrationStatus{
+			Version:   mi.Version,
+			Name:      mi.Name,
+			Applied:   ok,
+			AppliedAt: r.AppliedAt,
+		})
+	}
+	return result, nil
+}
+
+// Pending 未执行的迁移数
+func (mg *Migrator) Pending() (int, error) {
+	done, err := mg.applied()
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, mi := range migrations {
+		if _, ok := done[mi.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Up 按版本顺序执行全部未执行的迁移，返回执行的数量
+func (mg *Migrator) Up() (int, error) {
+	done, err := mg.applied()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, mi := range migrations {
+		if _, ok := done[mi.Version]; ok {
+			continue
+		}
+		if err := mi.Up(mg.db); err != nil {
+			return count, fmt.Errorf("迁移 %d_%s 失败: %w", mi.Version, mi.Name, err)
+		}
+		record := SchemaMigration{Version: mi.Version, Name: mi.Name, AppliedAt: time.Now()}
+		if err := mg.db.Create(&record).Error; err != nil {
+			return count, fmt.Errorf("记录迁移 %d 失败: %w", mi.Version, err)
+		}
+		zap.L().Info("已执行数据库迁移", zap.Int("version", mi.Version), zap.String("name", mi.Name))
+		count++
+	}
+	return count, nil
+}
+
+// Down 回滚最近执行的 steps 个迁移，遇到不可回滚的迁移时停止
+func (mg *Migrator) Down(steps int) (int, error) {
+	done, err := mg.applied()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		mi := migrations[i]
+		if _, ok := done[mi.Version]; !ok {
+			continue
+		}
+		if mi.Down == nil {
+			return count, fmt.Errorf("迁移 %d_%s 不可回滚", mi.Version, mi.Name)
+		}
+		if err := mi.Down(mg.db); err != nil {
+			return count, fmt.Errorf("回滚 %d_%s 失败: %w", mi.Version, mi.Name, err)
+		}
+		if err := mg.db.Delete(&SchemaMigration{}, mi.Version).Error; err != nil {
+			return count, fmt.Errorf("删除迁移记录 %d 失败: %w", mi.Version, err)
+		}
+		zap.L().Info("已回滚数据库迁移", zap.Int("version", mi.Version), zap.String("name", mi.Name))
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,413 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== 迁移用的表结构快照 ====================
+// 迁移只能使用这里冻结的结构，不能引用 models.go 中会继续演化的模型，
+// 否则新字段会被早期版本提前创建，回滚和升级顺序都会出错。新增字段请追加新的快照和迁移版本
+
+// ---------- v1 initial_schema ----------
+
+type v1Memory struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Type        string  `gorm:"type:varchar(50);index"`
+	GroupID     int64   `gorm:"index"`
+	UserID      int64   `gorm:"index"`
+	Content     string  `gorm:"type:text"`
+	Importance  float64 `gorm:"default:0.5"`
+	AccessCount int     `gorm:"default:0"`
+	Persona     string  `gorm:"type:varchar(100);index"`
+
+	LastAccessAt *time.Time
+	Archived     bool `gorm:"default:false;index"`
+}
+
+func (v1Memory) TableName() string { return "memories" }
+
+type v1GlobalUserProfile struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	UserID      int64   `gorm:"uniqueIndex:idx_global_user"`
+	Nickname    string  `gorm:"type:varchar(100)"`
+	SpeakStyle  string  `gorm:"type:text"`
+	Interests   string  `gorm:"type:text"`
+	CommonWords string  `gorm:"type:text"`
+	Intimacy    float64 `gorm:"default:0.3"`
+	LastSpeak   time.Time
+	MsgCount    int    `gorm:"default:0"`
+	Birthday    string `gorm:"type:varchar(5);index"`
+	GamesPlayed int    `gorm:"default:0"`
+	GamesWon    int    `gorm:"default:0"`
+	LastCaredAt *time.Time
+}
+
+func (v1GlobalUserProfile) TableName() string { return "global_user_profiles" }
+
+type v1MemberProfile struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	GroupID    int64   `gorm:"uniqueIndex:idx_group_user"`
+	UserID     int64   `gorm:"uniqueIndex:idx_group_user"`
+	Nickname   string  `gorm:"type:varchar(100)"`
+	SpeakStyle string  `gorm:"type:text"`
+	Activity   float64 `gorm:"default:0.5"`
+	LastSpeak  time.Time
+	MsgCount   int `gorm:"default:0"`
+}
+
+func (v1MemberProfile) TableName() string { return "member_profiles" }
+
+type v1Expression struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	GroupID   int64  `gorm:"index"`
+	Situation string `gorm:"type:varchar(200)"`
+	Style     string `gorm:"type:varchar(200)"`
+	Examples  string `gorm:"type:text"`
+	Checked   bool   `gorm:"default:false"`
+	Rejected  bool   `gorm:"default:false"`
+
+	Count      int `gorm:"default:0"`
+	Responses  int `gorm:"default:0"`
+	LastUsedAt *time.Time
+}
+
+func (v1Expression) TableName() string { return "expressions" }
+
+type v1Jargon struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	GroupID  int64  `gorm:"index"`
+	Content  string `gorm:"type:varchar(100);index"`
+	Meaning  string `gorm:"type:text"`
+	Context  string `gorm:"type:text"`
+	Verified bool   `gorm:"default:false"`
+
+	Count      int        `gorm:"default:0"`
+	LastUsedAt *time.Time `gorm:"index"`
+}
+
+func (v1Jargon) TableName() string { return "jargons" }
+
+type v1MessageLog struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	MessageID   string `gorm:"type:varchar(100);uniqueIndex"`
+	GroupID     int64  `gorm:"index"`
+	UserID      int64  `gorm:"index"`
+	Nickname    string `gorm:"type:varchar(100)"`
+	Content     string `gorm:"type:text"`
+	MsgType     string `gorm:"type:varchar(50)"`
+	IsMentioned bool   `gorm:"default:false"`
+	Forwards    string `gorm:"type:text"`
+	Summarized  bool   `gorm:"default:false;index"`
+}
+
+func (v1MessageLog) TableName() string { return "message_logs" }
+
+type v1MessageArchive struct {
+	ID         uint      `gorm:"primarykey"`
+	CreatedAt  time.Time `gorm:"index"`
+	ArchivedAt time.Time `gorm:"autoCreateTime"`
+
+	MessageID string `gorm:"type:varchar(100);index"`
+	GroupID   int64  `gorm:"index"`
+	UserID    int64  `gorm:"index"`
+	Nickname  string `gorm:"type:varchar(100)"`
+	Content   string `gorm:"type:text"`
+	MsgType   string `gorm:"type:varchar(50)"`
+	Forwards  string `gorm:"type:text"`
+}
+
+func (v1MessageArchive) TableName() string { return "message_archives" }
+
+type v1Sticker struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	FileName    string `gorm:"type:varchar(100)"`
+	FileHash    string `gorm:"type:varchar(64);uniqueIndex:idx_sticker_hash_group"`
+	GroupID     int64  `gorm:"uniqueIndex:idx_sticker_hash_group;index"`
+	Description string `gorm:"type:text"`
+	UseCount    int    `gorm:"default:0"`
+
+	Emotion string `gorm:"type:varchar(20);index"`
+	Subject string `gorm:"type:varchar(50);index"`
+	HasText bool   `gorm:"default:false"`
+	Text    string `gorm:"type:varchar(200)"`
+}
+
+func (v1Sticker) TableName() string { return "stickers" }
+
+type v1MoodState struct {
+	ID        uint `gorm:"primarykey"`
+	UpdatedAt time.Time
+
+	Valence     float64 `gorm:"default:0.0"`
+	Energy      float64 `gorm:"default:0.5"`
+	Sociability float64 `gorm:"default:0.5"`
+
+	LastReason string `gorm:"type:varchar(200)"`
+}
+
+func (v1MoodState) TableName() string { return "mood_state" }
+
+type v1TokenUsage struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Date             string `gorm:"type:varchar(10);index:idx_date_group"`
+	GroupID          int64  `gorm:"index:idx_date_group"`
+	Model            string `gorm:"type:varchar(100)"`
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+}
+
+func (v1TokenUsage) TableName() string { return "token_usages" }
+
+type v1TopicSummary struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	GroupID      int64  `gorm:"index"`
+	Topic        string `gorm:"type:varchar(200)"`
+	Summary      string `gorm:"type:text"`
+	Keywords     string `gorm:"type:varchar(500)"`
+	Participants string `gorm:"type:text"`
+	StartTime    time.Time
+	EndTime      time.Time
+	MessageCount int
+	MemoryID     uint `gorm:"index"`
+}
+
+func (v1TopicSummary) TableName() string { return "topic_summaries" }
+
+type v1Reminder struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	GroupID   int64 `gorm:"index"`
+	UserID    int64
+	Nickname  string `gorm:"type:varchar(100)"`
+	CreatorID int64
+	Content   string    `gorm:"type:text"`
+	RemindAt  time.Time `gorm:"index"`
+	Done      bool      `gorm:"default:false;index"`
+}
+
+func (v1Reminder) TableName() string { return "reminders" }
+
+type v1GroupInfo struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	GroupID     int64  `gorm:"uniqueIndex"`
+	GroupName   string `gorm:"type:varchar(200)"`
+	MemberCount int
+	Notice      string `gorm:"type:text"`
+	Enabled     bool   `gorm:"default:false;index"`
+	OnboardedAt *time.Time
+}
+
+func (v1GroupInfo) TableName() string { return "group_infos" }
+
+type v1GameSession struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	GroupID      int64  `gorm:"index"`
+	Type         string `gorm:"type:varchar(50)"`
+	State        string `gorm:"type:text"`
+	Participants string `gorm:"type:text"`
+	WinnerID     int64
+	Active       bool `gorm:"default:true;index"`
+	EndedAt      *time.Time
+}
+
+func (v1GameSession) TableName() string { return "game_sessions" }
+
+type v1DecisionLog struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	GroupID    int64  `gorm:"index"`
+	Trigger    string `gorm:"type:varchar(50)"`
+	SpeakProb  float64
+	Tools      string `gorm:"type:text"`
+	Action     string `gorm:"type:varchar(50);index"`
+	Model      string `gorm:"type:varchar(100)"`
+	DurationMs int64
+	Error      string `gorm:"type:text"`
+}
+
+func (v1DecisionLog) TableName() string { return "decision_logs" }
+
+// v1Models 初始版本的全部表
+var v1Models = []any{
+	&v1Memory{},
+	&v1GlobalUserProfile{},
+	&v1MemberProfile{},
+	&v1Expression{},
+	&v1Jargon{},
+	&v1MessageLog{},
+	&v1MessageArchive{},
+	&v1Sticker{},
+	&v1MoodState{},
+	&v1TokenUsage{},
+	&v1TopicSummary{},
+	&v1Reminder{},
+	&v1GroupInfo{},
+	&v1GameSession{},
+	&v1DecisionLog{},
+}
+
+// ---------- v4 memory_summary_keywords ----------
+
+type v4Memory struct {
+	Summary  string `gorm:"type:varchar(255)"`
+	Keywords string `gorm:"type:varchar(255)"`
+}
+
+func (v4Memory) TableName() string { return "memories" }
+
+// ---------- v5 memory_source_msg_id ----------
+
+type v5Memory struct {
+	SourceMsgID string `gorm:"type:varchar(255)"`
+}
+
+func (v5Memory) TableName() string { return "memories" }
+
+// ---------- v6 group_info_atmosphere ----------
+
+type v6GroupInfo struct {
+	Atmosphere string `gorm:"type:varchar(500)"`
+	HotTopics  string `gorm:"type:varchar(500)"`
+	Rules      string `gorm:"type:text"`
+}
+
+func (v6GroupInfo) TableName() string { return "group_infos" }
+
+// ---------- v7 jargon_embedding ----------
+
+type v7Jargon struct {
+	Embedding []byte
+}
+
+func (v7Jargon) TableName() string { return "jargons" }
+
+// ---------- v8 user_profile_timezone_address ----------
+
+type v8GlobalUserProfile struct {
+	Timezone         string `gorm:"type:varchar(64)"`
+	PreferredAddress string `gorm:"type:varchar(100)"`
+}
+
+func (v8GlobalUserProfile) TableName() string { return "global_user_profiles" }
+
+// ---------- v9 user_profile_interactions ----------
+
+type v9GlobalUserProfile struct {
+	Interactions int `gorm:"default:0"`
+}
+
+func (v9GlobalUserProfile) TableName() string { return "global_user_profiles" }
+
+// ---------- v10 tool_usages ----------
+
+type v10ToolUsage struct {
+	Date  string `gorm:"type:varchar(10);primarykey"`
+	Tool  string `gorm:"type:varchar(64);primarykey"`
+	Count int    `gorm:"default:0"`
+}
+
+func (v10ToolUsage) TableName() string { return "tool_usages" }
+
+// ---------- v11 memory_delete_reason ----------
+
+type v11Memory struct {
+	DeleteReason string `gorm:"type:varchar(255)"`
+}
+
+func (v11Memory) TableName() string { return "memories" }
+
+// ---------- v12 polls ----------
+
+type v12Poll struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	GroupID   int64 `gorm:"index"`
+	MessageID int64
+	Question  string    `gorm:"type:varchar(200)"`
+	Options   string    `gorm:"type:text"`
+	CloseAt   time.Time `gorm:"index"`
+	Closed    bool      `gorm:"default:false;index"`
+	Result    string    `gorm:"type:text"`
+}
+
+func (v12Poll) TableName() string { return "polls" }
+
+// ---------- v13 tool_call_logs ----------
+
+type v13ToolCallLog struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	GroupID    int64  `gorm:"index"`
+	Tool       string `gorm:"type:varchar(100);index"`
+	Input      string `gorm:"type:text"`
+	Output     string `gorm:"type:text"`
+	DurationMs int64
+	Success    bool   `gorm:"index"`
+	Error      string `gorm:"type:text"`
+}
+
+func (v13ToolCallLog) TableName() string { return "tool_call_logs" }
+
+// addColumns 按快照结构添加缺少的列（已存在的跳过，兼容之前依赖 AutoMigrate 的数据库）
+func addColumns(tx *gorm.DB, model any, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns 删除存在的列
+func dropColumns(tx *gorm.DB, model any, columns ...string) error {
+	for _, col := range columns {
+		if !tx.Migrator().HasColumn(model, col) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
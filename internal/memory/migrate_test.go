@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// columnsByTable 解析模型，按表名汇总列名
+func columnsByTable(t *testing.T, models ...any) map[string]map[string]bool {
+	t.Helper()
+	cache := &sync.Map{}
+	result := make(map[string]map[string]bool)
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("解析 %T 失败: %v", model, err)
+		}
+		if result[s.Table] == nil {
+			result[s.Table] = make(map[string]bool)
+		}
+		for _, f := range s.Fields {
+			if f.DBName != "" {
+				result[s.Table][f.DBName] = true
+			}
+		}
+	}
+	return result
+}
+
+// TestMigrationSnapshotsMatchModels 迁移快照叠加后的表结构应与当前模型一致，
+// 模型加了字段却忘记追加迁移时这里会失败
+func TestMigrationSnapshotsMatchModels(t *testing.T) {
+	snapshots := append(append([]any{}, v1Models...),
+		&v4Memory{}, &v5Memory{}, &v6GroupInfo{}, &v7Jargon{}, &v8GlobalUserProfile{},
+		&v9GlobalUserProfile{}, &v10ToolUsage{}, &v11Memory{}, &v12Poll{}, &v13ToolCallLog{},
+	)
+	models := []any{
+		&Memory{}, &GlobalUserProfile{}, &MemberProfile{}, &Expression{}, &Jargon{},
+		&MessageLog{}, &MessageArchive{}, &Sticker{}, &MoodState{}, &TokenUsage{},
+		&TopicSummary{}, &Reminder{}, &GroupInfo{}, &GameSession{}, &DecisionLog{},
+		&ToolUsage{}, &Poll{}, &ToolCallLog{},
+	}
+
+	got := columnsByTable(t, snapshots...)
+	want := columnsByTable(t, models...)
+	for table, cols := range want {
+		for col := range cols {
+			if !got[table][col] {
+				t.Errorf("%s.%s 没有对应的迁移", table, col)
+			}
+		}
+	}
+	for table, cols := range got {
+		for col := range cols {
+			if !want[table][col] {
+				t.Errorf("迁移创建了模型中不存在的列 %s.%s", table, col)
+			}
+		}
+	}
+}
+
+// TestMigrationVersions 版本号必须从 1 开始连续递增，初始版本不可回滚
+func TestMigrationVersions(t *testing.T) {
+	versions := make([]int, len(migrations))
+	for i, mi := range migrations {
+		versions[i] = mi.Version
+		if mi.Up == nil {
+			t.Errorf("迁移 %d 缺少 Up", mi.Version)
+		}
+	}
+	if !sort.IntsAreSorted(versions) {
+		t.Fatalf("迁移版本未按顺序排列: %v", versions)
+	}
+	for i, v := range versions {
+		if v != i+1 {
+			t.Fatalf("迁移版本不连续: %v", versions)
+		}
+	}
+	if migrations[0].Down != nil {
+		t.Error("初始迁移不应该可以回滚")
+	}
+}
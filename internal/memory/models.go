@@ -2,6 +2,8 @@ package memory
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // MemoryType 记忆类型
@@ -11,39 +13,76 @@ const (
 	MemoryTypeGroupFact      MemoryType = "group_fact"      // 群长期事实（群规、群风格、重要事件等）
 	MemoryTypeSelfExperience MemoryType = "self_experience" // 自身经历（参与的事、被提及、感受等）
 	MemoryTypeConversation   MemoryType = "conversation"    // 对话记忆（重要的对话内容、群友说的事）
+	MemoryTypeTopicSummary   MemoryType = "topic_summary"   // 话题摘要（由摘要任务自动生成）
 )
 
+// DiaryPrefix 日记记忆的内容前缀（日记以 self_experience 类型保存）
+const DiaryPrefix = "【日记"
+
 // Memory 长期记忆
 type Memory struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // 软删除（记忆巩固合并后的旧记录）
 
 	Type        MemoryType `gorm:"type:varchar(50);index" json:"type"`
 	GroupID     int64      `gorm:"index" json:"group_id"`
 	UserID      int64      `gorm:"index" json:"user_id,omitempty"`
 	Content     string     `gorm:"type:text" json:"content"`
+	Summary     string     `gorm:"type:varchar(255)" json:"summary,omitempty"`       // LLM 生成的一句话摘要，异步填充
+	Keywords    string     `gorm:"type:varchar(255)" json:"keywords,omitempty"`      // LLM 生成的关键词，逗号分隔，用于关键词检索
+	SourceMsgID string     `gorm:"type:varchar(255)" json:"source_msg_id,omitempty"` // 来源消息 ID，多个用逗号分隔
 	Importance  float64    `gorm:"default:0.5" json:"importance"`
 	AccessCount int        `gorm:"default:0" json:"access_count"`
+	Persona     string     `gorm:"type:varchar(100);index" json:"persona,omitempty"` // 所属人格，空表示默认人格
+
+	LastAccessAt *time.Time `json:"last_access_at,omitempty"`                         // 最后一次被检索到的时间
+	Archived     bool       `gorm:"default:false;index" json:"archived"`              // 重要性衰减到阈值以下后归档，不再参与检索
+	DeleteReason string     `gorm:"type:varchar(255)" json:"delete_reason,omitempty"` // 被主动删除时的原因（软删除，保留审计）
 }
 
 func (Memory) TableName() string { return "memories" }
 
-// MemberProfile 成员画像
+// GlobalUserProfile 跨群共享的用户档案（兴趣、亲密度、生日等与群无关的信息）
+type GlobalUserProfile struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      int64      `gorm:"uniqueIndex:idx_global_user" json:"user_id"`
+	Nickname    string     `gorm:"type:varchar(100)" json:"nickname"`
+	SpeakStyle  string     `gorm:"type:text" json:"speak_style"`
+	Interests   string     `gorm:"type:text" json:"interests"`
+	CommonWords string     `gorm:"type:text" json:"common_words"`
+	Intimacy    float64    `gorm:"default:0.3" json:"intimacy"`
+	LastSpeak   time.Time  `json:"last_speak"`                            // 在任意群最后一次发言
+	MsgCount    int        `gorm:"default:0" json:"msg_count"`            // 所有群的发言总数
+	Birthday    string     `gorm:"type:varchar(5);index" json:"birthday"` // 生日（公历 MM-DD）
+	GamesPlayed int        `gorm:"default:0" json:"games_played"`         // 参与小游戏次数
+	GamesWon    int        `gorm:"default:0" json:"games_won"`            // 小游戏获胜次数
+	LastCaredAt *time.Time `json:"last_cared_at"`                         // 上次主动私聊关心的时间
+
+	Interactions     int    `gorm:"default:0" json:"interactions"`                        // 与你的互动次数（互相回复、@、戳一戳）
+	Timezone         string `gorm:"type:varchar(64)" json:"timezone,omitempty"`           // 所在时区（IANA 名称，如 Asia/Tokyo）
+	PreferredAddress string `gorm:"type:varchar(100)" json:"preferred_address,omitempty"` // 希望被怎么称呼
+}
+
+func (GlobalUserProfile) TableName() string { return "global_user_profiles" }
+
+// MemberProfile 群内成员画像，只记录与群相关的差异（群名片、群内活跃度、群内特有风格）
 type MemberProfile struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
-	UserID      int64     `gorm:"uniqueIndex::idx_user" json:"user_id"`
-	Nickname    string    `gorm:"type:varchar(100)" json:"nickname"`
-	SpeakStyle  string    `gorm:"type:text" json:"speak_style"`
-	Interests   string    `gorm:"type:text" json:"interests"`
-	CommonWords string    `gorm:"type:text" json:"common_words"`
-	Activity    float64   `gorm:"default:0.5" json:"activity"`
-	Intimacy    float64   `gorm:"default:0.3" json:"intimacy"`
-	LastSpeak   time.Time `json:"last_speak"`
-	MsgCount    int       `gorm:"default:0" json:"msg_count"`
+	GroupID    int64     `gorm:"uniqueIndex:idx_group_user" json:"group_id"`
+	UserID     int64     `gorm:"uniqueIndex:idx_group_user" json:"user_id"`
+	Nickname   string    `gorm:"type:varchar(100)" json:"nickname"` // 群名片
+	SpeakStyle string    `gorm:"type:text" json:"speak_style"`      // 仅在本群表现出的说话风格
+	Activity   float64   `gorm:"default:0.5" json:"activity"`
+	LastSpeak  time.Time `json:"last_speak"`
+	MsgCount   int       `gorm:"default:0" json:"msg_count"`
 }
 
 func (MemberProfile) TableName() string { return "member_profiles" }
@@ -60,6 +99,10 @@ type Expression struct {
 	Examples  string `gorm:"type:text" json:"examples"`          // 示例 JSON
 	Checked   bool   `gorm:"default:false" json:"checked"`
 	Rejected  bool   `gorm:"default:false" json:"rejected"`
+
+	Count      int        `gorm:"default:0" json:"count"`     // 被模仿发言的次数
+	Responses  int        `gorm:"default:0" json:"responses"` // 模仿发言后得到回应（回复或@）的次数
+	LastUsedAt *time.Time `json:"last_used_at"`               // 最后一次被模仿的时间
 }
 
 func (Expression) TableName() string { return "expressions" }
@@ -75,6 +118,11 @@ type Jargon struct {
 	Meaning  string `gorm:"type:text" json:"meaning"`
 	Context  string `gorm:"type:text" json:"context"`
 	Verified bool   `gorm:"default:false" json:"verified"`
+
+	Count      int        `gorm:"default:0" json:"count"`    // 群聊中出现的次数
+	LastUsedAt *time.Time `gorm:"index" json:"last_used_at"` // 最后一次在群聊中出现的时间
+
+	Embedding []byte `json:"-"` // 内容和含义的向量（float32 小端序），用于语义检索
 }
 
 func (Jargon) TableName() string { return "jargons" }
@@ -91,21 +139,179 @@ type MessageLog struct {
 	Content     string `gorm:"type:text" json:"content"`
 	MsgType     string `gorm:"type:varchar(50)" json:"msg_type"`
 	IsMentioned bool   `gorm:"default:false" json:"is_mentioned"`
-	Forwards    string `gorm:"type:text" json:"forwards,omitempty"` // 合并转发内容的 JSON
+	Forwards    string `gorm:"type:text" json:"forwards,omitempty"`   // 合并转发内容的 JSON
+	Summarized  bool   `gorm:"default:false;index" json:"summarized"` // 是否已被话题摘要处理
 }
 
 func (MessageLog) TableName() string { return "message_logs" }
 
+// MessageArchive 归档的历史消息（消息日志清理时转存，不参与任何检索）
+type MessageArchive struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"` // 原消息时间
+	ArchivedAt time.Time `gorm:"autoCreateTime" json:"archived_at"`
+
+	MessageID string `gorm:"type:varchar(100);index" json:"message_id"`
+	GroupID   int64  `gorm:"index" json:"group_id"`
+	UserID    int64  `gorm:"index" json:"user_id"`
+	Nickname  string `gorm:"type:varchar(100)" json:"nickname"`
+	Content   string `gorm:"type:text" json:"content"`
+	MsgType   string `gorm:"type:varchar(50)" json:"msg_type"`
+	Forwards  string `gorm:"type:text" json:"forwards,omitempty"`
+}
+
+func (MessageArchive) TableName() string { return "message_archives" }
+
+// TopicSummary 话题摘要
+type TopicSummary struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	GroupID      int64     `gorm:"index" json:"group_id"`
+	Topic        string    `gorm:"type:varchar(200)" json:"topic"`    // 话题名
+	Summary      string    `gorm:"type:text" json:"summary"`          // 摘要内容
+	Keywords     string    `gorm:"type:varchar(500)" json:"keywords"` // 关键词，逗号分隔
+	Participants string    `gorm:"type:text" json:"participants"`     // 参与者昵称，逗号分隔
+	StartTime    time.Time `json:"start_time"`                        // 话题涉及消息的起止时间
+	EndTime      time.Time `json:"end_time"`
+	MessageCount int       `json:"message_count"`
+	MemoryID     uint      `gorm:"index" json:"memory_id"` // 对应的长期记忆（用于向量检索）
+}
+
+func (TopicSummary) TableName() string { return "topic_summaries" }
+
+// GroupInfo 群信息（自动发现的群和运行时启用的群）
+type GroupInfo struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"` // 首次发现时间
+	UpdatedAt time.Time `json:"updated_at"`
+
+	GroupID     int64      `gorm:"uniqueIndex" json:"group_id"`
+	GroupName   string     `gorm:"type:varchar(200)" json:"group_name"`
+	MemberCount int        `json:"member_count"`
+	Notice      string     `gorm:"type:text" json:"notice"`             // 最新群公告
+	Atmosphere  string     `gorm:"type:varchar(500)" json:"atmosphere"` // 群氛围，由 LLM 通过工具维护
+	HotTopics   string     `gorm:"type:varchar(500)" json:"hot_topics"` // 最近的热点话题，由 LLM 通过工具维护
+	Rules       string     `gorm:"type:text" json:"rules"`              // 群规或约定俗成的规矩
+	Enabled     bool       `gorm:"default:false;index" json:"enabled"`  // 是否已通过 API/命令启用
+	OnboardedAt *time.Time `json:"onboarded_at"`                        // 完成初次加入流程的时间
+}
+
+func (GroupInfo) TableName() string { return "group_infos" }
+
+// GameSession 群内小游戏会话
+type GameSession struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	GroupID      int64      `gorm:"index" json:"group_id"`
+	Type         string     `gorm:"type:varchar(50)" json:"type"`     // 游戏类型
+	State        string     `gorm:"type:text" json:"state"`           // 游戏状态 JSON
+	Participants string     `gorm:"type:text" json:"participants"`    // 参与者QQ号，逗号分隔
+	WinnerID     int64      `json:"winner_id"`                        // 获胜者（0 表示无）
+	Active       bool       `gorm:"default:true;index" json:"active"` // 是否进行中
+	EndedAt      *time.Time `json:"ended_at"`
+}
+
+func (GameSession) TableName() string { return "game_sessions" }
+
+// DecisionLog 思考决策记录（排查为什么说/不说话）
+type DecisionLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	GroupID    int64   `gorm:"index" json:"group_id"`
+	Trigger    string  `gorm:"type:varchar(50)" json:"trigger"`      // 触发原因：mention、proactive、reminder 等
+	SpeakProb  float64 `json:"speak_prob"`                           // 主动思考时的发言概率
+	Tools      string  `gorm:"type:text" json:"tools"`               // 工具调用序列，逗号分隔
+	Action     string  `gorm:"type:varchar(50);index" json:"action"` // 最终动作：speak、quiet、none、prejudge_skip 等
+	Model      string  `gorm:"type:varchar(100)" json:"model"`
+	DurationMs int64   `json:"duration_ms"`
+	Error      string  `gorm:"type:text" json:"error,omitempty"`
+}
+
+func (DecisionLog) TableName() string { return "decision_logs" }
+
+// ToolCallLog 工具调用记录（行为审计）
+type ToolCallLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	GroupID    int64  `gorm:"index" json:"group_id"`
+	Tool       string `gorm:"type:varchar(100);index" json:"tool"`
+	Input      string `gorm:"type:text" json:"input"`
+	Output     string `gorm:"type:text" json:"output"` // 输出摘要（过长时截断）
+	DurationMs int64  `json:"duration_ms"`
+	Success    bool   `gorm:"index" json:"success"`
+	Error      string `gorm:"type:text" json:"error,omitempty"`
+}
+
+func (ToolCallLog) TableName() string { return "tool_call_logs" }
+
+// Reminder 定时提醒
+type Reminder struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	GroupID   int64     `gorm:"index" json:"group_id"`
+	UserID    int64     `json:"user_id"` // 被提醒的群友
+	Nickname  string    `gorm:"type:varchar(100)" json:"nickname"`
+	CreatorID int64     `json:"creator_id"`                      // 发起提醒的群友（0 表示阿沐自己）
+	Content   string    `gorm:"type:text" json:"content"`        // 提醒内容
+	RemindAt  time.Time `gorm:"index" json:"remind_at"`          // 提醒时间
+	Done      bool      `gorm:"default:false;index" json:"done"` // 是否已触发
+}
+
+func (Reminder) TableName() string { return "reminders" }
+
+// Poll 群投票，群友通过贴表情或发送选项编号投票
+type Poll struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	GroupID   int64     `gorm:"index" json:"group_id"`
+	MessageID int64     `json:"message_id"`                        // 投票消息 ID（统计表情回应用）
+	Question  string    `gorm:"type:varchar(200)" json:"question"` // 投票问题
+	Options   string    `gorm:"type:text" json:"options"`          // 选项 JSON（[]PollOption）
+	CloseAt   time.Time `gorm:"index" json:"close_at"`             // 截止时间
+	Closed    bool      `gorm:"default:false;index" json:"closed"`
+	Result    string    `gorm:"type:text" json:"result,omitempty"` // 公布的结果
+}
+
+func (Poll) TableName() string { return "polls" }
+
+// PollOption 投票选项
+type PollOption struct {
+	Text    string `json:"text"`
+	EmojiID int    `json:"emoji_id"` // 对应的表情回应 ID
+	Seeded  bool   `json:"seeded"`   // 是否已由阿沐先贴上（统计时要减掉这一票）
+}
+
 // Sticker 收集的表情包
 type Sticker struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
-	FileName    string `gorm:"type:varchar(100)" json:"file_name"`            // 本地文件名（uuid.ext）
-	FileHash    string `gorm:"type:varchar(64);uniqueIndex" json:"file_hash"` // 文件 MD5 哈希（用于去重）
-	Description string `gorm:"type:text" json:"description"`                  // Vision 模型生成的描述
-	UseCount    int    `gorm:"default:0" json:"use_count"`                    // 使用次数
+	FileName    string `gorm:"type:varchar(100)" json:"file_name"`                                   // 本地文件名（uuid.ext）
+	FileHash    string `gorm:"type:varchar(64);uniqueIndex:idx_sticker_hash_group" json:"file_hash"` // 文件 MD5 哈希（用于去重）
+	GroupID     int64  `gorm:"uniqueIndex:idx_sticker_hash_group;index" json:"group_id"`             // 来源群，0 表示来源未知（对所有群可见）
+	Description string `gorm:"type:text" json:"description"`                                         // Vision 模型生成的描述
+	UseCount    int    `gorm:"default:0" json:"use_count"`                                           // 使用次数
+
+	Emotion string `gorm:"type:varchar(20);index" json:"emotion,omitempty"` // 情绪标签
+	Subject string `gorm:"type:varchar(50);index" json:"subject,omitempty"` // 画面主体
+	HasText bool   `gorm:"default:false" json:"has_text"`                   // 是否带字
+	Text    string `gorm:"type:varchar(200)" json:"text,omitempty"`         // 图上的文字
+}
+
+// StickerFilter 表情包标签过滤条件，零值字段不过滤
+type StickerFilter struct {
+	GroupID int64 // 当前群，按 sticker.scope 决定是否只看或优先看本群收集的表情包
+	Emotion string
+	Subject string
+	HasText *bool
 }
 
 func (Sticker) TableName() string { return "stickers" }
@@ -125,3 +331,27 @@ type MoodState struct {
 }
 
 func (MoodState) TableName() string { return "mood_state" }
+
+// TokenUsage token 用量记录（每次思考一条）
+type TokenUsage struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Date             string `gorm:"type:varchar(10);index:idx_date_group" json:"date"` // 日期 2006-01-02
+	GroupID          int64  `gorm:"index:idx_date_group" json:"group_id"`
+	Model            string `gorm:"type:varchar(100)" json:"model"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+}
+
+func (TokenUsage) TableName() string { return "token_usages" }
+
+// ToolUsage 有成本的工具（语音、画图等）每天的调用次数，用于每日限额
+type ToolUsage struct {
+	Date  string `gorm:"type:varchar(10);primarykey" json:"date"` // 日期 2006-01-02
+	Tool  string `gorm:"type:varchar(64);primarykey" json:"tool"`
+	Count int    `gorm:"default:0" json:"count"`
+}
+
+func (ToolUsage) TableName() string { return "tool_usages" }
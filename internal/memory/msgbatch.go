@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// messageBatchSize 返回消息日志批量写入的条数，1 表示逐条写入
+func (m *Manager) messageBatchSize() int {
+	size := m.cfg.Memory.MessageBatch.Size
+	if size <= 0 {
+		size = 50
+	}
+	return size
+}
+
+// startMessageFlusher 启动消息日志定时落盘任务，停止时会把缓冲区剩余的消息写完
+func (m *Manager) startMessageFlusher() {
+	intervalMs := m.cfg.Memory.MessageBatch.FlushIntervalMs
+	if intervalMs <= 0 {
+		intervalMs = 2000
+	}
+
+	// 停止信号和退出信号在启动时取好，Close 会把 cleanupStop 置为 nil
+	stop, done := m.cleanupStop, make(chan struct{})
+	m.msgFlushDone = done
+	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ticker.C:
+				m.flushMessages()
+			case <-stop:
+				ticker.Stop()
+				m.flushMessages()
+				return
+			}
+		}
+	}()
+}
+
+// flushMessages 把缓冲区中的消息批量写入数据库
+// 按消息读取消息日志前也会调用，保证刚收到的消息能查到
+func (m *Manager) flushMessages() {
+	m.msgFlushMu.Lock()
+	defer m.msgFlushMu.Unlock()
+
+	m.msgBufMu.Lock()
+	batch := m.msgBuf
+	m.msgBuf = nil
+	m.msgBufMu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	if err := m.db.CreateInBatches(batch, m.messageBatchSize()).Error; err != nil {
+		// 批量写入在事务中整体回滚，改为逐条写入，避免一条坏数据拖累整批消息
+		zap.L().Warn("批量写入消息日志失败，改为逐条写入", zap.Int("count", len(batch)), zap.Error(err))
+		failed := 0
+		for i := range batch {
+			batch[i].ID = 0 // 回滚前可能已经回填了自增 ID
+			if err := m.db.Create(&batch[i]).Error; err != nil {
+				failed++
+				zap.L().Warn("写入消息日志失败", zap.String("message_id", batch[i].MessageID), zap.Error(err))
+			}
+		}
+		if failed > 0 {
+			zap.L().Warn("部分消息日志写入失败", zap.Int("failed", failed), zap.Int("count", len(batch)))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrGroupStillEnabled 群仍在配置中或运行时启用，不能清理
+var ErrGroupStillEnabled = errors.New("群仍在启用中，请先从配置中移除或停用")
+
+// groupScopedModels 按群存储、清理群数据时需要删除的表
+var groupScopedModels = []struct {
+	name  string
+	model any
+}{
+	{"memories", &Memory{}},
+	{"member_profiles", &MemberProfile{}},
+	{"expressions", &Expression{}},
+	{"jargons", &Jargon{}},
+	{"message_logs", &MessageLog{}},
+	{"message_archives", &MessageArchive{}},
+	{"topic_summaries", &TopicSummary{}},
+	{"reminders", &Reminder{}},
+	{"polls", &Poll{}},
+	{"game_sessions", &GameSession{}},
+	{"decision_logs", &DecisionLog{}},
+	{"tool_call_logs", &ToolCallLog{}},
+	{"token_usages", &TokenUsage{}},
+	{"group_infos", &GroupInfo{}},
+}
+
+// PurgeGroup 删除某个群的全部数据（记忆、消息、画像、向量等），返回各表删除的行数
+// 跨群共享的全局用户档案不会删除；表情包只在按群隔离时删除该群收集的部分
+// 群仍在配置中启用，或通过 API/命令运行时启用（记录在群信息中）时返回 ErrGroupStillEnabled
+func (m *Manager) PurgeGroup(ctx context.Context, groupID int64) (map[string]int64, error) {
+	if m.cfg.IsGroupEnabled(groupID) {
+		return nil, ErrGroupStillEnabled
+	}
+	if info, err := m.GetGroupInfo(groupID); err == nil && info.Enabled {
+		return nil, ErrGroupStillEnabled
+	}
+
+	// 先把缓冲区的消息写入，避免清理后又被写回
+	m.flushMessages()
+
+	result := make(map[string]int64)
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range groupScopedModels {
+			res := tx.Unscoped().Where("group_id = ?", groupID).Delete(t.model)
+			if res.Error != nil {
+				return res.Error
+			}
+			result[t.name] = res.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if m.cfg.Sticker.Scope == StickerScopeGroupFirst || m.cfg.Sticker.Scope == StickerScopeGroupOnly {
+		var stickers []Sticker
+		if err := m.db.Where("group_id = ?", groupID).Find(&stickers).Error; err != nil {
+			return result, err
+		}
+		for _, s := range stickers {
+			if err := m.removeStickerFile(s.FileName); err != nil {
+				zap.L().Warn("删除表情包文件失败", zap.String("file", s.FileName), zap.Error(err))
+			}
+		}
+		res := m.db.Where("group_id = ?", groupID).Delete(&Sticker{})
+		if res.Error != nil {
+			return result, res.Error
+		}
+		result["stickers"] = res.RowsAffected
+	}
+
+	if m.vectors != nil {
+		if err := m.vectors.DeleteByGroup(ctx, groupID); err != nil {
+			return result, err
+		}
+	}
+
+	m.invalidateJargonCache(groupID)
+	m.removeGroupJargonVectors(groupID)
+	m.cache.invalidateGroup(groupID)
+	m.expressionUsesMu.Lock()
+	delete(m.expressionUses, groupID)
+	m.expressionUsesMu.Unlock()
+
+	zap.L().Info("已清理群数据", zap.Int64("group_id", groupID), zap.Any("deleted", result))
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// GroupMemoryStats 某群的长期记忆占用
+type GroupMemoryStats struct {
+	GroupID  int64 `json:"group_id"`
+	Total    int64 `json:"total"`
+	Archived int64 `json:"archived"`
+	Quota    int   `json:"quota"` // 0 表示不限制
+}
+
+// enforceMemoryQuota 群内记忆超过配额时淘汰多出的部分
+// 优先淘汰已归档的，其次按重要性、访问次数、创建时间从低到高；keepID 为刚保存的记忆，不参与淘汰
+func (m *Manager) enforceMemoryQuota(ctx context.Context, groupID int64, keepID uint) {
+	quota := m.cfg.MemoryQuota(groupID)
+	if quota <= 0 {
+		return
+	}
+
+	var count int64
+	if err := m.db.Model(&Memory{}).Where("group_id = ?", groupID).Count(&count).Error; err != nil || count <= int64(quota) {
+		return
+	}
+
+	var ids []uint
+	if err := m.db.Model(&Memory{}).Where("group_id = ? AND id <> ?", groupID, keepID).
+		Order("archived DESC, importance ASC, access_count ASC, created_at ASC").
+		Limit(int(count)-quota).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return
+	}
+
+	if err := m.db.Where("id IN ?", ids).Delete(&Memory{}).Error; err != nil {
+		zap.L().Warn("淘汰超额记忆失败", zap.Int64("group_id", groupID), zap.Error(err))
+		return
+	}
+	if m.vectors != nil {
+		if err := m.vectors.Delete(ctx, ids); err != nil {
+			zap.L().Warn("删除向量失败", zap.Error(err))
+		}
+	}
+	zap.L().Info("群记忆超出配额，已淘汰", zap.Int64("group_id", groupID), zap.Int("count", len(ids)), zap.Int("quota", quota))
+}
+
+// GetGroupMemoryStats 统计各群的长期记忆占用
+func (m *Manager) GetGroupMemoryStats() ([]GroupMemoryStats, error) {
+	var stats []GroupMemoryStats
+	err := m.db.Model(&Memory{}).
+		Select("group_id, COUNT(*) AS total, SUM(CASE WHEN archived THEN 1 ELSE 0 END) AS archived").
+		Group("group_id").Order("total DESC").Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		stats[i].Quota = m.cfg.MemoryQuota(stats[i].GroupID)
+	}
+	return stats, nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// rankCandidateFactor 检索时先取 limit 的若干倍候选，再综合排序截断
+const rankCandidateFactor = 3
+
+// rankMemories 综合相似度、重要性与新近度对记忆排序并截取前 limit 条
+// similarity 为 nil 时（关键词检索）只比较重要性与新近度
+// 新近度按最后访问时间（没有时用创建时间）以半衰期指数衰减
+func (m *Manager) rankMemories(memories []Memory, similarity map[uint]float64, limit int) []Memory {
+	cfg := m.cfg.Memory.LongTerm
+	w := cfg.RankWeights
+	if w.Similarity <= 0 && w.Importance <= 0 && w.Recency <= 0 {
+		w.Similarity, w.Importance, w.Recency = 0.6, 0.25, 0.15
+	}
+	halfLife := cfg.RecencyHalfLifeDays
+	if halfLife <= 0 {
+		halfLife = 14
+	}
+
+	now := time.Now()
+	scores := make(map[uint]float64, len(memories))
+	for _, mem := range memories {
+		last := mem.CreatedAt
+		if mem.LastAccessAt != nil && mem.LastAccessAt.After(last) {
+			last = *mem.LastAccessAt
+		}
+		days := now.Sub(last).Hours() / 24
+		recency := math.Pow(0.5, max(days, 0)/halfLife)
+		scores[mem.ID] = w.Similarity*similarity[mem.ID] + w.Importance*mem.Importance + w.Recency*recency
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		return scores[memories[i].ID] > scores[memories[j].ID]
+	})
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+	return memories
+}
+
+// touchMemories 记录记忆被检索命中：访问次数加一并更新最后访问时间
+func (m *Manager) touchMemories(memories []Memory) {
+	if len(memories) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(memories))
+	for _, mem := range memories {
+		ids = append(ids, mem.ID)
+	}
+	// 用 UpdateColumns 避免刷新 updated_at
+	_ = m.db.Model(&Memory{}).Where("id IN ?", ids).UpdateColumns(map[string]any{
+		"access_count":   gorm.Expr("access_count + 1"),
+		"last_access_at": time.Now(),
+	}).Error
+}
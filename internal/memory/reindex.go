@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"mumu-bot/internal/vector"
+
+	"go.uber.org/zap"
+)
+
+// ReindexProgress 向量重建进度
+type ReindexProgress struct {
+	Total  int64 // 本次需要处理的记忆总数
+	Done   int64 // 已处理数
+	Failed int64 // embedding 失败而跳过的数量
+	LastID uint  // 已处理的最大记忆 ID（用于断点续传）
+}
+
+// ResetVectors 清空并重建向量集合
+func (m *Manager) ResetVectors(ctx context.Context) error {
+	if m.vectors == nil {
+		return errors.New("未启用向量存储")
+	}
+	return m.vectors.Reset(ctx)
+}
+
+// Reindex 从 fromID 之后开始遍历记忆，重新生成 embedding 并批量写入向量存储
+// 每处理完一批调用一次 progress，调用方可以保存 LastID 实现断点续传
+func (m *Manager) Reindex(ctx context.Context, fromID uint, batchSize int, progress func(ReindexProgress)) error {
+	if m.vectors == nil {
+		return errors.New("未启用向量存储")
+	}
+	if m.embedding == nil {
+		return errors.New("Embedding 客户端不可用")
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	p := ReindexProgress{LastID: fromID}
+	if err := m.db.Model(&Memory{}).Where("id > ?", fromID).Count(&p.Total).Error; err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var batch []Memory
+		if err := m.db.Where("id > ?", p.LastID).Order("id ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(batch))
+		vectors := make([]vector.MemoryVector, 0, len(batch))
+		for _, mem := range batch {
+			ids = append(ids, mem.ID)
+			emb, err := m.embedding.Embed(ctx, mem.Content)
+			if err != nil {
+				zap.L().Warn("生成 embedding 失败", zap.Uint("id", mem.ID), zap.Error(err))
+				p.Failed++
+				continue
+			}
+			emb32 := make([]float32, len(emb))
+			for i, v := range emb {
+				emb32[i] = float32(v)
+			}
+			vectors = append(vectors, vector.MemoryVector{
+				MemoryID:  mem.ID,
+				GroupID:   mem.GroupID,
+				MemType:   string(mem.Type),
+				Embedding: emb32,
+			})
+		}
+
+		// 先删除旧向量，避免重复执行时产生重复数据
+		if err := m.vectors.Delete(ctx, ids); err != nil {
+			return err
+		}
+		if err := m.vectors.InsertBatch(ctx, vectors); err != nil {
+			return err
+		}
+
+		p.Done += int64(len(batch))
+		p.LastID = batch[len(batch)-1].ID
+		if progress != nil {
+			progress(p)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startStickerCleanup 启动表情包清理定时任务
+func (m *Manager) startStickerCleanup() {
+	intervalHours := m.cfg.Sticker.Cleanup.IntervalHours
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+
+	ticker := time.NewTicker(time.Duration(intervalHours) * time.Hour)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if n, err := m.CleanupStickers(); err != nil {
+					zap.L().Warn("清理表情包失败", zap.Error(err))
+				} else if n > 0 {
+					zap.L().Info("已清理表情包", zap.Int("count", n))
+				}
+			case <-m.cleanupStop:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	zap.L().Info("表情包清理任务已启动")
+}
+
+// CleanupStickers 清理长期未使用的表情包，以及超出数量上限的部分，返回清理数量
+func (m *Manager) CleanupStickers() (int, error) {
+	cfg := m.cfg.Sticker.Cleanup
+	unusedDays := cfg.UnusedDays
+	if unusedDays <= 0 {
+		unusedDays = 30
+	}
+
+	var stale []Sticker
+	if err := m.db.Where("use_count = 0 AND created_at < ?", time.Now().AddDate(0, 0, -unusedDays)).
+		Find(&stale).Error; err != nil {
+		return 0, err
+	}
+
+	if cfg.MaxCount > 0 {
+		var total int64
+		if err := m.db.Model(&Sticker{}).Count(&total).Error; err != nil {
+			return 0, err
+		}
+		if over := int(total) - len(stale) - cfg.MaxCount; over > 0 {
+			staleIDs := make([]uint, 0, len(stale))
+			for _, s := range stale {
+				staleIDs = append(staleIDs, s.ID)
+			}
+			q := m.db.Order("use_count ASC, updated_at ASC").Limit(over)
+			if len(staleIDs) > 0 {
+				q = q.Where("id NOT IN ?", staleIDs)
+			}
+			var extra []Sticker
+			if err := q.Find(&extra).Error; err != nil {
+				return 0, err
+			}
+			stale = append(stale, extra...)
+		}
+	}
+
+	removed := 0
+	for _, s := range stale {
+		if err := m.removeStickerFile(s.FileName); err != nil {
+			zap.L().Warn("删除表情包文件失败", zap.String("file", s.FileName), zap.Error(err))
+			continue
+		}
+		if err := m.db.Delete(&Sticker{}, s.ID).Error; err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// findScopedSticker 按 ID 查找表情包
+// groupID 不为 0 且表情包按群隔离时，只能找到本群收集的（或来源未知的）表情包
+func (m *Manager) findScopedSticker(id uint, groupID int64) (*Sticker, error) {
+	var sticker Sticker
+	q := m.db.Where("id = ?", id)
+	if groupID != 0 && (m.cfg.Sticker.Scope == StickerScopeGroupFirst || m.cfg.Sticker.Scope == StickerScopeGroupOnly) {
+		q = q.Where("group_id IN ?", []int64{groupID, 0})
+	}
+	if err := q.First(&sticker).Error; err != nil {
+		return nil, err
+	}
+	return &sticker, nil
+}
+
+// UpdateStickerLabels 更新表情包的描述和标签，可见范围同 DeleteSticker
+func (m *Manager) UpdateStickerLabels(id uint, groupID int64, labels *Sticker) (*Sticker, error) {
+	sticker, err := m.findScopedSticker(id, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.db.Model(sticker).Updates(map[string]any{
+		"description": labels.Description,
+		"emotion":     labels.Emotion,
+		"subject":     labels.Subject,
+		"has_text":    labels.HasText,
+		"text":        labels.Text,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return sticker, nil
+}
+
+// DeleteSticker 删除表情包记录和文件
+// groupID 不为 0 且表情包按群隔离时，只能删除本群收集的（或来源未知的）表情包
+func (m *Manager) DeleteSticker(id uint, groupID int64) (*Sticker, error) {
+	sticker, err := m.findScopedSticker(id, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.db.Delete(&Sticker{}, sticker.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := m.removeStickerFile(sticker.FileName); err != nil {
+		zap.L().Warn("删除表情包文件失败", zap.String("file", sticker.FileName), zap.Error(err))
+	}
+	return sticker, nil
+}
+
+// removeStickerFile 删除表情包文件，配置了归档目录时移动过去
+func (m *Manager) removeStickerFile(fileName string) error {
+	storagePath := m.cfg.Sticker.StoragePath
+	if storagePath == "" {
+		storagePath = "./stickers"
+	}
+	src := filepath.Join(storagePath, fileName)
+
+	archivePath := m.cfg.Sticker.Cleanup.ArchivePath
+	if archivePath == "" {
+		if err := os.Remove(src); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(archivePath, 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, filepath.Join(archivePath, fileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ==================== 工具每日限额 ====================
+
+// TryUseTool 占用一次工具的当日额度，额度用完时返回 false；limit <= 0 表示不限制
+func (m *Manager) TryUseTool(tool string, limit int) (bool, error) {
+	today := time.Now().Format(time.DateOnly)
+	if err := m.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ToolUsage{Date: today, Tool: tool}).Error; err != nil {
+		return false, err
+	}
+
+	query := m.db.Model(&ToolUsage{}).Where("date = ? AND tool = ?", today, tool)
+	if limit > 0 {
+		query = query.Where("count < ?", limit)
+	}
+	res := query.UpdateColumn("count", gorm.Expr("count + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// RefundToolUse 工具执行失败时退还一次当日额度
+func (m *Manager) RefundToolUse(tool string) {
+	today := time.Now().Format(time.DateOnly)
+	m.db.Model(&ToolUsage{}).Where("date = ? AND tool = ? AND count > 0", today, tool).
+		UpdateColumn("count", gorm.Expr("count - 1"))
+}
+
+// GetToolUsage 获取工具当天已使用的次数
+func (m *Manager) GetToolUsage(tool string) int {
+	var usage ToolUsage
+	m.db.Where("date = ? AND tool = ?", time.Now().Format(time.DateOnly), tool).Limit(1).Find(&usage)
+	return usage.Count
+}
@@ -2,6 +2,7 @@ package onebot
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"mumu-bot/internal/config"
 	"strconv"
@@ -28,6 +29,7 @@ type Client struct {
 
 	// 消息回调
 	onMessage func(*GroupMessage)
+	onPoke    func(groupID, userID int64) // 群内有人戳了戳自己
 
 	// 重连控制
 	reconnecting bool
@@ -78,8 +80,10 @@ type GroupMessage struct {
 	GroupID      int64            `json:"group_id"`
 	UserID       int64            `json:"user_id"`
 	Nickname     string           `json:"nickname"`
+	SenderRole   string           `json:"sender_role,omitempty"`   // 发送者群角色 owner/admin/member
 	Content      string           `json:"content"`                 // 纯文本内容
 	IsMentioned  bool             `json:"is_mentioned"`            // 是否@机器人
+	MentionAll   bool             `json:"mention_all,omitempty"`   // 是否@全体成员
 	Time         time.Time        `json:"time"`                    // 消息时间
 	MessageType  string           `json:"message_type"`            // 消息类型
 	Images       []ImageInfo      `json:"images,omitempty"`        // 图片列表
@@ -113,10 +117,24 @@ type FaceInfo struct {
 
 // ReplyInfo 回复信息
 type ReplyInfo struct {
-	MessageID int64  `json:"message_id"`
-	Content   string `json:"content,omitempty"`   // 被回复消息内容
-	SenderID  int64  `json:"sender_id,omitempty"` // 被回复消息发送者 ID
-	Nickname  string `json:"nickname,omitempty"`  // 被回复消息发送者昵称
+	MessageID int64       `json:"message_id"`
+	Content   string      `json:"content,omitempty"`   // 被回复消息内容
+	SenderID  int64       `json:"sender_id,omitempty"` // 被回复消息发送者 ID
+	Nickname  string      `json:"nickname,omitempty"`  // 被回复消息发送者昵称
+	Images    []ImageInfo `json:"images,omitempty"`    // 被回复消息中的图片
+}
+
+// HistoryMessage 群历史消息（只保留文本形式的内容）
+type HistoryMessage struct {
+	MessageID int64       `json:"message_id"`
+	GroupID   int64       `json:"group_id,omitempty"`
+	UserID    int64       `json:"user_id"`
+	Nickname  string      `json:"nickname"`
+	Time      time.Time   `json:"time"`
+	Content   string      `json:"content"`
+	Images    []ImageInfo `json:"images,omitempty"`
+
+	ForwardIDs []int64 `json:"-"` // 消息中合并转发的 ID，可通过 GetForwardMsg 展开
 }
 
 // ForwardMessage 合并转发中的单条消息
@@ -346,6 +364,28 @@ func (c *Client) handleNoticeEvent(event map[string]interface{}) {
 	if noticeType == "group_ban" {
 		c.handleGroupBanNotice(event, subType)
 	}
+	if noticeType == "notify" && subType == "poke" {
+		c.handlePokeNotice(event)
+	}
+}
+
+// handlePokeNotice 处理群内戳一戳通知，只关心戳自己的
+func (c *Client) handlePokeNotice(event map[string]interface{}) {
+	groupID, ok := parseInt64(event["group_id"])
+	if !ok || groupID == 0 {
+		return
+	}
+	targetID, ok := parseInt64(event["target_id"])
+	if !ok || targetID != c.selfID {
+		return
+	}
+	userID, ok := parseInt64(event["user_id"])
+	if !ok || userID == c.selfID {
+		return
+	}
+	if c.onPoke != nil {
+		c.onPoke(groupID, userID)
+	}
 }
 
 func (c *Client) handleGroupBanNotice(event map[string]interface{}, subType string) {
@@ -446,6 +486,9 @@ func (c *Client) parseGroupMessage(event map[string]interface{}) *GroupMessage {
 		if nickname, ok := sender["nickname"].(string); ok {
 			msg.Nickname = nickname
 		}
+		if role, ok := sender["role"].(string); ok {
+			msg.SenderRole = role
+		}
 	}
 
 	// 解析消息段，提取各类信息
@@ -462,6 +505,55 @@ func (c *Client) parseGroupMessage(event map[string]interface{}) *GroupMessage {
 	return msg
 }
 
+// parseImageSegment 解析图片消息段
+func parseImageSegment(data map[string]interface{}) (ImageInfo, bool) {
+	img := ImageInfo{}
+	if url, ok := data["url"].(string); ok {
+		img.URL = url
+	}
+	if file, ok := data["file"].(string); ok {
+		img.File = file
+	}
+	if summary, ok := data["summary"].(string); ok {
+		img.Summary = summary
+	}
+	if subType, ok := parseInt(data["sub_type"]); ok {
+		img.SubType = subType
+	}
+	return img, img.URL != "" || img.File != ""
+}
+
+// parseReplyImages 提取被回复消息中的图片（raw_message 只有 CQ 码，看不到图片内容）
+func parseReplyImages(replyData map[string]interface{}) []ImageInfo {
+	message, ok := replyData["message"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var images []ImageInfo
+	for _, seg := range message {
+		segMap, ok := seg.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := segMap["data"].(map[string]interface{})
+		if data == nil {
+			continue
+		}
+		switch segMap["type"] {
+		case "image":
+			if img, ok := parseImageSegment(data); ok {
+				images = append(images, img)
+			}
+		case "mface":
+			if url, ok := data["url"].(string); ok && url != "" {
+				summary, _ := data["summary"].(string)
+				images = append(images, ImageInfo{URL: url, Summary: summary, SubType: 1})
+			}
+		}
+	}
+	return images
+}
+
 // parseMessageSegments 解析消息段，填充消息各字段
 func (c *Client) parseMessageSegments(event map[string]interface{}, msg *GroupMessage) {
 	message, ok := event["message"].([]interface{})
@@ -493,20 +585,7 @@ func (c *Client) parseMessageSegments(event map[string]interface{}, msg *GroupMe
 			}
 
 		case "image":
-			img := ImageInfo{}
-			if url, ok := data["url"].(string); ok {
-				img.URL = url
-			}
-			if file, ok := data["file"].(string); ok {
-				img.File = file
-			}
-			if summary, ok := data["summary"].(string); ok {
-				img.Summary = summary
-			}
-			if subType, ok := parseInt(data["sub_type"]); ok {
-				img.SubType = subType
-			}
-			if img.URL != "" || img.File != "" {
+			if img, ok := parseImageSegment(data); ok {
 				msg.Images = append(msg.Images, img)
 			}
 
@@ -529,6 +608,7 @@ func (c *Client) parseMessageSegments(event map[string]interface{}, msg *GroupMe
 		case "at":
 			if qq, ok := data["qq"].(string); ok {
 				if qq == "all" {
+					msg.MentionAll = true
 					textParts = append(textParts, "@全体成员")
 				} else if qqID, err := strconv.ParseInt(qq, 10, 64); err == nil {
 					msg.AtList = append(msg.AtList, qqID)
@@ -555,6 +635,7 @@ func (c *Client) parseMessageSegments(event map[string]interface{}, msg *GroupMe
 							msg.Reply.Nickname = nick
 						}
 					}
+					msg.Reply.Images = parseReplyImages(replyData)
 				}
 			}
 
@@ -651,6 +732,11 @@ func (c *Client) OnMessage(handler func(*GroupMessage)) {
 	c.onMessage = handler
 }
 
+// OnPoke 设置被戳一戳的回调
+func (c *Client) OnPoke(handler func(groupID, userID int64)) {
+	c.onPoke = handler
+}
+
 // SendGroupMessage 发送群消息
 func (c *Client) SendGroupMessage(groupID int64, content string, replyTo int64, mentions []int64) (int64, error) {
 	// 使用消息段数组格式，更符合 OneBot 11 标准
@@ -911,6 +997,34 @@ func (c *Client) MarkMsgAsRead(messageID int64) error {
 	return err
 }
 
+// SetGroupBan 群禁言，duration 为禁言秒数，0 表示解除禁言
+func (c *Client) SetGroupBan(groupID, userID int64, duration int) error {
+	_, err := c.callAPI(context.Background(), "set_group_ban", map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+		"duration": duration,
+	})
+	return err
+}
+
+// SendLike 给用户的资料卡点赞，times 为本次点赞次数
+func (c *Client) SendLike(userID int64, times int) error {
+	_, err := c.callAPI(context.Background(), "send_like", map[string]interface{}{
+		"user_id": userID,
+		"times":   times,
+	})
+	return err
+}
+
+// ForwardGroupSingleMsg 把一条消息原样转发到指定群（NapCat / LLOneBot 扩展接口）
+func (c *Client) ForwardGroupSingleMsg(groupID, messageID int64) error {
+	_, err := c.callAPI(context.Background(), "forward_group_single_msg", map[string]interface{}{
+		"group_id":   groupID,
+		"message_id": messageID,
+	})
+	return err
+}
+
 // GroupPoke 群戳一戳
 func (c *Client) GroupPoke(groupID, userID int64) error {
 	_, err := c.callAPI(context.Background(), "group_poke", map[string]interface{}{
@@ -1178,6 +1292,97 @@ func (c *Client) GetForwardMsg(forwardID int64) ([]ForwardMessage, error) {
 	return parseForwardMessages(data), nil
 }
 
+// GetGroupMsgHistory 获取群历史消息（按时间从早到晚）
+// messageSeq 为起始消息 ID，0 表示从最新的消息开始往前取
+func (c *Client) GetGroupMsgHistory(groupID, messageSeq int64, count int) ([]HistoryMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	params := map[string]interface{}{
+		"group_id": groupID,
+		"count":    count,
+	}
+	if messageSeq != 0 {
+		params["message_seq"] = messageSeq
+	}
+	resp, err := c.callAPI(ctx, "get_group_msg_history", params)
+	if err != nil {
+		return nil, err
+	}
+	data := resp.DataMap()
+	if data == nil {
+		return nil, nil
+	}
+	rawMsgs, _ := data["messages"].([]interface{})
+
+	result := make([]HistoryMessage, 0, len(rawMsgs))
+	for _, item := range rawMsgs {
+		msgMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result = append(result, parseHistoryMessage(msgMap))
+	}
+	return result, nil
+}
+
+// GetMessageDetail 按消息 ID 获取单条消息（get_msg），内容只保留文本形式
+func (c *Client) GetMessageDetail(messageID int64) (*HistoryMessage, error) {
+	data, err := c.GetMsg(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("消息不存在")
+	}
+	hm := parseHistoryMessage(data)
+	return &hm, nil
+}
+
+// parseHistoryMessage 解析 get_msg / get_group_msg_history 返回的单条消息
+func parseHistoryMessage(msgMap map[string]interface{}) HistoryMessage {
+	hm := HistoryMessage{}
+	if gid, ok := parseInt64(msgMap["group_id"]); ok {
+		hm.GroupID = gid
+	}
+	if msgID, ok := parseInt64(msgMap["message_id"]); ok {
+		hm.MessageID = msgID
+	}
+	if sender, ok := msgMap["sender"].(map[string]interface{}); ok {
+		if uid, ok := parseInt64(sender["user_id"]); ok {
+			hm.UserID = uid
+		}
+		if card, ok := sender["card"].(string); ok && card != "" {
+			hm.Nickname = card
+		} else if nick, ok := sender["nickname"].(string); ok {
+			hm.Nickname = nick
+		}
+	}
+	if t, ok := parseInt64(msgMap["time"]); ok {
+		hm.Time = time.Unix(t, 0)
+	}
+	if segs, ok := msgMap["message"].([]interface{}); ok {
+		hm.Content = extractTextFromSegments(segs)
+		for _, seg := range segs {
+			segMap, _ := seg.(map[string]interface{})
+			data, _ := segMap["data"].(map[string]interface{})
+			if segMap["type"] != "forward" || data == nil {
+				continue
+			}
+			if forwardID, ok := parseInt64(data["id"]); ok && forwardID != 0 {
+				hm.ForwardIDs = append(hm.ForwardIDs, forwardID)
+			}
+		}
+	} else if raw, ok := msgMap["raw_message"].(string); ok {
+		hm.Content = raw
+	}
+	if hm.Content == "" {
+		hm.Content = "[消息]"
+	}
+	hm.Images = parseReplyImages(msgMap)
+	return hm
+}
+
 func parseForwardMessages(data map[string]interface{}) []ForwardMessage {
 	var rawMsgs []interface{}
 	if msgs, ok := data["messages"].([]interface{}); ok {
@@ -1335,6 +1540,89 @@ func (c *Client) SendImageMessage(groupID int64, filePath string, isSticker bool
 	return 0, nil
 }
 
+// SendImageData 发送图片消息，data 为图片数据（以 base64 传给 OneBot）
+func (c *Client) SendImageData(groupID int64, data []byte) (int64, error) {
+	message := []map[string]interface{}{
+		{
+			"type": "image",
+			"data": map[string]interface{}{
+				"file": "base64://" + base64.StdEncoding.EncodeToString(data),
+			},
+		},
+	}
+
+	resp, err := c.callAPI(context.Background(), "send_group_msg", map[string]interface{}{
+		"group_id": groupID,
+		"message":  message,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if data := resp.DataMap(); data != nil {
+		if msgID, ok := parseInt64(data["message_id"]); ok {
+			return msgID, nil
+		}
+	}
+	return 0, nil
+}
+
+// SendGroupForwardMsg 发送合并转发消息，每个节点以指定的 QQ 号和昵称显示
+func (c *Client) SendGroupForwardMsg(groupID int64, nodes []ForwardMessage) (int64, error) {
+	messages := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		messages = append(messages, map[string]interface{}{
+			"type": "node",
+			"data": map[string]interface{}{
+				"user_id":  strconv.FormatInt(node.UserID, 10),
+				"nickname": node.Nickname,
+				"content": []map[string]interface{}{
+					{"type": "text", "data": map[string]interface{}{"text": node.Content}},
+				},
+			},
+		})
+	}
+
+	resp, err := c.callAPI(context.Background(), "send_group_forward_msg", map[string]interface{}{
+		"group_id": groupID,
+		"messages": messages,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if data := resp.DataMap(); data != nil {
+		if msgID, ok := parseInt64(data["message_id"]); ok {
+			return msgID, nil
+		}
+	}
+	return 0, nil
+}
+
+// SendRecordMessage 发送语音消息，audio 为音频数据（以 base64 传给 OneBot，不依赖共享文件目录）
+func (c *Client) SendRecordMessage(groupID int64, audio []byte) (int64, error) {
+	message := []map[string]interface{}{
+		{
+			"type": "record",
+			"data": map[string]interface{}{
+				"file": "base64://" + base64.StdEncoding.EncodeToString(audio),
+			},
+		},
+	}
+
+	resp, err := c.callAPI(context.Background(), "send_group_msg", map[string]interface{}{
+		"group_id": groupID,
+		"message":  message,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if data := resp.DataMap(); data != nil {
+		if msgID, ok := parseInt64(data["message_id"]); ok {
+			return msgID, nil
+		}
+	}
+	return 0, nil
+}
+
 // 助手函数
 func parseInt64(v interface{}) (int64, bool) {
 	if v == nil {
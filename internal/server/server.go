@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mumu-bot/internal/agent"
 	"mumu-bot/internal/config"
 	"mumu-bot/internal/memory"
 	"net/http"
@@ -11,20 +13,23 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // Server HTTP服务
 type Server struct {
 	cfg       *config.Config
 	memoryMgr *memory.Manager
+	agent     *agent.Agent
 	server    *http.Server
 }
 
 // NewServer 创建HTTP服务
-func NewServer(cfg *config.Config, memoryMgr *memory.Manager) *Server {
+func NewServer(cfg *config.Config, memoryMgr *memory.Manager, a *agent.Agent) *Server {
 	return &Server{
 		cfg:       cfg,
 		memoryMgr: memoryMgr,
+		agent:     a,
 	}
 }
 
@@ -39,14 +44,28 @@ func (s *Server) Start() {
 	// 健康检查
 	r.GET("/health", s.healthCheck)
 
+	// 实时事件流
+	r.GET("/ws", s.streamEvents)
+
 	// API 路由
 	api := r.Group("/api")
 	{
 		// 记忆相关
 		api.GET("/memories", s.listMemories)
+		api.GET("/memories/stats", s.getMemoryStats)
 		api.GET("/memories/:id", s.getMemory)
+		api.PUT("/memories/:id", s.updateMemory)
 		api.DELETE("/memories/:id", s.deleteMemory)
 
+		// 人格资产导出/导入
+		api.GET("/export", s.exportBackup)
+		api.POST("/import", s.importBackup)
+		api.GET("/backups", s.listBackups)
+		api.POST("/backups", s.runBackup)
+
+		// 表情包
+		api.DELETE("/stickers/:id", s.deleteSticker)
+
 		// 成员画像
 		api.GET("/members", s.listMembers)
 		api.GET("/members/:user_id", s.getMember)
@@ -54,11 +73,32 @@ func (s *Server) Start() {
 		// 消息记录
 		api.GET("/messages", s.listMessages)
 
+		// 话题摘要
+		api.GET("/summaries", s.listSummaries)
+
+		// 决策记录
+		api.GET("/decisions", s.listDecisions)
+
 		// 统计信息
 		api.GET("/stats", s.getStats)
+		api.GET("/usage", s.getTokenUsage)
+		api.GET("/tools/stats", s.getToolStats)
+		api.GET("/tools/calls", s.listToolCalls)
 
 		// 状态
 		api.GET("/status", s.getStatus)
+
+		// 运行时配置
+		api.GET("/config", s.getConfig)
+		api.PATCH("/config", s.patchConfig)
+
+		// 群控制
+		api.POST("/groups/:id/pause", s.pauseGroup)
+		api.POST("/groups/:id/resume", s.resumeGroup)
+		api.POST("/groups/:id/think", s.thinkGroup)
+		api.GET("/groups/discovered", s.listDiscoveredGroups)
+		api.POST("/groups/:id/enable", s.enableGroup)
+		api.DELETE("/groups/:id/data", s.purgeGroup)
 	}
 
 	addr := fmt.Sprintf("%s:%d", s.cfg.Server.Host, s.cfg.Server.Port)
@@ -124,6 +164,16 @@ func (s *Server) listMemories(c *gin.Context) {
 	})
 }
 
+// getMemoryStats 查看各群的记忆占用与配额
+func (s *Server) getMemoryStats(c *gin.Context) {
+	stats, err := s.memoryMgr.GetGroupMemoryStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
 // getMemory 获取单个记忆
 func (s *Server) getMemory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -138,6 +188,48 @@ func (s *Server) getMemory(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"data": mem, "sources": s.memoryMgr.GetSourceMessages(&mem)})
+}
+
+// updateMemory 编辑记忆的内容、重要性或类型
+func (s *Server) updateMemory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 ID"})
+		return
+	}
+
+	var edit memory.MemoryEdit
+	if err := c.ShouldBindJSON(&edit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if edit.Content != nil && *edit.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "内容不能为空"})
+		return
+	}
+	if edit.Importance != nil && (*edit.Importance < 0 || *edit.Importance > 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "重要性应在 0-1 之间"})
+		return
+	}
+	if edit.Type != nil {
+		switch *edit.Type {
+		case memory.MemoryTypeGroupFact, memory.MemoryTypeSelfExperience, memory.MemoryTypeConversation, memory.MemoryTypeTopicSummary:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记忆类型"})
+			return
+		}
+	}
+
+	mem, err := s.memoryMgr.EditMemory(c.Request.Context(), uint(id), 0, &edit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记忆不存在"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": mem})
 }
 
@@ -149,7 +241,32 @@ func (s *Server) deleteMemory(c *gin.Context) {
 		return
 	}
 
-	if err := s.memoryMgr.GetDB().Delete(&memory.Memory{}, id).Error; err != nil {
+	reason := c.DefaultQuery("reason", "管理员通过 API 删除")
+	_, err = s.memoryMgr.DeleteMemory(c.Request.Context(), uint(id), 0, reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "记忆不存在"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// deleteSticker 删除表情包（记录和文件）
+func (s *Server) deleteSticker(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 ID"})
+		return
+	}
+
+	_, err = s.memoryMgr.DeleteSticker(uint(id), 0)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "表情包不存在"})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -157,6 +274,54 @@ func (s *Server) deleteMemory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
 }
 
+// exportBackup 导出记忆、黑话、表达方式、成员画像
+func (s *Server) exportBackup(c *gin.Context) {
+	backup, err := s.memoryMgr.Export()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	filename := fmt.Sprintf("mumu-backup-%s.json", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.JSON(http.StatusOK, backup)
+}
+
+// importBackup 从导出的 JSON 导入，记忆会重新生成向量
+func (s *Server) importBackup(c *gin.Context) {
+	var backup memory.Backup
+	if err := c.ShouldBindJSON(&backup); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的备份数据: " + err.Error()})
+		return
+	}
+
+	result, err := s.memoryMgr.Import(c.Request.Context(), &backup)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// listBackups 列出备份目录中的备份
+func (s *Server) listBackups(c *gin.Context) {
+	files, err := s.memoryMgr.ListBackups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": files})
+}
+
+// runBackup 立即执行一次备份
+func (s *Server) runBackup(c *gin.Context) {
+	name, err := s.memoryMgr.RunBackup()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"name": name}})
+}
+
 // listMembers 列出成员画像
 func (s *Server) listMembers(c *gin.Context) {
 	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
@@ -176,7 +341,7 @@ func (s *Server) listMembers(c *gin.Context) {
 	})
 }
 
-// getMember 获取单个成员画像
+// getMember 获取单个成员的全局档案，指定 group_id 时同时返回群内画像
 func (s *Server) getMember(c *gin.Context) {
 	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
 	if err != nil {
@@ -186,18 +351,23 @@ func (s *Server) getMember(c *gin.Context) {
 
 	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
 
-	var profile memory.MemberProfile
-	query := s.memoryMgr.GetDB().Where("user_id = ?", userID)
-	if groupID > 0 {
-		query = query.Where("group_id = ?", groupID)
-	}
-
-	if err := query.First(&profile).Error; err != nil {
+	profile, err := s.memoryMgr.GetUserProfile(userID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "成员不存在"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": profile})
+	data := gin.H{"profile": profile}
+	if groupID > 0 {
+		member, err := s.memoryMgr.GetMemberProfile(groupID, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "该成员不在此群"})
+			return
+		}
+		data["member"] = member
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
 }
 
 // listMessages 列出消息记录
@@ -219,22 +389,185 @@ func (s *Server) listMessages(c *gin.Context) {
 	})
 }
 
+// listSummaries 列出话题摘要
+func (s *Server) listSummaries(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
+	page, pageSize := parsePageParams(c)
+
+	summaries, total, err := s.memoryMgr.ListTopicSummaries(groupID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      summaries,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// listDecisions 列出思考决策记录（可按群和动作筛选）
+func (s *Server) listDecisions(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
+	action := c.Query("action")
+	page, pageSize := parsePageParams(c)
+
+	logs, total, err := s.memoryMgr.ListDecisionLogs(groupID, action, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      logs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
 // getStats 获取统计信息
 func (s *Server) getStats(c *gin.Context) {
 	stats := s.memoryMgr.GetStats()
 	c.JSON(http.StatusOK, gin.H{"data": stats})
 }
 
+// getTokenUsage 获取某天的 token 用量（默认今天）
+func (s *Server) getTokenUsage(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
+	date := c.DefaultQuery("date", time.Now().Format(time.DateOnly))
+
+	total, err := s.memoryMgr.GetDailyTokenUsage(date, groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"date":         date,
+		"group_id":     groupID,
+		"total_tokens": total,
+	}})
+}
+
+// getToolStats 获取各工具的执行耗时、慢调用和超时统计（进程启动以来）
+func (s *Server) getToolStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": s.agent.ToolStats()})
+}
+
+// listToolCalls 列出工具调用记录（可按群、工具名和是否成功筛选）
+func (s *Server) listToolCalls(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
+	toolName := c.Query("tool")
+	var success *bool
+	if v, err := strconv.ParseBool(c.Query("success")); err == nil {
+		success = &v
+	}
+	page, pageSize := parsePageParams(c)
+
+	logs, total, err := s.memoryMgr.ListToolCallLogs(groupID, toolName, success, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      logs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// getConfig 获取当前生效的配置（密钥已脱敏）
+func (s *Server) getConfig(c *gin.Context) {
+	data, err := s.cfg.Masked()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// configPatchRequest 运行时配置修改请求
+type configPatchRequest struct {
+	config.RuntimePatch
+	Groups []struct {
+		GroupID int64 `json:"group_id"`
+		Enabled bool  `json:"enabled"`
+	} `json:"groups"` // 群开关
+}
+
+// patchConfig 修改可热更新的配置（talk_frequency、speak_cooldown、群开关），立即生效，不写回配置文件
+func (s *Server) patchConfig(c *gin.Context) {
+	var req configPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求格式错误"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, g := range req.Groups {
+		if g.GroupID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+			return
+		}
+	}
+
+	if err := s.cfg.ApplyRuntimePatch(&req.RuntimePatch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 群开关逐个处理，已经是目标状态的群不算失败
+	groupErrors := make(map[int64]string)
+	for _, g := range req.Groups {
+		if g.Enabled == s.cfg.IsGroupEnabled(g.GroupID) {
+			continue
+		}
+		var err error
+		if g.Enabled {
+			err = s.agent.EnableGroup(g.GroupID)
+		} else {
+			err = s.agent.DisableGroup(g.GroupID)
+		}
+		if err != nil {
+			groupErrors[g.GroupID] = err.Error()
+		}
+	}
+	zap.L().Info("运行时配置已修改", zap.Any("talk_frequency", req.TalkFrequency), zap.Int("groups", len(req.Groups)))
+
+	resp := gin.H{"message": "已更新"}
+	if len(groupErrors) > 0 {
+		resp["group_errors"] = groupErrors
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // getStatus 获取状态
 func (s *Server) getStatus(c *gin.Context) {
 	stats := s.memoryMgr.GetStats()
 
+	// 各群当前有效的发言冷却（秒）
+	groups := s.cfg.GetGroups()
+	cooldowns := make(map[int64]float64, len(groups))
+	for _, gc := range groups {
+		if gc.Enabled {
+			cooldowns[gc.GroupID] = s.agent.EffectiveCooldown(gc.GroupID).Seconds()
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "running",
-		"persona": s.cfg.Persona.Name,
-		"groups":  len(s.cfg.Groups),
-		"uptime":  time.Now().Format(time.RFC3339),
-		"stats":   stats,
+		"status":    "running",
+		"persona":   s.cfg.Persona.Name,
+		"groups":    len(groups),
+		"paused":    s.agent.PausedGroups(),
+		"cooldowns": cooldowns,
+		"uptime":    time.Now().Format(time.RFC3339),
+		"stats":     stats,
 		"config": gin.H{
 			"think_interval": s.cfg.Agent.ThinkInterval,
 			"observe_window": s.cfg.Agent.ObserveWindow,
@@ -242,3 +575,103 @@ func (s *Server) getStatus(c *gin.Context) {
 		},
 	})
 }
+
+// pauseGroup 暂停群内发言，可通过 minutes 参数指定时长
+func (s *Server) pauseGroup(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+		return
+	}
+	minutes, _ := strconv.Atoi(c.DefaultQuery("minutes", "0"))
+
+	s.agent.PauseFor(groupID, time.Duration(minutes)*time.Minute)
+	c.JSON(http.StatusOK, gin.H{"message": "已暂停"})
+}
+
+// resumeGroup 恢复群内发言
+func (s *Server) resumeGroup(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+		return
+	}
+
+	s.agent.Resume(groupID)
+	c.JSON(http.StatusOK, gin.H{"message": "已恢复"})
+}
+
+// thinkGroup 立即对群执行一次思考，force=skip_cooldown 时忽略发言冷却
+func (s *Server) thinkGroup(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+		return
+	}
+	skipCooldown := c.Query("force") == "skip_cooldown"
+
+	if err := s.agent.ThinkNow(groupID, skipCooldown); err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, agent.ErrGroupNotEnabled) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "已触发思考"})
+}
+
+// listDiscoveredGroups 列出收到过消息但未启用的群
+func (s *Server) listDiscoveredGroups(c *gin.Context) {
+	groups, err := s.memoryMgr.ListDiscoveredGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// 配置文件里启用的群也会写入群信息（enabled 仍为 false），这里排除掉
+	discovered := make([]memory.GroupInfo, 0, len(groups))
+	for _, g := range groups {
+		if !s.cfg.IsGroupEnabled(g.GroupID) {
+			discovered = append(discovered, g)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": discovered})
+}
+
+// enableGroup 启用群并执行初次加入流程
+func (s *Server) enableGroup(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+		return
+	}
+
+	if err := s.agent.EnableGroup(groupID); err != nil {
+		if errors.Is(err, agent.ErrGroupAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已启用"})
+}
+
+// purgeGroup 删除群的全部数据，群必须已经停用
+func (s *Server) purgeGroup(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的群 ID"})
+		return
+	}
+	result, err := s.memoryMgr.PurgeGroup(c.Request.Context(), groupID)
+	if errors.Is(err, memory.ErrGroupStillEnabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+	wsPongTimeout  = 60 * time.Second
+)
+
+// wsUpgrader 创建 WebSocket 升级器：浏览器不对 WebSocket 做同源限制，
+// 这里只允许同源、配置中列出的来源，以及不带 Origin 的非浏览器客户端
+func (s *Server) wsUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			if slices.Contains(s.cfg.Server.WSAllowedOrigins, origin) {
+				return true
+			}
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		},
+	}
+}
+
+// isLoopbackHost 监听地址是否只在本机可访问
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkWSToken 校验 /ws 的访问令牌；未配置令牌时只允许监听本机地址
+func (s *Server) checkWSToken(c *gin.Context) bool {
+	token := s.cfg.Server.WSToken
+	if token == "" {
+		return isLoopbackHost(s.cfg.Server.Host)
+	}
+	got := c.Query("token")
+	if got == "" {
+		got = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// streamEvents 通过 WebSocket 实时推送事件（新消息、发言、工具调用、决策）
+// 可选参数：group_id 只推送某个群，types 逗号分隔的事件类型
+func (s *Server) streamEvents(c *gin.Context) {
+	if !s.checkWSToken(c) {
+		if s.cfg.Server.WSToken == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "监听非本机地址时需要配置 server.ws_token"})
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "令牌无效"})
+		}
+		return
+	}
+
+	groupID, _ := strconv.ParseInt(c.DefaultQuery("group_id", "0"), 10, 64)
+	var types []string
+	if v := c.Query("types"); v != "" {
+		types = strings.Split(v, ",")
+	}
+
+	conn, err := s.wsUpgrader().Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		zap.L().Warn("WebSocket 升级失败", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.agent.Events().Subscribe()
+	defer unsubscribe()
+
+	// 读循环只处理 pong 和关闭，客户端断开后通知写循环退出
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	zap.L().Info("事件流客户端已连接", zap.String("remote", c.Request.RemoteAddr))
+
+	for {
+		select {
+		case <-done:
+			zap.L().Info("事件流客户端已断开", zap.String("remote", c.Request.RemoteAddr))
+			return
+		case e := <-events:
+			if groupID != 0 && e.GroupID != groupID {
+				continue
+			}
+			if len(types) > 0 && !slices.Contains(types, e.Type) {
+				continue
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"go.uber.org/zap"
+)
+
+// defaultToolCacheTTLs 未配置时使用的结果缓存时间（秒），只读且短时间内变化不大的查询工具
+var defaultToolCacheTTLs = map[string]int{
+	"getGroupInfo":            60,
+	"getGroupMemberDetail":    60,
+	"listGroupMembers":        60,
+	"getMemberInfo":           60,
+	"getGroupNotices":         300,
+	"getEssenceMessages":      300,
+	"getMessageDetail":        300,
+	"getForwardMessageDetail": 600,
+	"getWeather":              600,
+	"summarizeContent":        600,
+}
+
+// toolCacheInvalidations 写操作工具成功后需要清掉的同群查询缓存
+var toolCacheInvalidations = map[string][]string{
+	"updateGroupInfo":     {"getGroupInfo"},
+	"updateMemberProfile": {"getMemberInfo", "findMemberByName"},
+}
+
+// toolCachePruneSize 缓存条目超过该数量时顺带清理过期条目
+const toolCachePruneSize = 512
+
+type toolCacheKey struct {
+	tool    string
+	groupID int64
+	args    string
+}
+
+type toolCacheEntry struct {
+	result   string
+	expireAt time.Time
+}
+
+// ToolCache 查询类工具的结果缓存，按工具、群和参数缓存成功的结果
+type ToolCache struct {
+	ttls    map[string]int
+	mu      sync.Mutex
+	entries map[toolCacheKey]toolCacheEntry
+}
+
+// NewToolCache 创建工具结果缓存，ttls 为 nil 时使用内置默认值
+func NewToolCache(ttls map[string]int) *ToolCache {
+	if ttls == nil {
+		ttls = defaultToolCacheTTLs
+	}
+	return &ToolCache{ttls: ttls, entries: make(map[toolCacheKey]toolCacheEntry)}
+}
+
+func (c *ToolCache) get(key toolCacheKey, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if now.After(e.expireAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return e.result, true
+}
+
+func (c *ToolCache) set(key toolCacheKey, result string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= toolCachePruneSize {
+		for k, e := range c.entries {
+			if now.After(e.expireAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = toolCacheEntry{
+		result:   result,
+		expireAt: now.Add(time.Duration(c.ttls[key.tool]) * time.Second),
+	}
+}
+
+// invalidate 清掉某个群指定工具的缓存
+func (c *ToolCache) invalidate(groupID int64, toolNames []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.groupID == groupID && slices.Contains(toolNames, k.tool) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// cachedTool 包装查询工具，相同参数在缓存有效期内直接返回上次的结果
+type cachedTool struct {
+	tool.InvokableTool
+	name  string
+	cache *ToolCache
+}
+
+func (t *cachedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var groupID int64
+	if tc := GetToolContext(ctx); tc != nil {
+		groupID = tc.GroupID
+	}
+	key := toolCacheKey{tool: t.name, groupID: groupID, args: strings.TrimSpace(argumentsInJSON)}
+	if result, ok := t.cache.get(key, time.Now()); ok {
+		zap.L().Debug("工具结果命中缓存", zap.String("tool", t.name), zap.Int64("group_id", groupID))
+		return result, nil
+	}
+
+	result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	if err == nil && isSuccessResult(result) {
+		t.cache.set(key, result, time.Now())
+	}
+	return result, err
+}
+
+// invalidatingTool 包装写操作工具，执行成功后清掉相关查询的缓存
+type invalidatingTool struct {
+	tool.InvokableTool
+	targets []string
+	cache   *ToolCache
+}
+
+func (t *invalidatingTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	if err == nil && isSuccessResult(result) {
+		if tc := GetToolContext(ctx); tc != nil {
+			t.cache.invalidate(tc.GroupID, t.targets)
+		}
+	}
+	return result, err
+}
+
+// isSuccessResult 工具结果是否表示成功，失败的结果不缓存
+func isSuccessResult(result string) bool {
+	return !strings.Contains(result, `"success":false`)
+}
+
+// WithCache 为配置了缓存时间的查询工具加上结果缓存，为相关写操作工具加上缓存失效，其他工具原样返回
+func WithCache(t tool.BaseTool, cache *ToolCache) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok || cache == nil {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return t
+	}
+	if ttl := cache.ttls[info.Name]; ttl > 0 {
+		return &cachedTool{InvokableTool: it, name: info.Name, cache: cache}
+	}
+	if targets, ok := toolCacheInvalidations[info.Name]; ok {
+		return &invalidatingTool{InvokableTool: it, targets: targets, cache: cache}
+	}
+	return t
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	mutils "mumu-bot/internal/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 计算器工具 ====================
+
+type CalculateInput struct {
+	Expression string `json:"expression" jsonschema:"description=数学表达式，支持 + - * / % ^、括号、pi、e 和 sqrt abs sin cos tan ln log exp floor ceil round 等函数，如 (12.5+8)*3/4"`
+	Precision  *int   `json:"precision,omitempty" jsonschema:"description=保留几位小数（0~15），不填则自动"`
+}
+
+type CalculateOutput struct {
+	Success bool   `json:"success"`
+	Result  string `json:"result,omitempty"`
+	Text    string `json:"text,omitempty"` // 可以直接转述的结果
+	Message string `json:"message,omitempty"`
+}
+
+func calculateFunc(_ context.Context, input *CalculateInput) (*CalculateOutput, error) {
+	expr := strings.TrimSpace(input.Expression)
+	if expr == "" {
+		return &CalculateOutput{Success: false, Message: "表达式不能为空"}, nil
+	}
+
+	v, err := mutils.EvalExpr(expr)
+	if err != nil {
+		output := &CalculateOutput{Success: false, Message: "算不了: " + err.Error()}
+		LogToolCall("calculate", input, output, nil)
+		return output, nil
+	}
+
+	var result string
+	if input.Precision != nil {
+		result = strconv.FormatFloat(v, 'f', min(max(*input.Precision, 0), 15), 64)
+	} else {
+		// 自动精度：去掉浮点误差带来的长尾（如 0.1+0.2）
+		result = strconv.FormatFloat(v, 'g', 12, 64)
+		if !strings.ContainsAny(result, "e") {
+			f, _ := strconv.ParseFloat(result, 64)
+			result = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+
+	output := &CalculateOutput{Success: true, Result: result, Text: expr + " = " + result}
+	LogToolCall("calculate", input, output, nil)
+	return output, nil
+}
+
+func NewCalculateTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"calculate",
+		"精确计算数学表达式。算账、换算、算比例等需要具体数字时使用，不要自己心算。",
+		calculateFunc,
+	)
+}
@@ -216,6 +216,7 @@ func searchExpressionsFunc(ctx context.Context, input *SearchExpressionsInput) (
 			"meaning": j.Style,
 			"context": j.Examples,
 			"checked": j.Checked,
+			"uses":    j.Count,
 		})
 	}
 
@@ -231,7 +232,7 @@ func searchExpressionsFunc(ctx context.Context, input *SearchExpressionsInput) (
 func NewSearchExpressionsTool() (tool.InvokableTool, error) {
 	return utils.InferTool(
 		"searchExpressions",
-		"搜索你从群友学到的表达方式和口头禅。",
+		"搜索你从群友学到的表达方式和口头禅。模仿某条表达发言时，在 speak 中带上它的 expression_id。",
 		searchExpressionsFunc,
 	)
 }
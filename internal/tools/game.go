@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"mumu-bot/internal/game"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 开始游戏工具 ====================
+
+// StartGameInput 开始游戏的输入参数
+type StartGameInput struct {
+	// Type 游戏类型
+	Type string `json:"type" jsonschema:"enum=guess_number,enum=idiom_chain,enum=quiz,description=游戏类型：guess_number=猜数字(1-100)、idiom_chain=成语接龙、quiz=你出题大家答"`
+	// Question quiz 的题目
+	Question string `json:"question,omitempty" jsonschema:"description=quiz 类型必填：题目"`
+	// Answer quiz 的答案
+	Answer string `json:"answer,omitempty" jsonschema:"description=quiz 类型必填：标准答案（不会告诉群友）"`
+	// First 成语接龙的起始成语
+	First string `json:"first,omitempty" jsonschema:"description=idiom_chain 类型可选：起始成语，不填则随机"`
+}
+
+// GameOutput 游戏工具的输出
+type GameOutput struct {
+	Success  bool   `json:"success"`
+	Correct  bool   `json:"correct,omitempty"`
+	Finished bool   `json:"finished,omitempty"`
+	Message  string `json:"message"`
+}
+
+// startGameFunc 开始游戏的实际实现
+func startGameFunc(ctx context.Context, input *StartGameInput) (*GameOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.Games == nil {
+		return &GameOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	intro, err := tc.Games.Start(tc.GroupID, input.Type, game.StartOptions{
+		Question: input.Question,
+		Answer:   input.Answer,
+		First:    input.First,
+	})
+	if err != nil {
+		output := &GameOutput{Success: false, Message: err.Error()}
+		LogToolCall("startGame", input, output, err)
+		return output, nil
+	}
+
+	output := &GameOutput{Success: true, Message: "游戏开始了，用 speak 告诉大家玩法和题面：" + intro}
+	LogToolCall("startGame", input, output, nil)
+	return output, nil
+}
+
+// NewStartGameTool 创建开始游戏工具
+func NewStartGameTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"startGame",
+		`在群里开一个小游戏（猜数字、成语接龙、你出题大家答）。
+- 群友想玩游戏、或者群里很无聊时可以使用
+- 每个群同时只能有一个游戏，开始后用 speak 告诉大家怎么玩`,
+		startGameFunc,
+	)
+}
+
+// ==================== 游戏作答工具 ====================
+
+// AnswerGameInput 游戏作答的输入参数
+type AnswerGameInput struct {
+	// UserID 作答群友的QQ号
+	UserID int64 `json:"user_id" jsonschema:"description=作答群友的QQ号"`
+	// Answer 群友的答案
+	Answer string `json:"answer" jsonschema:"description=群友的答案（猜数字填数字，成语接龙填成语）"`
+}
+
+// answerGameFunc 游戏作答的实际实现
+func answerGameFunc(ctx context.Context, input *AnswerGameInput) (*GameOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.Games == nil {
+		return &GameOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.UserID == 0 || input.Answer == "" {
+		return &GameOutput{Success: false, Message: "用户 ID 和答案不能为空"}, nil
+	}
+
+	res, err := tc.Games.Answer(tc.GroupID, input.UserID, input.Answer)
+	if err != nil {
+		output := &GameOutput{Success: false, Message: err.Error()}
+		LogToolCall("answerGame", input, output, err)
+		return output, nil
+	}
+
+	output := &GameOutput{Success: true, Correct: res.Correct, Finished: res.Finished, Message: res.Message}
+	LogToolCall("answerGame", input, output, nil)
+	return output, nil
+}
+
+// NewAnswerGameTool 创建游戏作答工具
+func NewAnswerGameTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"answerGame",
+		`判定群友在游戏中的作答，群里有游戏进行中且有人作答时使用。
+- 每条作答调用一次，然后用 speak 把结果告诉大家
+- 成语接龙只检查接龙规则，如果群友接的根本不是成语，直接告诉 TA 不算，不要调用本工具`,
+		answerGameFunc,
+	)
+}
+
+// ==================== 结束游戏工具 ====================
+
+// StopGameInput 结束游戏的输入参数
+type StopGameInput struct{}
+
+// stopGameFunc 结束游戏的实际实现
+func stopGameFunc(ctx context.Context, input *StopGameInput) (*GameOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.Games == nil {
+		return &GameOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	if err := tc.Games.Stop(tc.GroupID); err != nil {
+		output := &GameOutput{Success: false, Message: err.Error()}
+		LogToolCall("stopGame", input, output, err)
+		return output, nil
+	}
+
+	output := &GameOutput{Success: true, Message: "游戏已结束"}
+	LogToolCall("stopGame", input, output, nil)
+	return output, nil
+}
+
+// NewStopGameTool 创建结束游戏工具
+func NewStopGameTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"stopGame",
+		"结束群里进行中的游戏。大家不想玩了、或者游戏卡住没人玩时使用。",
+		stopGameFunc,
+	)
+}
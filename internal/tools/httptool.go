@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ==================== 基于配置的 HTTP 工具 ====================
+
+// HTTPToolSpec 插件目录中单个 HTTP 工具的描述文件（yaml 或 json）
+type HTTPToolSpec struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Method      string            `yaml:"method"`  // GET 或 POST，默认 GET
+	URL         string            `yaml:"url"`     // 可包含 {参数名} 占位符，调用时替换为参数值
+	Headers     map[string]string `yaml:"headers"` // 值中的 ${ENV} 会替换为环境变量
+	Params      []HTTPToolParam   `yaml:"params"`
+	Timeout     int               `yaml:"timeout"`      // 请求超时（秒），默认 15
+	MaxResponse int               `yaml:"max_response"` // 返回给 LLM 的响应最大字符数，默认 4000
+}
+
+// HTTPToolParam HTTP 工具参数，未在 URL 占位符中使用的参数 GET 时放进查询串，POST 时作为 JSON 请求体
+type HTTPToolParam struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // string、integer、number、boolean，默认 string
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Enum        []string `yaml:"enum"`
+}
+
+// HTTPToolOutput HTTP 工具的输出
+type HTTPToolOutput struct {
+	Success bool   `json:"success"`
+	Status  int    `json:"status,omitempty"`
+	Data    string `json:"data,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+var (
+	httpToolNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+	urlPlaceholder      = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+)
+
+type httpTool struct {
+	spec   HTTPToolSpec
+	client *http.Client
+}
+
+// NewHTTPTool 根据描述创建 HTTP 工具
+func NewHTTPTool(spec HTTPToolSpec) (tool.InvokableTool, error) {
+	if !httpToolNamePattern.MatchString(spec.Name) {
+		return nil, fmt.Errorf("工具名不合法: %q", spec.Name)
+	}
+	if spec.Description == "" {
+		return nil, fmt.Errorf("工具 %s 缺少 description", spec.Name)
+	}
+	spec.Method = strings.ToUpper(spec.Method)
+	switch spec.Method {
+	case "":
+		spec.Method = http.MethodGet
+	case http.MethodGet, http.MethodPost:
+	default:
+		return nil, fmt.Errorf("工具 %s 不支持的请求方法: %s", spec.Name, spec.Method)
+	}
+	if u, err := url.Parse(spec.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("工具 %s 的 url 不合法: %q", spec.Name, spec.URL)
+	}
+	for _, p := range spec.Params {
+		switch p.Type {
+		case "", "string", "integer", "number", "boolean":
+		default:
+			return nil, fmt.Errorf("工具 %s 参数 %s 不支持的类型: %s", spec.Name, p.Name, p.Type)
+		}
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = 15
+	}
+	if spec.MaxResponse <= 0 {
+		spec.MaxResponse = 4000
+	}
+	return &httpTool{spec: spec, client: &http.Client{Timeout: time.Duration(spec.Timeout) * time.Second}}, nil
+}
+
+func (t *httpTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	params := make(map[string]*schema.ParameterInfo, len(t.spec.Params))
+	for _, p := range t.spec.Params {
+		typ := schema.DataType(p.Type)
+		if typ == "" {
+			typ = schema.String
+		}
+		params[p.Name] = &schema.ParameterInfo{Type: typ, Desc: p.Description, Required: p.Required, Enum: p.Enum}
+	}
+	return &schema.ToolInfo{
+		Name:        t.spec.Name,
+		Desc:        t.spec.Description,
+		ParamsOneOf: schema.NewParamsOneOfByParams(params),
+	}, nil
+}
+
+func (t *httpTool) InvokableRun(ctx context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	output := t.run(ctx, argumentsInJSON)
+	LogToolCall(t.spec.Name, argumentsInJSON, output, nil)
+	return sonic.MarshalString(output)
+}
+
+func (t *httpTool) run(ctx context.Context, argumentsInJSON string) *HTTPToolOutput {
+	args := make(map[string]any)
+	if strings.TrimSpace(argumentsInJSON) != "" {
+		if err := sonic.UnmarshalString(argumentsInJSON, &args); err != nil {
+			return &HTTPToolOutput{Success: false, Message: "参数格式错误"}
+		}
+	}
+	for _, p := range t.spec.Params {
+		if _, ok := args[p.Name]; p.Required && !ok {
+			return &HTTPToolOutput{Success: false, Message: "缺少参数 " + p.Name}
+		}
+	}
+
+	// URL 占位符替换，用过的参数不再重复传
+	used := make(map[string]bool)
+	rawURL := urlPlaceholder.ReplaceAllStringFunc(t.spec.URL, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := args[name]
+		if !ok {
+			return m
+		}
+		used[name] = true
+		return url.PathEscape(fmt.Sprint(v))
+	})
+	rest := make(map[string]any)
+	for k, v := range args {
+		if !used[k] && slices.ContainsFunc(t.spec.Params, func(p HTTPToolParam) bool { return p.Name == k }) {
+			rest[k] = v
+		}
+	}
+
+	var body io.Reader
+	if t.spec.Method == http.MethodGet {
+		u, _ := url.Parse(rawURL)
+		q := u.Query()
+		for k, v := range rest {
+			q.Set(k, fmt.Sprint(v))
+		}
+		u.RawQuery = q.Encode()
+		rawURL = u.String()
+	} else {
+		data, _ := sonic.Marshal(rest)
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.spec.Method, rawURL, body)
+	if err != nil {
+		return &HTTPToolOutput{Success: false, Message: "构造请求失败"}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range t.spec.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		zap.L().Warn("HTTP 工具请求失败", zap.String("tool", t.spec.Name), zap.Error(err))
+		return &HTTPToolOutput{Success: false, Message: "请求失败，服务可能暂时不可用"}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return &HTTPToolOutput{Success: false, Status: resp.StatusCode, Message: "读取响应失败"}
+	}
+	text := []rune(strings.TrimSpace(string(data)))
+	if len(text) > t.spec.MaxResponse {
+		text = append(text[:t.spec.MaxResponse], []rune("...(已截断)")...)
+	}
+	return &HTTPToolOutput{
+		Success: resp.StatusCode >= 200 && resp.StatusCode < 300,
+		Status:  resp.StatusCode,
+		Data:    string(text),
+	}
+}
+
+// LoadHTTPTools 从插件目录加载所有 HTTP 工具描述文件（*.yaml、*.yml、*.json），
+// 单个文件有问题时跳过并记录日志，目录不存在时返回空
+func LoadHTTPTools(dir string) ([]tool.BaseTool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			zap.L().Debug("工具插件目录不存在，跳过加载", zap.String("dir", dir))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取工具插件目录失败: %w", err)
+	}
+
+	var result []tool.BaseTool
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			zap.L().Warn("读取工具插件失败", zap.String("file", path), zap.Error(err))
+			continue
+		}
+		var spec HTTPToolSpec
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			zap.L().Warn("解析工具插件失败", zap.String("file", path), zap.Error(err))
+			continue
+		}
+		t, err := NewHTTPTool(spec)
+		if err != nil {
+			zap.L().Warn("创建工具插件失败", zap.String("file", path), zap.Error(err))
+			continue
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
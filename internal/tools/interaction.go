@@ -2,10 +2,18 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/memory"
+	"mumu-bot/internal/onebot"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+	"go.uber.org/zap"
 )
 
 // ==================== 发言工具 ====================
@@ -18,6 +26,8 @@ type SpeakInput struct {
 	ReplyTo int64 `json:"reply_to,omitempty" jsonschema:"description=要回复的消息ID"`
 	// Mentions 要@的用户QQ号列表（可选）
 	Mentions []int64 `json:"mentions,omitempty" jsonschema:"description=要@的用户QQ号列表"`
+	// ExpressionID 模仿的表达方式ID（可选）
+	ExpressionID uint `json:"expression_id,omitempty" jsonschema:"description=如果这句话模仿了 searchExpressions 找到的某条表达方式，填写它的ID"`
 }
 
 // SpeakOutput 发言的输出
@@ -34,17 +44,33 @@ func speakFunc(ctx context.Context, input *SpeakInput) (*SpeakOutput, error) {
 	}
 
 	var msgID int64
+	var notice string
 	// 获取工具上下文
 	tc := GetToolContext(ctx)
 	if tc != nil && tc.SpeakCallback != nil {
 		// 通过回调发送消息，获取返回的消息ID
-		msgID = tc.SpeakCallback(tc.GroupID, input.Content, input.ReplyTo, input.Mentions)
+		id, err := tc.SpeakCallback(tc.GroupID, input.Content, input.ReplyTo, input.Mentions)
+		if errors.Is(err, ErrReplyTargetInvalid) {
+			// 回复目标无效但已降级发送成功
+			notice = "（" + err.Error() + "）"
+		} else if err != nil {
+			output := &SpeakOutput{Success: false, Message: err.Error()}
+			LogToolCall("speak", input, output, err)
+			return output, nil
+		}
+		msgID = id
+
+		if input.ExpressionID != 0 && tc.MemoryMgr != nil {
+			if err := tc.MemoryMgr.RecordExpressionUse(tc.GroupID, input.ExpressionID, msgID); err != nil {
+				zap.L().Warn("记录表达方式使用失败", zap.Uint("id", input.ExpressionID), zap.Error(err))
+			}
+		}
 	}
 
 	output := &SpeakOutput{
 		Success:   true,
 		MessageID: msgID,
-		Message:   fmt.Sprintf("发言成功，消息ID: %d", msgID),
+		Message:   fmt.Sprintf("发言成功，消息ID: %d%s", msgID, notice),
 	}
 	LogToolCall("speak", input, output, nil)
 	return output, nil
@@ -259,3 +285,387 @@ func NewRecallMessageTool() (tool.InvokableTool, error) {
 		recallMessageFunc,
 	)
 }
+
+// ==================== 禁言工具 ====================
+
+// MuteMemberInput 禁言的输入参数
+type MuteMemberInput struct {
+	// UserID 要禁言的群成员QQ号
+	UserID int64 `json:"user_id" jsonschema:"description=要禁言的群成员QQ号"`
+	// Minutes 禁言分钟数
+	Minutes int `json:"minutes" jsonschema:"description=禁言多少分钟，象征性的就好，一般1-5分钟"`
+	// Reason 禁言原因
+	Reason string `json:"reason" jsonschema:"description=禁言原因，例如：刷屏"`
+}
+
+// MuteMemberOutput 禁言的输出
+type MuteMemberOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// muteMemberFunc 禁言的实际实现
+func muteMemberFunc(ctx context.Context, input *MuteMemberInput) (*MuteMemberOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &MuteMemberOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Bot == nil {
+		return &MuteMemberOutput{Success: false, Message: "Bot 未连接"}, nil
+	}
+	if input.UserID == 0 {
+		return &MuteMemberOutput{Success: false, Message: "用户 ID 不能为空"}, nil
+	}
+
+	cfg := config.Get()
+	if gc := cfg.GetGroupConfig(tc.GroupID); gc == nil || !gc.AllowMute {
+		return &MuteMemberOutput{Success: false, Message: "这个群没有授权你禁言别人"}, nil
+	}
+	if input.UserID == tc.Bot.GetSelfID() || slices.Contains(cfg.Chat.Mute.Protected, input.UserID) ||
+		slices.Contains(cfg.Command.Admins, input.UserID) {
+		output := &MuteMemberOutput{Success: false, Message: "这个人不能禁言"}
+		LogToolCall("muteMember", input, output, nil)
+		return output, nil
+	}
+
+	// 自己必须是管理员，且只能禁言普通成员
+	self, err := tc.Bot.GetGroupMemberInfo(tc.GroupID, tc.Bot.GetSelfID(), true)
+	if err != nil || (self.Role != "admin" && self.Role != "owner") {
+		output := &MuteMemberOutput{Success: false, Message: "你不是这个群的管理员，没法禁言"}
+		LogToolCall("muteMember", input, output, err)
+		return output, nil
+	}
+	target, err := tc.Bot.GetGroupMemberInfo(tc.GroupID, input.UserID, true)
+	if err != nil {
+		output := &MuteMemberOutput{Success: false, Message: "获取群成员信息失败"}
+		LogToolCall("muteMember", input, output, err)
+		return output, nil
+	}
+	if target.Role != "member" {
+		output := &MuteMemberOutput{Success: false, Message: "不能禁言群主或管理员"}
+		LogToolCall("muteMember", input, output, nil)
+		return output, nil
+	}
+
+	maxMinutes := cfg.Chat.Mute.MaxMinutes
+	if maxMinutes <= 0 {
+		maxMinutes = 10
+	}
+	minutes := min(max(input.Minutes, 1), maxMinutes)
+	if err := tc.Bot.SetGroupBan(tc.GroupID, input.UserID, minutes*60); err != nil {
+		output := &MuteMemberOutput{Success: false, Message: err.Error()}
+		LogToolCall("muteMember", input, output, err)
+		return output, nil
+	}
+
+	zap.L().Info("禁言群成员", zap.Int64("group_id", tc.GroupID), zap.Int64("user_id", input.UserID),
+		zap.Int("minutes", minutes), zap.String("reason", input.Reason))
+	output := &MuteMemberOutput{Success: true, Message: fmt.Sprintf("已禁言 %d 分钟", minutes)}
+	LogToolCall("muteMember", input, output, nil)
+	return output, nil
+}
+
+// NewMuteMemberTool 创建禁言工具
+func NewMuteMemberTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"muteMember",
+		"禁言某个群友一小会儿。只在有人恶意刷屏、反复骚扰时象征性地用一下，开玩笑和吵架不算。禁言前后最好用 speak 说明一下原因。只有群里授权且你是管理员时才能用。",
+		muteMemberFunc,
+	)
+}
+
+// ==================== 私聊工具 ====================
+
+// SendPrivateMessageInput 私聊的输入参数
+type SendPrivateMessageInput struct {
+	// UserID 要私聊的群友QQ号
+	UserID int64 `json:"user_id" jsonschema:"description=要私聊的群友QQ号"`
+	// Content 私聊内容
+	Content string `json:"content" jsonschema:"description=私聊内容，口语化，不要用markdown"`
+}
+
+// SendPrivateMessageOutput 私聊的输出
+type SendPrivateMessageOutput struct {
+	Success   bool   `json:"success"`
+	MessageID int64  `json:"message_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+// sendPrivateMessageFunc 私聊的实际实现
+func sendPrivateMessageFunc(ctx context.Context, input *SendPrivateMessageInput) (*SendPrivateMessageOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.PrivateCallback == nil {
+		return &SendPrivateMessageOutput{Success: false, Message: "私聊功能未启用"}, nil
+	}
+	if input.UserID == 0 {
+		return &SendPrivateMessageOutput{Success: false, Message: "用户 ID 不能为空"}, nil
+	}
+	if input.Content == "" {
+		return &SendPrivateMessageOutput{Success: false, Message: "私聊内容不能为空"}, nil
+	}
+
+	// 只能私聊足够熟的人
+	cfg := config.Get().Chat.PrivateMessage
+	minIntimacy := cfg.MinIntimacy
+	if minIntimacy <= 0 {
+		minIntimacy = 0.6
+	}
+	profile, err := tc.MemoryMgr.GetUserProfile(input.UserID)
+	if err != nil || profile.Intimacy < minIntimacy {
+		output := &SendPrivateMessageOutput{Success: false, Message: "你和 TA 还没熟到可以私聊的程度，在群里说吧"}
+		LogToolCall("sendPrivateMessage", input, output, nil)
+		return output, nil
+	}
+
+	dailyLimit := cfg.DailyLimit
+	if dailyLimit == 0 {
+		dailyLimit = 10
+	}
+	ok, err := tc.MemoryMgr.TryUseTool("sendPrivateMessage", dailyLimit)
+	if err != nil || !ok {
+		output := &SendPrivateMessageOutput{Success: false, Message: "今天私聊得够多了，在群里说吧"}
+		LogToolCall("sendPrivateMessage", input, output, err)
+		return output, nil
+	}
+
+	msgID, err := tc.PrivateCallback(tc.GroupID, input.UserID, input.Content)
+	if err != nil {
+		tc.MemoryMgr.RefundToolUse("sendPrivateMessage")
+		output := &SendPrivateMessageOutput{Success: false, Message: err.Error()}
+		LogToolCall("sendPrivateMessage", input, output, err)
+		return output, nil
+	}
+
+	// 私聊内容不会出现在群消息里，写入记忆免得之后忘了说过什么
+	mem := &memory.Memory{
+		Type:       memory.MemoryTypeConversation,
+		GroupID:    tc.GroupID,
+		UserID:     input.UserID,
+		Content:    fmt.Sprintf("私聊对 %s 说：%s", profile.Nickname, input.Content),
+		Importance: 0.4,
+	}
+	if _, err := tc.MemoryMgr.SaveMemory(ctx, mem); err != nil {
+		zap.L().Warn("保存私聊记忆失败", zap.Int64("user_id", input.UserID), zap.Error(err))
+	}
+
+	output := &SendPrivateMessageOutput{Success: true, MessageID: msgID, Message: "私聊已发送"}
+	LogToolCall("sendPrivateMessage", input, output, nil)
+	return output, nil
+}
+
+// NewSendPrivateMessageTool 创建私聊工具
+func NewSendPrivateMessageTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"sendPrivateMessage",
+		"私聊某个很熟的群友。只在不适合在群里说的事（比如私下关心、悄悄话）时用，只能私聊亲密度足够高的人，别用来刷存在感。",
+		sendPrivateMessageFunc,
+	)
+}
+
+// ==================== 跨群转发工具 ====================
+
+// ForwardToGroupInput 跨群转发的输入参数
+type ForwardToGroupInput struct {
+	// MessageID 要转发的本群消息ID
+	MessageID int64 `json:"message_id" jsonschema:"description=要转发的本群消息ID"`
+	// GroupID 目标群号
+	GroupID int64 `json:"group_id" jsonschema:"description=目标群号，只能是允许转发的群"`
+}
+
+// ForwardToGroupOutput 跨群转发的输出
+type ForwardToGroupOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// forwardToGroupFunc 跨群转发的实际实现
+func forwardToGroupFunc(ctx context.Context, input *ForwardToGroupInput) (*ForwardToGroupOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.ForwardToGroupCallback == nil {
+		return &ForwardToGroupOutput{Success: false, Message: "跨群转发功能未启用"}, nil
+	}
+	if input.MessageID == 0 || input.GroupID == 0 {
+		return &ForwardToGroupOutput{Success: false, Message: "消息 ID 和目标群号不能为空"}, nil
+	}
+
+	dailyLimit := config.Get().Chat.GroupForward.DailyLimit
+	if dailyLimit == 0 {
+		dailyLimit = 5
+	}
+	ok, err := tc.MemoryMgr.TryUseTool("forwardToGroup", dailyLimit)
+	if err != nil || !ok {
+		output := &ForwardToGroupOutput{Success: false, Message: "今天转发得够多了"}
+		LogToolCall("forwardToGroup", input, output, err)
+		return output, nil
+	}
+
+	if err := tc.ForwardToGroupCallback(tc.GroupID, input.GroupID, input.MessageID); err != nil {
+		tc.MemoryMgr.RefundToolUse("forwardToGroup")
+		output := &ForwardToGroupOutput{Success: false, Message: err.Error()}
+		LogToolCall("forwardToGroup", input, output, err)
+		return output, nil
+	}
+
+	output := &ForwardToGroupOutput{Success: true, Message: "已转发"}
+	LogToolCall("forwardToGroup", input, output, nil)
+	return output, nil
+}
+
+// NewForwardToGroupTool 创建跨群转发工具
+func NewForwardToGroupTool() (tool.InvokableTool, error) {
+	var groups []string
+	for _, id := range config.Get().Chat.GroupForward.Groups {
+		groups = append(groups, strconv.FormatInt(id, 10))
+	}
+	return utils.InferTool(
+		"forwardToGroup",
+		"把本群的一条消息原样转发到另一个群，比如把重要公告同步过去。只能在这些群之间转发："+strings.Join(groups, "、")+"。别把闲聊或别人的隐私转出去。",
+		forwardToGroupFunc,
+	)
+}
+
+// ==================== 资料卡点赞工具 ====================
+
+// SendLikeInput 点赞的输入参数
+type SendLikeInput struct {
+	UserID int64 `json:"user_id" jsonschema:"description=要点赞的群友QQ号"`
+}
+
+// SendLikeOutput 点赞的输出
+type SendLikeOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// sendLikeFunc 点赞的实际实现
+func sendLikeFunc(ctx context.Context, input *SendLikeInput) (*SendLikeOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &SendLikeOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Bot == nil {
+		return &SendLikeOutput{Success: false, Message: "Bot 未连接"}, nil
+	}
+	if input.UserID == 0 {
+		return &SendLikeOutput{Success: false, Message: "用户 ID 不能为空"}, nil
+	}
+
+	// 只给熟人点赞
+	cfg := config.Get().Chat.Like
+	minIntimacy := cfg.MinIntimacy
+	if minIntimacy <= 0 {
+		minIntimacy = 0.5
+	}
+	profile, err := tc.MemoryMgr.GetUserProfile(input.UserID)
+	if err != nil || profile.Intimacy < minIntimacy {
+		output := &SendLikeOutput{Success: false, Message: "你和 TA 还不太熟，先别点赞了"}
+		LogToolCall("sendLike", input, output, nil)
+		return output, nil
+	}
+
+	dailyPerUser := cfg.DailyPerUser
+	if dailyPerUser <= 0 {
+		dailyPerUser = 1
+	}
+	key := fmt.Sprintf("sendLike:%d", input.UserID)
+	ok, err := tc.MemoryMgr.TryUseTool(key, dailyPerUser)
+	if err != nil || !ok {
+		output := &SendLikeOutput{Success: false, Message: "今天已经给 TA 点过赞了"}
+		LogToolCall("sendLike", input, output, err)
+		return output, nil
+	}
+
+	times := cfg.Times
+	if times <= 0 {
+		times = 10
+	}
+	if err := tc.Bot.SendLike(input.UserID, times); err != nil {
+		tc.MemoryMgr.RefundToolUse(key)
+		output := &SendLikeOutput{Success: false, Message: "点赞失败: " + err.Error()}
+		LogToolCall("sendLike", input, output, err)
+		return output, nil
+	}
+
+	output := &SendLikeOutput{Success: true, Message: fmt.Sprintf("已给 TA 的资料卡点了 %d 个赞", times)}
+	LogToolCall("sendLike", input, output, nil)
+	return output, nil
+}
+
+// NewSendLikeTool 创建点赞工具
+func NewSendLikeTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"sendLike",
+		"给熟悉的群友资料卡点赞，一种不打扰人的轻量互动，比如 TA 今天分享了好消息、或者你想表达好感时。只能给亲密度足够高的人点，每天每人有限次。",
+		sendLikeFunc,
+	)
+}
+
+// ==================== 合并转发工具 ====================
+
+// maxForwardNodes 一条合并转发最多包含的消息数
+const maxForwardNodes = 30
+
+// ForwardNode 合并转发中的一条消息
+type ForwardNode struct {
+	UserID   int64  `json:"user_id,omitempty" jsonschema:"description=这条消息显示的发送者QQ号，不填则显示为你自己"`
+	Nickname string `json:"nickname,omitempty" jsonschema:"description=这条消息显示的发送者昵称"`
+	Content  string `json:"content" jsonschema:"description=消息内容"`
+}
+
+// SendForwardInput 发送合并转发的输入参数
+type SendForwardInput struct {
+	// Nodes 按顺序排列的消息
+	Nodes []ForwardNode `json:"nodes" jsonschema:"description=按顺序排列的消息，最多30条"`
+}
+
+// SendForwardOutput 发送合并转发的输出
+type SendForwardOutput struct {
+	Success   bool   `json:"success"`
+	MessageID int64  `json:"message_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+// sendForwardFunc 发送合并转发的实际实现
+func sendForwardFunc(ctx context.Context, input *SendForwardInput) (*SendForwardOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.ForwardCallback == nil {
+		return &SendForwardOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if len(input.Nodes) == 0 {
+		return &SendForwardOutput{Success: false, Message: "消息不能为空"}, nil
+	}
+	if len(input.Nodes) > maxForwardNodes {
+		return &SendForwardOutput{Success: false, Message: fmt.Sprintf("最多 %d 条消息", maxForwardNodes)}, nil
+	}
+
+	nodes := make([]onebot.ForwardMessage, 0, len(input.Nodes))
+	for _, n := range input.Nodes {
+		if n.Content == "" {
+			continue
+		}
+		nodes = append(nodes, onebot.ForwardMessage{UserID: n.UserID, Nickname: n.Nickname, Content: n.Content})
+	}
+	if len(nodes) == 0 {
+		return &SendForwardOutput{Success: false, Message: "消息不能为空"}, nil
+	}
+
+	msgID, err := tc.ForwardCallback(tc.GroupID, nodes)
+	if err != nil {
+		output := &SendForwardOutput{Success: false, Message: err.Error()}
+		LogToolCall("sendForward", input, output, err)
+		return output, nil
+	}
+
+	output := &SendForwardOutput{Success: true, MessageID: msgID, Message: fmt.Sprintf("合并转发已发送，共 %d 条", len(nodes))}
+	LogToolCall("sendForward", input, output, nil)
+	return output, nil
+}
+
+// NewSendForwardTool 创建发送合并转发工具
+func NewSendForwardTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"sendForward",
+		"把多条消息打包成合并转发（聊天记录）发到群里。比如把刚才一段精彩对话整理出来甩出来，或者分条列一些内容。引用群友的话时填 TA 的QQ号和昵称，内容要忠实原话。",
+		sendForwardFunc,
+	)
+}
@@ -101,7 +101,7 @@ func searchJargonFunc(ctx context.Context, input *SearchJargonInput) (*SearchJar
 		limit = 10
 	}
 
-	jargons, err := tc.MemoryMgr.SearchJargons(tc.GroupID, input.Keyword, limit)
+	jargons, err := tc.MemoryMgr.SearchJargons(ctx, tc.GroupID, input.Keyword, limit)
 	if err != nil {
 		output := &SearchJargonOutput{Success: false, Message: err.Error()}
 		LogToolCall("searchJargon", input, output, err)
@@ -116,6 +116,7 @@ func searchJargonFunc(ctx context.Context, input *SearchJargonInput) (*SearchJar
 			"meaning":  j.Meaning,
 			"context":  j.Context,
 			"verified": j.Verified,
+			"uses":     j.Count,
 		})
 	}
 
@@ -197,6 +198,78 @@ func NewGetUnverifiedJargonsTool() (tool.InvokableTool, error) {
 	)
 }
 
+// ==================== 浏览黑话工具 ====================
+
+type ListJargonsInput struct {
+	Verified *bool `json:"verified,omitempty" jsonschema:"description=按验证状态过滤：true只看已验证的，false只看待审核的，不填看全部"`
+	Page     int   `json:"page,omitempty" jsonschema:"description=页码，从1开始，默认1"`
+	PageSize int   `json:"page_size,omitempty" jsonschema:"description=每页条数，默认20，最多50"`
+}
+
+type JargonListItem struct {
+	ID       uint   `json:"id"`
+	Content  string `json:"content"`
+	Meaning  string `json:"meaning"`
+	Verified bool   `json:"verified"`
+	Count    int    `json:"count"` // 群聊中出现的次数
+}
+
+type ListJargonsOutput struct {
+	Success bool             `json:"success"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Jargons []JargonListItem `json:"jargons,omitempty"`
+	Message string           `json:"message,omitempty"`
+}
+
+func listJargonsFunc(ctx context.Context, input *ListJargonsInput) (*ListJargonsOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &ListJargonsOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	pageSize = min(pageSize, 50)
+
+	jargons, total, err := tc.MemoryMgr.ListJargons(tc.GroupID, input.Verified, page, pageSize)
+	if err != nil {
+		output := &ListJargonsOutput{Success: false, Message: err.Error()}
+		LogToolCall("listJargons", input, output, err)
+		return output, nil
+	}
+
+	output := &ListJargonsOutput{Success: true, Total: total, Page: page}
+	for _, j := range jargons {
+		output.Jargons = append(output.Jargons, JargonListItem{
+			ID:       j.ID,
+			Content:  j.Content,
+			Meaning:  j.Meaning,
+			Verified: j.Verified,
+			Count:    j.Count,
+		})
+	}
+	if len(output.Jargons) == 0 {
+		output.Message = "这一页没有黑话了"
+	}
+	LogToolCall("listJargons", input, output, nil)
+	return output, nil
+}
+
+func NewListJargonsTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"listJargons",
+		"分页浏览本群记下的全部黑话/术语，按出现次数排序，可以只看已验证或待审核的。想系统复习群里的黑话时使用。",
+		listJargonsFunc,
+	)
+}
+
 // ==================== 审核黑话工具 ====================
 
 type ReviewJargonInput struct {
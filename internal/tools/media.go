@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/config"
+	mutils "mumu-bot/internal/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 语音工具 ====================
+
+type SendVoiceInput struct {
+	Content string `json:"content" jsonschema:"description=要用语音说出来的话，口语化，不要带表情符号和markdown"`
+}
+
+type SendVoiceOutput struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	MessageID int64  `json:"message_id,omitempty"`
+}
+
+// voiceLimits 单条语音字数上限和每日条数上限（0 表示不限制）
+func voiceLimits() (maxChars, dailyLimit int) {
+	cfg := config.Get().TTS
+	maxChars, dailyLimit = cfg.MaxChars, cfg.DailyLimit
+	if maxChars <= 0 {
+		maxChars = 100
+	}
+	if dailyLimit == 0 {
+		dailyLimit = 20
+	} else if dailyLimit < 0 {
+		dailyLimit = 0
+	}
+	return maxChars, dailyLimit
+}
+
+func sendVoiceFunc(ctx context.Context, input *SendVoiceInput) (*SendVoiceOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.VoiceCallback == nil {
+		return &SendVoiceOutput{Success: false, Message: "语音功能未启用"}, nil
+	}
+	if input.Content == "" {
+		return &SendVoiceOutput{Success: false, Message: "语音内容不能为空"}, nil
+	}
+
+	maxChars, dailyLimit := voiceLimits()
+	if n := len([]rune(input.Content)); n > maxChars {
+		output := &SendVoiceOutput{Success: false, Message: fmt.Sprintf("语音太长了（%d 字），不要超过 %d 字", n, maxChars)}
+		LogToolCall("sendVoice", input, output, nil)
+		return output, nil
+	}
+
+	ok, err := tc.MemoryMgr.TryUseTool("sendVoice", dailyLimit)
+	if err != nil {
+		output := &SendVoiceOutput{Success: false, Message: "查询语音额度失败"}
+		LogToolCall("sendVoice", input, output, err)
+		return output, nil
+	}
+	if !ok {
+		output := &SendVoiceOutput{Success: false, Message: "今天的语音次数用完了，用文字说吧"}
+		LogToolCall("sendVoice", input, output, nil)
+		return output, nil
+	}
+
+	msgID, err := tc.VoiceCallback(tc.GroupID, input.Content)
+	if err != nil {
+		tc.MemoryMgr.RefundToolUse("sendVoice")
+		output := &SendVoiceOutput{Success: false, Message: err.Error()}
+		LogToolCall("sendVoice", input, output, err)
+		return output, nil
+	}
+
+	output := &SendVoiceOutput{
+		Success:   true,
+		Message:   fmt.Sprintf("语音已发送，消息ID: %d", msgID),
+		MessageID: msgID,
+	}
+	if dailyLimit > 0 {
+		output.Message += fmt.Sprintf("，今天还能发 %d 条", max(dailyLimit-tc.MemoryMgr.GetToolUsage("sendVoice"), 0))
+	}
+	LogToolCall("sendVoice", input, output, nil)
+	return output, nil
+}
+
+func NewSendVoiceTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"sendVoice",
+		"用语音说一句话（文字会合成语音发到群里）。偶尔想撒娇、唱两句或者语气很重要的时候用，平时说话还是用 speak。每天次数有限。",
+		sendVoiceFunc,
+	)
+}
+
+// ==================== 发送网络图片工具 ====================
+
+type SendImageInput struct {
+	URL string `json:"url" jsonschema:"description=图片的完整链接（http/https），例如搜图工具返回的图片地址"`
+}
+
+type SendImageOutput struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	MessageID int64  `json:"message_id,omitempty"`
+}
+
+func sendImageFunc(ctx context.Context, input *SendImageInput) (*SendImageOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &SendImageOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Bot == nil {
+		return &SendImageOutput{Success: false, Message: "Bot 未连接"}, nil
+	}
+	if input.URL == "" {
+		return &SendImageOutput{Success: false, Message: "图片链接不能为空"}, nil
+	}
+
+	cfg := config.Get().Image.Send
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = 5
+	}
+	data, err := mutils.FetchImage(ctx, input.URL, cfg.AllowedDomains, int64(maxSizeMB)<<20)
+	if err != nil {
+		output := &SendImageOutput{Success: false, Message: err.Error()}
+		LogToolCall("sendImage", input, output, err)
+		return output, nil
+	}
+
+	msgID, err := tc.Bot.SendImageData(tc.GroupID, data)
+	if err != nil {
+		output := &SendImageOutput{Success: false, Message: "发送失败: " + err.Error()}
+		LogToolCall("sendImage", input, output, err)
+		return output, nil
+	}
+
+	output := &SendImageOutput{
+		Success:   true,
+		Message:   "图片已发送",
+		MessageID: msgID,
+	}
+	LogToolCall("sendImage", input, output, nil)
+	return output, nil
+}
+
+func NewSendImageTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"sendImage",
+		"按链接发送一张网络图片到群里。用搜图之类的工具找到图片后用它发出来；发收藏的表情包请用 sendSticker。",
+		sendImageFunc,
+	)
+}
+
+// ==================== 画图工具 ====================
+
+type DrawImageInput struct {
+	Prompt string `json:"prompt" jsonschema:"description=画面描述（提示词），具体写出主体、场景、风格，用英文效果更好"`
+}
+
+type DrawImageOutput struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	MessageID int64  `json:"message_id,omitempty"`
+}
+
+// drawDailyLimit 每天最多生成多少张图片（0 表示不限制）
+func drawDailyLimit() int {
+	limit := config.Get().Image.Draw.DailyLimit
+	if limit == 0 {
+		return 10
+	} else if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+func drawImageFunc(ctx context.Context, input *DrawImageInput) (*DrawImageOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.DrawCallback == nil {
+		return &DrawImageOutput{Success: false, Message: "画图功能未启用"}, nil
+	}
+	if input.Prompt == "" {
+		return &DrawImageOutput{Success: false, Message: "提示词不能为空"}, nil
+	}
+
+	dailyLimit := drawDailyLimit()
+	ok, err := tc.MemoryMgr.TryUseTool("drawImage", dailyLimit)
+	if err != nil {
+		output := &DrawImageOutput{Success: false, Message: "查询画图额度失败"}
+		LogToolCall("drawImage", input, output, err)
+		return output, nil
+	}
+	if !ok {
+		output := &DrawImageOutput{Success: false, Message: "今天画图的次数用完了，明天再画吧"}
+		LogToolCall("drawImage", input, output, nil)
+		return output, nil
+	}
+
+	msgID, err := tc.DrawCallback(tc.GroupID, input.Prompt)
+	if err != nil {
+		tc.MemoryMgr.RefundToolUse("drawImage")
+		output := &DrawImageOutput{Success: false, Message: err.Error()}
+		LogToolCall("drawImage", input, output, err)
+		return output, nil
+	}
+
+	output := &DrawImageOutput{
+		Success:   true,
+		Message:   fmt.Sprintf("图片已生成并发送，消息ID: %d", msgID),
+		MessageID: msgID,
+	}
+	if dailyLimit > 0 {
+		output.Message += fmt.Sprintf("，今天还能画 %d 张", max(dailyLimit-tc.MemoryMgr.GetToolUsage("drawImage"), 0))
+	}
+	LogToolCall("drawImage", input, output, nil)
+	return output, nil
+}
+
+func NewDrawImageTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"drawImage",
+		"画一张图发到群里（AI 生成，需要几十秒）。群友请你画画、或者你想用图来表达的时候用，每天次数有限，不要随便画。",
+		drawImageFunc,
+	)
+}
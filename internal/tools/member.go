@@ -2,6 +2,9 @@ package tools
 
 import (
 	"context"
+	"mumu-bot/internal/memory"
+	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/components/tool"
@@ -40,13 +43,21 @@ type UpdateMemberProfileInput struct {
 	// UserID 群友的QQ号
 	UserID int64 `json:"user_id" jsonschema:"description=群友的QQ号"`
 	// SpeakStyle 说话风格描述
-	SpeakStyle string `json:"speak_style,omitempty" jsonschema:"description=说话风格描述"`
+	SpeakStyle string `json:"speak_style,omitempty" jsonschema:"description=说话风格描述（各群通用）"`
+	// GroupSpeakStyle 仅在本群表现出的说话风格
+	GroupSpeakStyle string `json:"group_speak_style,omitempty" jsonschema:"description=只在当前群表现出的说话风格，和平时不一样时才填写"`
 	// Interests 兴趣爱好列表
 	Interests []string `json:"interests,omitempty" jsonschema:"description=兴趣爱好列表（只传入新增的项）"`
 	// CommonWords 常用词汇或口头禅
 	CommonWords []string `json:"common_words,omitempty" jsonschema:"description=常用词汇或口头禅（只传入新增的项）"`
 	// Intimacy 亲密度 0-1，根据互动情况调整
 	Intimacy *float64 `json:"intimacy,omitempty" jsonschema:"description=亲密度0-1，根据与对方的互动频率、聊天深度、情感连接来评估。"`
+	// Birthday 生日
+	Birthday string `json:"birthday,omitempty" jsonschema:"description=生日（公历），格式 MM-DD，如 03-15；只在群友明确说过自己生日时填写"`
+	// Timezone 所在时区
+	Timezone string `json:"timezone,omitempty" jsonschema:"description=所在时区的 IANA 名称，如 Asia/Shanghai、America/New_York；只在群友明确说过自己在哪里时填写"`
+	// PreferredAddress 希望被怎么称呼
+	PreferredAddress string `json:"preferred_address,omitempty" jsonschema:"description=对方希望被怎么称呼，如「叫我小林就好」则填小林"`
 }
 
 // UpdateMemberProfileOutput 更新成员画像的输出
@@ -66,13 +77,37 @@ func updateMemberProfileFunc(ctx context.Context, input *UpdateMemberProfileInpu
 		return &UpdateMemberProfileOutput{Success: false, Message: "用户 ID 不能为空"}, nil
 	}
 
-	profile, err := tc.MemoryMgr.GetMemberProfile(input.UserID)
+	// 先校验全部输入，避免部分字段已经写入后才报错
+	if input.Birthday != "" {
+		if _, err := time.Parse("01-02", input.Birthday); err != nil {
+			return &UpdateMemberProfileOutput{Success: false, Message: "生日格式应为 MM-DD"}, nil
+		}
+	}
+	if input.Timezone != "" {
+		if _, err := time.LoadLocation(input.Timezone); err != nil {
+			return &UpdateMemberProfileOutput{Success: false, Message: "无效的时区，请使用 IANA 名称，如 Asia/Tokyo"}, nil
+		}
+	}
+
+	profile, err := tc.MemoryMgr.GetUserProfile(input.UserID)
 	if err != nil {
 		return &UpdateMemberProfileOutput{Success: false, Message: err.Error()}, nil
 	}
 
+	// 群内特有的风格记录在群内画像上，同样先取到再统一写入
+	var mp *memory.MemberProfile
+	if input.GroupSpeakStyle != "" {
+		if mp, err = tc.MemoryMgr.GetMemberProfile(tc.GroupID, input.UserID); err != nil {
+			return &UpdateMemberProfileOutput{Success: false, Message: err.Error()}, nil
+		}
+		mp.SpeakStyle = input.GroupSpeakStyle
+	}
+
+	// 只写入本次修改的列，避免覆盖互动次数、发言统计等并发更新
+	var columns []string
 	if input.SpeakStyle != "" {
 		profile.SpeakStyle = input.SpeakStyle
+		columns = append(columns, "speak_style")
 	}
 	if len(input.Interests) > 0 {
 		// 解析已有的兴趣爱好
@@ -86,6 +121,7 @@ func updateMemberProfileFunc(ctx context.Context, input *UpdateMemberProfileInpu
 		mergedInterests := mergeAndDeduplicateStrings(existingInterests, input.Interests)
 		b, _ := sonic.MarshalString(mergedInterests)
 		profile.Interests = b
+		columns = append(columns, "interests")
 	}
 	if len(input.CommonWords) > 0 {
 		// 解析已有的常用词汇
@@ -99,23 +135,38 @@ func updateMemberProfileFunc(ctx context.Context, input *UpdateMemberProfileInpu
 		mergedCommonWords := mergeAndDeduplicateStrings(existingCommonWords, input.CommonWords)
 		b, _ := sonic.MarshalString(mergedCommonWords)
 		profile.CommonWords = b
+		columns = append(columns, "common_words")
 	}
 	if input.Intimacy != nil {
-		// 限制亲密度在 0-1 范围内
-		intimacy := *input.Intimacy
-		if intimacy < 0 {
-			intimacy = 0
-		} else if intimacy > 1 {
-			intimacy = 1
-		}
-		profile.Intimacy = intimacy
+		// 限制亲密度在 0-1 范围内，开启亲密度演化时单次调整幅度也有上限
+		profile.Intimacy = tc.MemoryMgr.AdjustIntimacy(profile.Intimacy, *input.Intimacy)
+		columns = append(columns, "intimacy")
+	}
+	if input.Birthday != "" {
+		profile.Birthday = input.Birthday
+		columns = append(columns, "birthday")
+	}
+	if input.Timezone != "" {
+		profile.Timezone = input.Timezone
+		columns = append(columns, "timezone")
+	}
+	if input.PreferredAddress != "" {
+		profile.PreferredAddress = input.PreferredAddress
+		columns = append(columns, "preferred_address")
 	}
 
-	if err := tc.MemoryMgr.UpdateMemberProfile(profile); err != nil {
+	if err := tc.MemoryMgr.UpdateUserProfile(profile, columns...); err != nil {
 		output := &UpdateMemberProfileOutput{Success: false, Message: err.Error()}
 		LogToolCall("updateMemberProfile", input, output, err)
 		return output, nil
 	}
+	if mp != nil {
+		if err := tc.MemoryMgr.UpdateMemberProfile(mp, "speak_style"); err != nil {
+			output := &UpdateMemberProfileOutput{Success: false, Message: err.Error()}
+			LogToolCall("updateMemberProfile", input, output, err)
+			return output, nil
+		}
+	}
 
 	output := &UpdateMemberProfileOutput{Success: true, Message: "已更新对该群友的了解"}
 	LogToolCall("updateMemberProfile", input, output, nil)
@@ -126,7 +177,7 @@ func updateMemberProfileFunc(ctx context.Context, input *UpdateMemberProfileInpu
 func NewUpdateMemberProfileTool() (tool.InvokableTool, error) {
 	return utils.InferTool(
 		"updateMemberProfile",
-		"更新你对某个群友的了解。当你发现群友的新特点、说话风格、兴趣爱好时使用。也可以根据互动情况调整亲密度（intimacy）。",
+		"更新你对某个群友的了解。当你发现群友的新特点、说话风格、兴趣爱好、生日时使用。也可以根据互动情况调整亲密度（intimacy），每次调整幅度有限，亲密度主要靠日常互动慢慢积累。这些了解在各个群之间共享，只在当前群才有的表现写到 group_speak_style。对方说过希望怎么被称呼时记到 preferred_address。",
 		updateMemberProfileFunc,
 	)
 }
@@ -141,15 +192,21 @@ type GetMemberInfoInput struct {
 
 // GetMemberInfoOutput 获取成员信息的输出
 type GetMemberInfoOutput struct {
-	Success     bool     `json:"success"`
-	Message     string   `json:"message,omitempty"`
-	Nickname    string   `json:"nickname,omitempty"`
-	SpeakStyle  string   `json:"speak_style,omitempty"`
-	Interests   []string `json:"interests,omitempty"`
-	CommonWords []string `json:"common_words,omitempty"`
-	Activity    float64  `json:"activity,omitempty"` // 活跃度 0-1
-	Intimacy    float64  `json:"intimacy,omitempty"` // 亲密度 0-1
-	MsgCount    int      `json:"msg_count,omitempty"`
+	Success          bool     `json:"success"`
+	Message          string   `json:"message,omitempty"`
+	Nickname         string   `json:"nickname,omitempty"`
+	SpeakStyle       string   `json:"speak_style,omitempty"`
+	GroupSpeakStyle  string   `json:"group_speak_style,omitempty"` // 仅在本群表现出的说话风格
+	Interests        []string `json:"interests,omitempty"`
+	CommonWords      []string `json:"common_words,omitempty"`
+	Activity         float64  `json:"activity,omitempty"`        // 本群活跃度 0-1
+	Intimacy         float64  `json:"intimacy,omitempty"`        // 亲密度 0-1
+	MsgCount         int      `json:"msg_count,omitempty"`       // 本群发言数
+	TotalMsgCount    int      `json:"total_msg_count,omitempty"` // 所有群的发言总数
+	Birthday         string   `json:"birthday,omitempty"`
+	Timezone         string   `json:"timezone,omitempty"`
+	LocalTime        string   `json:"local_time,omitempty"`        // 对方当地时间
+	PreferredAddress string   `json:"preferred_address,omitempty"` // 希望被怎么称呼
 }
 
 // getMemberInfoFunc 获取成员信息的实际实现
@@ -163,7 +220,7 @@ func getMemberInfoFunc(ctx context.Context, input *GetMemberInfoInput) (*GetMemb
 		return &GetMemberInfoOutput{Success: false, Message: "用户 ID 不能为空"}, nil
 	}
 
-	profile, err := tc.MemoryMgr.GetMemberProfile(input.UserID)
+	profile, err := tc.MemoryMgr.GetUserProfile(input.UserID)
 	if err != nil {
 		output := &GetMemberInfoOutput{
 			Success: false,
@@ -186,14 +243,27 @@ func getMemberInfoFunc(ctx context.Context, input *GetMemberInfoInput) (*GetMemb
 	}
 
 	output := &GetMemberInfoOutput{
-		Success:     true,
-		Nickname:    profile.Nickname,
-		SpeakStyle:  profile.SpeakStyle,
-		Interests:   interests,
-		CommonWords: commonWords,
-		Activity:    profile.Activity,
-		Intimacy:    profile.Intimacy,
-		MsgCount:    profile.MsgCount,
+		Success:          true,
+		Nickname:         profile.Nickname,
+		SpeakStyle:       profile.SpeakStyle,
+		Interests:        interests,
+		CommonWords:      commonWords,
+		Intimacy:         profile.Intimacy,
+		TotalMsgCount:    profile.MsgCount,
+		Birthday:         profile.Birthday,
+		Timezone:         profile.Timezone,
+		PreferredAddress: profile.PreferredAddress,
+	}
+	if loc, err := time.LoadLocation(profile.Timezone); err == nil && profile.Timezone != "" {
+		output.LocalTime = time.Now().In(loc).Format("2006-01-02 15:04")
+	}
+	if mp, err := tc.MemoryMgr.GetMemberProfile(tc.GroupID, input.UserID); err == nil {
+		if mp.Nickname != "" {
+			output.Nickname = mp.Nickname
+		}
+		output.GroupSpeakStyle = mp.SpeakStyle
+		output.Activity = mp.Activity
+		output.MsgCount = mp.MsgCount
 	}
 	LogToolCall("getMemberInfo", input, output, nil)
 	return output, nil
@@ -207,3 +277,89 @@ func NewGetMemberInfoTool() (tool.InvokableTool, error) {
 		getMemberInfoFunc,
 	)
 }
+
+// ==================== 按昵称查找成员工具 ====================
+
+type FindMemberByNameInput struct {
+	Name string `json:"name" jsonschema:"description=要找的人的昵称或群名片，支持只写一部分"`
+}
+
+type MemberCandidate struct {
+	UserID   int64  `json:"user_id"`
+	Nickname string `json:"nickname,omitempty"` // QQ 昵称或记录中的昵称
+	Card     string `json:"card,omitempty"`     // 群名片
+}
+
+type FindMemberByNameOutput struct {
+	Success    bool              `json:"success"`
+	Candidates []MemberCandidate `json:"candidates,omitempty"`
+	Message    string            `json:"message,omitempty"`
+}
+
+// maxMemberCandidates 最多返回的候选人数
+const maxMemberCandidates = 10
+
+func findMemberByNameFunc(ctx context.Context, input *FindMemberByNameInput) (*FindMemberByNameOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &FindMemberByNameOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return &FindMemberByNameOutput{Success: false, Message: "昵称不能为空"}, nil
+	}
+
+	candidates := make([]MemberCandidate, 0, maxMemberCandidates)
+	index := make(map[int64]int)
+
+	// 先查画像表（说过话的人），再查实时群成员列表补充
+	profiles, err := tc.MemoryMgr.FindMembersByName(tc.GroupID, name, maxMemberCandidates)
+	if err != nil {
+		zap.L().Warn("按昵称查找画像失败", zap.Error(err))
+	}
+	for _, p := range profiles {
+		index[p.UserID] = len(candidates)
+		candidates = append(candidates, MemberCandidate{UserID: p.UserID, Nickname: p.Nickname})
+	}
+
+	if tc.Bot != nil {
+		if members, err := tc.Bot.GetGroupMemberList(tc.GroupID, false); err == nil {
+			lower := strings.ToLower(name)
+			for _, mb := range members {
+				if !strings.Contains(strings.ToLower(mb.Card), lower) && !strings.Contains(strings.ToLower(mb.Nickname), lower) {
+					continue
+				}
+				if i, ok := index[mb.UserID]; ok {
+					candidates[i].Nickname, candidates[i].Card = mb.Nickname, mb.Card
+					continue
+				}
+				if len(candidates) >= maxMemberCandidates {
+					continue
+				}
+				index[mb.UserID] = len(candidates)
+				candidates = append(candidates, MemberCandidate{UserID: mb.UserID, Nickname: mb.Nickname, Card: mb.Card})
+			}
+		} else {
+			zap.L().Warn("获取群成员列表失败", zap.Error(err))
+		}
+	}
+
+	if len(candidates) == 0 {
+		output := &FindMemberByNameOutput{Success: true, Message: "没找到叫这个名字的人"}
+		LogToolCall("findMemberByName", input, output, nil)
+		return output, nil
+	}
+
+	output := &FindMemberByNameOutput{Success: true, Candidates: candidates}
+	LogToolCall("findMemberByName", input, output, nil)
+	return output, nil
+}
+
+// NewFindMemberByNameTool 创建按昵称查找成员工具
+func NewFindMemberByNameTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"findMemberByName",
+		"按昵称或群名片查找群友的QQ号。只知道名字、想@某人或查看某人信息时先用它拿到QQ号；有多个候选时结合上下文判断是谁。",
+		findMemberByNameFunc,
+	)
+}
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"mumu-bot/internal/memory"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -20,6 +23,8 @@ type SaveMemoryInput struct {
 	Importance float64 `json:"importance,omitempty" jsonschema:"description=重要性评分(0-1)，越重要越高"`
 	// RelatedUserID 相关的用户ID（可选）
 	RelatedUserID int64 `json:"related_user_id,omitempty" jsonschema:"description=如果这条记忆与某个群友相关，填写其QQ号"`
+	// SourceMsgIDs 记忆来源的消息 ID（可选）
+	SourceMsgIDs []int64 `json:"source_msg_ids,omitempty" jsonschema:"description=这条记忆出自哪几条消息（消息ID），不填时自动关联最近的相关消息"`
 }
 
 // SaveMemoryOutput 保存记忆的输出
@@ -54,21 +59,35 @@ func saveMemoryFunc(ctx context.Context, input *SaveMemoryInput) (*SaveMemoryOut
 		importance = 0.5
 	}
 
+	// 记录来源消息，方便日后核对记忆是否准确
+	sources := make([]string, 0, len(input.SourceMsgIDs))
+	for _, id := range input.SourceMsgIDs {
+		sources = append(sources, strconv.FormatInt(id, 10))
+	}
+	if len(sources) == 0 {
+		sources = tc.MemoryMgr.GetSourceMessageIDs(tc.GroupID, input.RelatedUserID, 3)
+	}
+
 	mem := &memory.Memory{
-		Type:       memory.MemoryType(input.Type),
-		GroupID:    tc.GroupID,
-		UserID:     input.RelatedUserID,
-		Content:    input.Content,
-		Importance: importance,
+		Type:        memory.MemoryType(input.Type),
+		GroupID:     tc.GroupID,
+		UserID:      input.RelatedUserID,
+		Content:     input.Content,
+		Importance:  importance,
+		SourceMsgID: strings.Join(sources, ","),
 	}
 
-	if err := tc.MemoryMgr.SaveMemory(ctx, mem); err != nil {
+	merged, err := tc.MemoryMgr.SaveMemory(ctx, mem)
+	if err != nil {
 		output := &SaveMemoryOutput{Success: false, Message: err.Error()}
 		LogToolCall("saveMemory", input, output, err)
 		return output, nil
 	}
 
 	output := &SaveMemoryOutput{Success: true, Message: "已记住"}
+	if merged {
+		output.Message = "已有相似的记忆，已更新为新的内容"
+	}
 	LogToolCall("saveMemory", input, output, nil)
 	return output, nil
 }
@@ -93,11 +112,13 @@ type QueryMemoryInput struct {
 	// Query 搜索关键词或描述
 	Query string `json:"query" jsonschema:"description=搜索关键词或描述"`
 	// Type 限定记忆类型（可选）
-	Type string `json:"type,omitempty" jsonschema:"enum=group_fact,enum=self_experience,enum=conversation,description=限定记忆类型（空字符串时不筛选）"`
+	Type string `json:"type,omitempty" jsonschema:"enum=group_fact,enum=self_experience,enum=conversation,enum=topic_summary,description=限定记忆类型（空字符串时不筛选），topic_summary 为自动生成的群聊话题摘要"`
 	// Scoped 是否只搜索当前聊天群的记忆
 	Scoped bool `json:"scoped,omitempty" jsonschema:"description=是否只搜索当前聊天群的记忆，默认false"`
 	// Limit 返回结果数量限制，默认10，最大50
 	Limit int `json:"limit,omitempty" jsonschema:"description=返回结果数量限制，默认10，最大50"`
+	// IncludeSummaries 是否额外检索话题摘要
+	IncludeSummaries bool `json:"include_summaries,omitempty" jsonschema:"description=是否同时检索群聊话题摘要（适合回忆「之前大家聊过什么」），默认false"`
 }
 
 // QueryMemoryOutput 查询记忆的输出
@@ -140,9 +161,26 @@ func queryMemoryFunc(ctx context.Context, input *QueryMemoryInput) (*QueryMemory
 		return output, nil
 	}
 
+	// 话题摘要单独检索一次再合并，避免被普通记忆挤掉
+	if input.IncludeSummaries && memory.MemoryType(input.Type) != memory.MemoryTypeTopicSummary {
+		seen := make(map[uint]bool, len(memories))
+		for _, m := range memories {
+			seen[m.ID] = true
+		}
+		summaries, err := tc.MemoryMgr.QueryMemory(ctx, input.Query, groupID, memory.MemoryTypeTopicSummary, min(limit, 5))
+		if err == nil {
+			for _, m := range summaries {
+				if !seen[m.ID] {
+					memories = append(memories, m)
+				}
+			}
+		}
+	}
+
 	results := make([]map[string]interface{}, 0, len(memories))
 	for _, m := range memories {
 		results = append(results, map[string]interface{}{
+			"id":         m.ID,
 			"type":       m.Type,
 			"content":    m.Content,
 			"importance": m.Importance,
@@ -168,7 +206,278 @@ func NewQueryMemoryTool() (tool.InvokableTool, error) {
 【scoped 参数使用指南】
 - scoped=false（默认）：搜索所有群的记忆，适合查找自身经历、过往事件等
 - scoped=true：只搜索当前群的记忆，适合查找当前群内事件、群规等
+
+想回忆「之前大家聊过什么」时，设置 include_summaries=true 同时检索群聊话题摘要。
 `,
 		queryMemoryFunc,
 	)
 }
+
+// ==================== 编辑记忆工具 ====================
+
+// EditMemoryInput 编辑记忆的输入参数
+type EditMemoryInput struct {
+	// ID 记忆ID
+	ID uint `json:"id" jsonschema:"description=要修改的记忆ID（queryMemory 返回的 id）"`
+	// Content 新的内容
+	Content string `json:"content,omitempty" jsonschema:"description=修改后的完整内容，不改内容时留空"`
+	// Importance 新的重要性
+	Importance *float64 `json:"importance,omitempty" jsonschema:"description=新的重要性评分(0-1)，不改时不填"`
+	// Type 新的记忆类型
+	Type string `json:"type,omitempty" jsonschema:"enum=group_fact,enum=self_experience,enum=conversation,description=新的记忆类型，不改时留空"`
+}
+
+// EditMemoryOutput 编辑记忆的输出
+type EditMemoryOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// editMemoryFunc 编辑记忆的实际实现
+func editMemoryFunc(ctx context.Context, input *EditMemoryInput) (*EditMemoryOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &EditMemoryOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	if input.ID == 0 {
+		return &EditMemoryOutput{Success: false, Message: "记忆 ID 不能为空"}, nil
+	}
+
+	edit := &memory.MemoryEdit{}
+	if input.Content != "" {
+		edit.Content = &input.Content
+	}
+	if input.Importance != nil {
+		if *input.Importance < 0 || *input.Importance > 1 {
+			return &EditMemoryOutput{Success: false, Message: "重要性应在 0-1 之间"}, nil
+		}
+		edit.Importance = input.Importance
+	}
+	if input.Type != "" {
+		t := memory.MemoryType(input.Type)
+		if t != memory.MemoryTypeGroupFact && t != memory.MemoryTypeSelfExperience && t != memory.MemoryTypeConversation {
+			return &EditMemoryOutput{Success: false, Message: "无效的记忆类型，可选: group_fact, self_experience, conversation"}, nil
+		}
+		edit.Type = &t
+	}
+	if edit.Content == nil && edit.Importance == nil && edit.Type == nil {
+		return &EditMemoryOutput{Success: false, Message: "没有需要修改的内容"}, nil
+	}
+
+	if _, err := tc.MemoryMgr.EditMemory(ctx, input.ID, tc.GroupID, edit); err != nil {
+		output := &EditMemoryOutput{Success: false, Message: "修改失败，记忆不存在或不属于这个群"}
+		LogToolCall("editMemory", input, output, err)
+		return output, nil
+	}
+
+	output := &EditMemoryOutput{Success: true, Message: "已修改这条记忆"}
+	LogToolCall("editMemory", input, output, nil)
+	return output, nil
+}
+
+// NewEditMemoryTool 创建编辑记忆工具
+func NewEditMemoryTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"editMemory",
+		`修改一条已有的记忆（改内容后会重新生成向量）。发现之前记错了、信息过时了或重要性判断不对时使用，先用 queryMemory 找到记忆的 id。
+纠正记忆优先用它，不要删掉再重新 saveMemory。`,
+		editMemoryFunc,
+	)
+}
+
+// ==================== 删除记忆工具 ====================
+
+// DeleteMemoryInput 删除记忆的输入参数
+type DeleteMemoryInput struct {
+	// ID 记忆ID
+	ID uint `json:"id" jsonschema:"description=要删除的记忆ID（从queryMemory结果中获取）"`
+	// Reason 删除原因
+	Reason string `json:"reason" jsonschema:"description=为什么删除，例如：信息有误、已经过时"`
+}
+
+// DeleteMemoryOutput 删除记忆的输出
+type DeleteMemoryOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// deleteMemoryFunc 删除记忆的实际实现
+func deleteMemoryFunc(ctx context.Context, input *DeleteMemoryInput) (*DeleteMemoryOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &DeleteMemoryOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.ID == 0 {
+		return &DeleteMemoryOutput{Success: false, Message: "记忆 ID 不能为空"}, nil
+	}
+	if strings.TrimSpace(input.Reason) == "" {
+		return &DeleteMemoryOutput{Success: false, Message: "请说明删除原因"}, nil
+	}
+
+	if _, err := tc.MemoryMgr.DeleteMemory(ctx, input.ID, tc.GroupID, input.Reason); err != nil {
+		output := &DeleteMemoryOutput{Success: false, Message: "删除失败，记忆不存在或不属于这个群"}
+		LogToolCall("deleteMemory", input, output, err)
+		return output, nil
+	}
+
+	output := &DeleteMemoryOutput{Success: true, Message: "已删除这条记忆"}
+	LogToolCall("deleteMemory", input, output, nil)
+	return output, nil
+}
+
+// NewDeleteMemoryTool 创建删除记忆工具
+func NewDeleteMemoryTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"deleteMemory",
+		`删除一条记忆。只在确认记忆是错的、已经完全过时或者群友明确要求你忘掉时使用；只是有点不准确的用 editMemory 改。先用 queryMemory 找到记忆的 id。`,
+		deleteMemoryFunc,
+	)
+}
+
+// ==================== 查询日记工具 ====================
+
+// GetRecentDiariesInput 查询日记的输入参数
+type GetRecentDiariesInput struct {
+	// Limit 返回篇数，默认3，最大10
+	Limit int `json:"limit,omitempty" jsonschema:"description=返回日记篇数，默认3，最大10"`
+}
+
+// GetRecentDiariesOutput 查询日记的输出
+type GetRecentDiariesOutput struct {
+	Success bool     `json:"success"`
+	Count   int      `json:"count"`
+	Diaries []string `json:"diaries,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// getRecentDiariesFunc 查询日记的实际实现
+func getRecentDiariesFunc(ctx context.Context, input *GetRecentDiariesInput) (*GetRecentDiariesOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &GetRecentDiariesOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 3
+	}
+	if limit > 10 {
+		limit = 10
+	}
+
+	diaries, err := tc.MemoryMgr.GetRecentDiaries(tc.GroupID, limit)
+	if err != nil {
+		output := &GetRecentDiariesOutput{Success: false, Message: err.Error()}
+		LogToolCall("getRecentDiaries", input, output, err)
+		return output, nil
+	}
+
+	results := make([]string, 0, len(diaries))
+	for _, d := range diaries {
+		results = append(results, d.Content)
+	}
+
+	output := &GetRecentDiariesOutput{
+		Success: true,
+		Count:   len(results),
+		Diaries: results,
+	}
+	LogToolCall("getRecentDiaries", input, output, nil)
+	return output, nil
+}
+
+// NewGetRecentDiariesTool 创建查询日记工具
+func NewGetRecentDiariesTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"getRecentDiaries",
+		"翻看你最近在这个群写的日记，回忆前几天发生的事和当时的心情。",
+		getRecentDiariesFunc,
+	)
+}
+
+// ==================== 话题摘要检索工具 ====================
+
+// SearchTopicSummariesInput 检索话题摘要的输入参数
+type SearchTopicSummariesInput struct {
+	Keyword   string `json:"keyword,omitempty" jsonschema:"description=关键词，多个用空格分隔，命中任意一个即可。不填则只按时间筛选"`
+	StartDate string `json:"start_date,omitempty" jsonschema:"description=开始日期（含），格式 2006-01-02，不填不限制"`
+	EndDate   string `json:"end_date,omitempty" jsonschema:"description=结束日期（含），格式 2006-01-02，不填不限制"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"description=返回条数，默认10，最多20"`
+}
+
+// TopicSummaryItem 一条话题摘要
+type TopicSummaryItem struct {
+	Topic        string `json:"topic"`
+	Summary      string `json:"summary"`
+	Participants string `json:"participants,omitempty"`
+	Time         string `json:"time"`
+}
+
+// SearchTopicSummariesOutput 检索话题摘要的输出
+type SearchTopicSummariesOutput struct {
+	Success bool               `json:"success"`
+	Topics  []TopicSummaryItem `json:"topics,omitempty"`
+	Message string             `json:"message,omitempty"`
+}
+
+// searchTopicSummariesFunc 检索话题摘要的实际实现
+func searchTopicSummariesFunc(ctx context.Context, input *SearchTopicSummariesInput) (*SearchTopicSummariesOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &SearchTopicSummariesOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	var since, until time.Time
+	if input.StartDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, input.StartDate, time.Local)
+		if err != nil {
+			return &SearchTopicSummariesOutput{Success: false, Message: "开始日期格式不对，应为 2006-01-02"}, nil
+		}
+		since = t
+	}
+	if input.EndDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, input.EndDate, time.Local)
+		if err != nil {
+			return &SearchTopicSummariesOutput{Success: false, Message: "结束日期格式不对，应为 2006-01-02"}, nil
+		}
+		until = t.AddDate(0, 0, 1)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 20)
+
+	summaries, err := tc.MemoryMgr.SearchTopicSummaries(tc.GroupID, input.Keyword, since, until, limit)
+	if err != nil {
+		output := &SearchTopicSummariesOutput{Success: false, Message: err.Error()}
+		LogToolCall("searchTopicSummaries", input, output, err)
+		return output, nil
+	}
+
+	output := &SearchTopicSummariesOutput{Success: true}
+	for _, s := range summaries {
+		output.Topics = append(output.Topics, TopicSummaryItem{
+			Topic:        s.Topic,
+			Summary:      s.Summary,
+			Participants: s.Participants,
+			Time:         s.StartTime.Format("01-02 15:04") + " ~ " + s.EndTime.Format("01-02 15:04"),
+		})
+	}
+	if len(output.Topics) == 0 {
+		output.Message = "没有找到相关的话题"
+	}
+	LogToolCall("searchTopicSummaries", input, output, nil)
+	return output, nil
+}
+
+// NewSearchTopicSummariesTool 创建检索话题摘要工具
+func NewSearchTopicSummariesTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"searchTopicSummaries",
+		"按时间范围和关键词翻看群里聊过的话题摘要。群友问\"上周我们聊过啥\"、\"之前那次讨论XX是怎么说的\"时使用。",
+		searchTopicSummariesFunc,
+	)
+}
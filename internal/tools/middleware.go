@@ -0,0 +1,408 @@
+package tools
+
+import (
+	"context"
+	"mumu-bot/internal/config"
+	"mumu-bot/internal/events"
+	"mumu-bot/internal/memory"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"go.uber.org/zap"
+)
+
+// panicToolResult 工具 panic 时返回给 LLM 的结果
+const panicToolResult = `{"success":false,"message":"工具执行出错了，换个方式或者先别用这个工具"}`
+
+// recoverTool 包装工具，执行发生 panic 时恢复并把错误作为工具结果反馈给 LLM
+type recoverTool struct {
+	tool.InvokableTool
+}
+
+func (t *recoverTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			name := ""
+			if info, infoErr := t.Info(ctx); infoErr == nil {
+				name = info.Name
+			}
+			zap.L().Error("工具执行 panic",
+				zap.String("tool", name),
+				zap.String("arguments", argumentsInJSON),
+				zap.Any("panic", r),
+				zap.String("stack", string(debug.Stack())))
+			result, err = panicToolResult, nil
+		}
+	}()
+	return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+// WithRecover 为可调用工具加上 panic 恢复，其他类型的工具原样返回
+func WithRecover(t tool.BaseTool) tool.BaseTool {
+	if it, ok := t.(tool.InvokableTool); ok {
+		return &recoverTool{InvokableTool: it}
+	}
+	return t
+}
+
+// rateLimitedToolResult 工具超出频率限制时返回给 LLM 的结果
+const rateLimitedToolResult = `{"success":false,"message":"你最近做太多次了，先缓一缓，换种方式互动吧"}`
+
+// defaultToolRateLimits 未配置时使用的频率限制，容易被滥用的轻量互动工具
+var defaultToolRateLimits = map[string]config.ToolRateLimit{
+	"poke":           {Max: 3, Window: 600},
+	"reactToMessage": {Max: 5, Window: 600},
+}
+
+// RateLimiter 按工具、按群的滑动窗口频率限制
+type RateLimiter struct {
+	rules map[string]config.ToolRateLimit
+	mu    sync.Mutex
+	calls map[rateLimitKey][]time.Time
+}
+
+type rateLimitKey struct {
+	tool    string
+	groupID int64
+}
+
+// NewRateLimiter 创建频率限制器，rules 为 nil 时使用内置默认值
+func NewRateLimiter(rules map[string]config.ToolRateLimit) *RateLimiter {
+	if rules == nil {
+		rules = defaultToolRateLimits
+	}
+	return &RateLimiter{rules: rules, calls: make(map[rateLimitKey][]time.Time)}
+}
+
+// allow 检查并记录一次调用，超限时返回 false
+func (l *RateLimiter) allow(toolName string, groupID int64, now time.Time) bool {
+	rule, ok := l.rules[toolName]
+	if !ok || rule.Max <= 0 || rule.Window <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := rateLimitKey{tool: toolName, groupID: groupID}
+	cutoff := now.Add(-time.Duration(rule.Window) * time.Second)
+	calls := l.calls[key]
+	i := 0
+	for i < len(calls) && !calls[i].After(cutoff) {
+		i++
+	}
+	calls = calls[i:]
+	if len(calls) >= rule.Max {
+		l.calls[key] = calls
+		return false
+	}
+	l.calls[key] = append(calls, now)
+	return true
+}
+
+// rateLimitTool 包装工具，超出频率限制时直接返回提示而不执行
+type rateLimitTool struct {
+	tool.InvokableTool
+	name    string
+	limiter *RateLimiter
+}
+
+func (t *rateLimitTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var groupID int64
+	if tc := GetToolContext(ctx); tc != nil {
+		groupID = tc.GroupID
+	}
+	if !t.limiter.allow(t.name, groupID, time.Now()) {
+		zap.L().Info("工具调用超出频率限制", zap.String("tool", t.name), zap.Int64("group_id", groupID))
+		return rateLimitedToolResult, nil
+	}
+	return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+// WithRateLimit 为配置了频率限制的可调用工具加上限制，其他工具原样返回
+func WithRateLimit(t tool.BaseTool, limiter *RateLimiter) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok || limiter == nil {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return t
+	}
+	if rule, ok := limiter.rules[info.Name]; !ok || rule.Max <= 0 {
+		return t
+	}
+	return &rateLimitTool{InvokableTool: it, name: info.Name, limiter: limiter}
+}
+
+// timeoutToolResult 工具执行超时时返回给 LLM 的结果
+const timeoutToolResult = `{"success":false,"message":"工具半天没响应，已经放弃了，换个方式或者先别用这个工具"}`
+
+// 工具超时默认值
+const (
+	defaultToolTimeout       = 30 * time.Second
+	defaultSlowToolThreshold = 5 * time.Second
+)
+
+// defaultToolTimeouts 未单独配置时使用的超时（秒），本身就比较慢的工具
+var defaultToolTimeouts = map[string]int{
+	"drawImage": 120,
+}
+
+// ToolStat 单个工具的执行统计
+type ToolStat struct {
+	Calls       int64   `json:"calls"`
+	Slow        int64   `json:"slow"`     // 超过慢调用阈值的次数
+	Timeouts    int64   `json:"timeouts"` // 超时次数
+	AvgSeconds  float64 `json:"avg_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+	LastSlowAt  string  `json:"last_slow_at,omitempty"`
+	total       time.Duration
+	maxDuration time.Duration
+}
+
+// ToolTimeouts 工具执行超时控制与慢工具统计
+type ToolTimeouts struct {
+	cfg  config.ToolTimeoutConfig
+	mu   sync.Mutex
+	stat map[string]*ToolStat
+}
+
+// NewToolTimeouts 创建工具超时控制
+func NewToolTimeouts(cfg config.ToolTimeoutConfig) *ToolTimeouts {
+	return &ToolTimeouts{cfg: cfg, stat: make(map[string]*ToolStat)}
+}
+
+// timeoutFor 返回工具的超时时长，0 表示不限制
+func (t *ToolTimeouts) timeoutFor(name string) time.Duration {
+	sec, ok := t.cfg.Tools[name]
+	if !ok {
+		sec, ok = defaultToolTimeouts[name]
+	}
+	if !ok {
+		sec = t.cfg.Default
+	}
+	switch {
+	case sec < 0:
+		return 0
+	case sec == 0:
+		return defaultToolTimeout
+	}
+	return time.Duration(sec) * time.Second
+}
+
+// slowThreshold 慢调用阈值
+func (t *ToolTimeouts) slowThreshold() time.Duration {
+	if t.cfg.SlowThreshold > 0 {
+		return time.Duration(t.cfg.SlowThreshold) * time.Second
+	}
+	return defaultSlowToolThreshold
+}
+
+// record 记录一次工具执行
+func (t *ToolTimeouts) record(name string, elapsed time.Duration, timedOut bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stat[name]
+	if !ok {
+		s = &ToolStat{}
+		t.stat[name] = s
+	}
+	s.Calls++
+	s.total += elapsed
+	s.maxDuration = max(s.maxDuration, elapsed)
+	if timedOut {
+		s.Timeouts++
+	}
+	if elapsed >= t.slowThreshold() {
+		s.Slow++
+		s.LastSlowAt = time.Now().Format(time.DateTime)
+	}
+}
+
+// Stats 返回各工具的执行统计快照
+func (t *ToolTimeouts) Stats() map[string]ToolStat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make(map[string]ToolStat, len(t.stat))
+	for name, s := range t.stat {
+		snap := *s
+		snap.AvgSeconds = s.total.Seconds() / float64(s.Calls)
+		snap.MaxSeconds = s.maxDuration.Seconds()
+		result[name] = snap
+	}
+	return result
+}
+
+// timeoutTool 包装工具，超时后不再等待结果，直接告诉 LLM 工具没响应
+type timeoutTool struct {
+	tool.InvokableTool
+	name     string
+	timeout  time.Duration
+	timeouts *ToolTimeouts
+}
+
+type toolRunResult struct {
+	result string
+	err    error
+}
+
+func (t *timeoutTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	start := time.Now()
+	if t.timeout <= 0 {
+		result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+		t.logSlow(start, false)
+		return result, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	// 带缓冲，超时放弃后工具仍可正常返回而不阻塞
+	done := make(chan toolRunResult, 1)
+	go func() {
+		result, err := t.InvokableTool.InvokableRun(runCtx, argumentsInJSON, opts...)
+		done <- toolRunResult{result, err}
+	}()
+
+	select {
+	case r := <-done:
+		t.logSlow(start, false)
+		return r.result, r.err
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			// 整轮思考被取消，不算工具超时
+			return "", ctx.Err()
+		}
+		t.logSlow(start, true)
+		return timeoutToolResult, nil
+	}
+}
+
+// logSlow 记录执行耗时，慢调用和超时时打日志
+func (t *timeoutTool) logSlow(start time.Time, timedOut bool) {
+	elapsed := time.Since(start)
+	t.timeouts.record(t.name, elapsed, timedOut)
+	if timedOut {
+		zap.L().Warn("工具执行超时", zap.String("tool", t.name), zap.Duration("timeout", t.timeout))
+	} else if elapsed >= t.timeouts.slowThreshold() {
+		zap.L().Info("工具执行较慢", zap.String("tool", t.name), zap.Duration("elapsed", elapsed))
+	}
+}
+
+// WithTimeout 为可调用工具加上执行超时和耗时统计，其他工具原样返回。
+// panic 恢复需要包在内层，工具在单独的 goroutine 中执行
+func WithTimeout(t tool.BaseTool, timeouts *ToolTimeouts) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok || timeouts == nil {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return t
+	}
+	return &timeoutTool{InvokableTool: it, name: info.Name, timeout: timeouts.timeoutFor(info.Name), timeouts: timeouts}
+}
+
+// auditOutputLimit 审计记录中输出摘要的最大字符数
+const auditOutputLimit = 500
+
+// outputSummary 截断过长的工具输出，用于审计记录和事件推送
+func outputSummary(result string) string {
+	output := []rune(result)
+	if len(output) > auditOutputLimit {
+		return string(output[:auditOutputLimit]) + "...(truncated)"
+	}
+	return result
+}
+
+// auditTool 包装工具，把每次调用的群、输入、输出摘要、耗时和是否成功写入 tool_call_logs
+type auditTool struct {
+	tool.InvokableTool
+	name string
+}
+
+func (t *auditTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	start := time.Now()
+	result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.MemoryMgr == nil {
+		return result, err
+	}
+	log := &memory.ToolCallLog{
+		GroupID:    tc.GroupID,
+		Tool:       t.name,
+		Input:      argumentsInJSON,
+		Output:     outputSummary(result),
+		DurationMs: time.Since(start).Milliseconds(),
+		Success:    err == nil && isSuccessResult(result),
+	}
+	if err != nil {
+		log.Error = err.Error()
+	}
+	if saveErr := tc.MemoryMgr.SaveToolCallLog(log); saveErr != nil {
+		zap.L().Warn("保存工具调用记录失败", zap.String("tool", t.name), zap.Error(saveErr))
+	}
+	return result, err
+}
+
+// WithAudit 为可调用工具加上调用审计记录，其他工具原样返回
+func WithAudit(t tool.BaseTool) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return t
+	}
+	return &auditTool{InvokableTool: it, name: info.Name}
+}
+
+// ToolCallEvent 工具调用事件的内容
+type ToolCallEvent struct {
+	Tool       string `json:"tool"`
+	Input      string `json:"input"`
+	Output     string `json:"output"` // 输出摘要
+	DurationMs int64  `json:"duration_ms"`
+	Success    bool   `json:"success"`
+}
+
+// eventTool 包装工具，把每次调用发布到事件总线供实时监控
+type eventTool struct {
+	tool.InvokableTool
+	name string
+	bus  *events.Bus
+}
+
+func (t *eventTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	start := time.Now()
+	result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+
+	var groupID int64
+	if tc := GetToolContext(ctx); tc != nil {
+		groupID = tc.GroupID
+	}
+	t.bus.Publish(events.TypeToolCall, groupID, &ToolCallEvent{
+		Tool:       t.name,
+		Input:      argumentsInJSON,
+		Output:     outputSummary(result),
+		DurationMs: time.Since(start).Milliseconds(),
+		Success:    err == nil && isSuccessResult(result),
+	})
+	return result, err
+}
+
+// WithEvents 为可调用工具加上调用事件发布，其他工具原样返回
+func WithEvents(t tool.BaseTool, bus *events.Bus) tool.BaseTool {
+	it, ok := t.(tool.InvokableTool)
+	if !ok || bus == nil {
+		return t
+	}
+	info, err := t.Info(context.Background())
+	if err != nil {
+		return t
+	}
+	return &eventTool{InvokableTool: it, name: info.Name, bus: bus}
+}
@@ -78,3 +78,72 @@ func NewUpdateMoodTool() (tool.InvokableTool, error) {
 		updateMoodFunc,
 	)
 }
+
+// ==================== 情绪查询工具 ====================
+
+// GetMoodInput 查询情绪的输入参数
+type GetMoodInput struct{}
+
+// MoodChangeItem 一次情绪变化
+type MoodChangeItem struct {
+	Time             string  `json:"time"`
+	ValenceDelta     float64 `json:"valence_delta"`
+	EnergyDelta      float64 `json:"energy_delta"`
+	SociabilityDelta float64 `json:"sociability_delta"`
+	Reason           string  `json:"reason,omitempty"`
+}
+
+// GetMoodOutput 查询情绪的输出
+type GetMoodOutput struct {
+	Success       bool             `json:"success"`
+	Message       string           `json:"message,omitempty"`
+	Valence       float64          `json:"valence"`     // 心情 [-1, 1]
+	Energy        float64          `json:"energy"`      // 精力 [0, 1]
+	Sociability   float64          `json:"sociability"` // 社交意愿 [0, 1]
+	RecentChanges []MoodChangeItem `json:"recent_changes,omitempty"`
+}
+
+// getMoodFunc 查询情绪的实际实现
+func getMoodFunc(ctx context.Context, _ *GetMoodInput) (*GetMoodOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &GetMoodOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.MemoryMgr == nil {
+		return &GetMoodOutput{Success: false, Message: "记忆管理器未初始化"}, nil
+	}
+
+	mood, err := tc.MemoryMgr.GetMoodState()
+	if err != nil {
+		output := &GetMoodOutput{Success: false, Message: "获取情绪失败: " + err.Error()}
+		LogToolCall("getMood", nil, output, err)
+		return output, nil
+	}
+
+	output := &GetMoodOutput{
+		Success:     true,
+		Valence:     mood.Valence,
+		Energy:      mood.Energy,
+		Sociability: mood.Sociability,
+	}
+	for _, c := range tc.MemoryMgr.GetRecentMoodChanges() {
+		output.RecentChanges = append(output.RecentChanges, MoodChangeItem{
+			Time:             c.Time.Format("01-02 15:04"),
+			ValenceDelta:     c.ValenceDelta,
+			EnergyDelta:      c.EnergyDelta,
+			SociabilityDelta: c.SociabilityDelta,
+			Reason:           c.Reason,
+		})
+	}
+	LogToolCall("getMood", nil, output, nil)
+	return output, nil
+}
+
+// NewGetMoodTool 创建查询情绪工具
+func NewGetMoodTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"getMood",
+		"查看你现在的情绪数值和最近几次情绪变化的原因（不含自然衰减）。聊了很久、想确认自己现在是什么状态时使用。",
+		getMoodFunc,
+	)
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 发起投票工具 ====================
+
+type CreatePollInput struct {
+	Question        string   `json:"question" jsonschema:"description=投票问题"`
+	Options         []string `json:"options" jsonschema:"description=选项，2~6个，尽量简短"`
+	DurationMinutes int      `json:"duration_minutes,omitempty" jsonschema:"description=多少分钟后截止并公布结果，默认30，最多1440"`
+}
+
+type CreatePollOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	PollID  uint   `json:"poll_id,omitempty"`
+	CloseAt string `json:"close_at,omitempty"`
+}
+
+func createPollFunc(ctx context.Context, input *CreatePollInput) (*CreatePollOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &CreatePollOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.CreatePollCallback == nil {
+		return &CreatePollOutput{Success: false, Message: "投票功能不可用"}, nil
+	}
+
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		return &CreatePollOutput{Success: false, Message: "投票问题不能为空"}, nil
+	}
+	options := make([]string, 0, len(input.Options))
+	for _, o := range input.Options {
+		if o = strings.TrimSpace(o); o != "" {
+			options = append(options, o)
+		}
+	}
+	minutes := input.DurationMinutes
+	if minutes <= 0 {
+		minutes = 30
+	}
+	minutes = min(minutes, 1440)
+
+	poll, err := tc.CreatePollCallback(tc.GroupID, question, options, time.Duration(minutes)*time.Minute)
+	if err != nil {
+		output := &CreatePollOutput{Success: false, Message: err.Error()}
+		LogToolCall("createPoll", input, output, err)
+		return output, nil
+	}
+
+	output := &CreatePollOutput{
+		Success: true,
+		Message: "投票已发起，截止时会自动公布结果",
+		PollID:  poll.ID,
+		CloseAt: poll.CloseAt.Format("01-02 15:04"),
+	}
+	LogToolCall("createPoll", input, output, nil)
+	return output, nil
+}
+
+func NewCreatePollTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"createPoll",
+		"在群里发起一个简单投票，群友贴表情或发编号投票，截止时自动统计并公布结果。大家意见不一、需要少数服从多数时使用。",
+		createPollFunc,
+	)
+}
+
+// ==================== 结束投票工具 ====================
+
+type ClosePollInput struct {
+	PollID uint `json:"poll_id,omitempty" jsonschema:"description=要结束的投票ID，不填则结束本群最近发起的投票"`
+}
+
+type ClosePollOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Result  string `json:"result,omitempty"`
+}
+
+func closePollFunc(ctx context.Context, input *ClosePollInput) (*ClosePollOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &ClosePollOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.ClosePollCallback == nil {
+		return &ClosePollOutput{Success: false, Message: "投票功能不可用"}, nil
+	}
+
+	var pollID uint
+	if input.PollID != 0 {
+		pollID = input.PollID
+	} else {
+		polls, err := tc.MemoryMgr.GetOpenPolls(tc.GroupID)
+		if err != nil || len(polls) == 0 {
+			output := &ClosePollOutput{Success: false, Message: "现在没有进行中的投票"}
+			LogToolCall("closePoll", input, output, err)
+			return output, nil
+		}
+		pollID = polls[0].ID
+	}
+
+	poll, err := tc.MemoryMgr.GetPoll(tc.GroupID, pollID)
+	if err != nil {
+		output := &ClosePollOutput{Success: false, Message: "投票不存在"}
+		LogToolCall("closePoll", input, output, err)
+		return output, nil
+	}
+	if poll.Closed {
+		output := &ClosePollOutput{Success: false, Message: "这个投票已经结束了", Result: poll.Result}
+		LogToolCall("closePoll", input, output, nil)
+		return output, nil
+	}
+
+	result, err := tc.ClosePollCallback(poll)
+	if err != nil && result == "" {
+		output := &ClosePollOutput{Success: false, Message: err.Error()}
+		LogToolCall("closePoll", input, output, err)
+		return output, nil
+	}
+
+	output := &ClosePollOutput{Success: true, Message: "投票已结束，结果已公布", Result: result}
+	if err != nil {
+		output.Message = "投票已结束，但" + err.Error()
+	}
+	LogToolCall("closePoll", input, output, nil)
+	return output, nil
+}
+
+func NewClosePollTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"closePoll",
+		"提前结束投票，统计票数并在群里公布结果。大家都投完了、或者发起人要求提前结束时使用。",
+		closePollFunc,
+	)
+}
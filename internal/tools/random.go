@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 掷骰子工具 ====================
+
+type RollDiceInput struct {
+	Count int `json:"count,omitempty" jsonschema:"description=骰子个数，默认1，最多10"`
+	Sides int `json:"sides,omitempty" jsonschema:"description=骰子面数，默认6，最多1000"`
+}
+
+type RollDiceOutput struct {
+	Success bool   `json:"success"`
+	Rolls   []int  `json:"rolls,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Text    string `json:"text,omitempty"` // 可以直接转述的结果
+	Message string `json:"message,omitempty"`
+}
+
+func rollDiceFunc(_ context.Context, input *RollDiceInput) (*RollDiceOutput, error) {
+	count := input.Count
+	if count <= 0 {
+		count = 1
+	}
+	sides := input.Sides
+	if sides <= 0 {
+		sides = 6
+	}
+	if count > 10 || sides > 1000 || sides < 2 {
+		return &RollDiceOutput{Success: false, Message: "最多10个骰子，面数在2到1000之间"}, nil
+	}
+
+	rolls := make([]int, count)
+	parts := make([]string, count)
+	total := 0
+	for i := range rolls {
+		rolls[i] = rand.Intn(sides) + 1
+		parts[i] = strconv.Itoa(rolls[i])
+		total += rolls[i]
+	}
+
+	text := fmt.Sprintf("%dd%d：%s", count, sides, parts[0])
+	if count > 1 {
+		text = fmt.Sprintf("%dd%d：%s = %d", count, sides, strings.Join(parts, "+"), total)
+	}
+	output := &RollDiceOutput{Success: true, Rolls: rolls, Total: total, Text: text}
+	LogToolCall("rollDice", input, output, nil)
+	return output, nil
+}
+
+func NewRollDiceTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"rollDice",
+		"掷骰子，得到真正的随机数。群友让你掷骰子、随机决定点数时使用，不要自己编数字。",
+		rollDiceFunc,
+	)
+}
+
+// ==================== 随机选择工具 ====================
+
+type RandomChoiceInput struct {
+	Options []string `json:"options" jsonschema:"description=候选项，至少2个"`
+	Count   int      `json:"count,omitempty" jsonschema:"description=选几个（不重复），默认1"`
+}
+
+type RandomChoiceOutput struct {
+	Success bool     `json:"success"`
+	Chosen  []string `json:"chosen,omitempty"`
+	Text    string   `json:"text,omitempty"` // 可以直接转述的结果
+	Message string   `json:"message,omitempty"`
+}
+
+func randomChoiceFunc(_ context.Context, input *RandomChoiceInput) (*RandomChoiceOutput, error) {
+	options := make([]string, 0, len(input.Options))
+	for _, o := range input.Options {
+		if o = strings.TrimSpace(o); o != "" {
+			options = append(options, o)
+		}
+	}
+	if len(options) < 2 {
+		return &RandomChoiceOutput{Success: false, Message: "至少要有2个候选项"}, nil
+	}
+	count := input.Count
+	if count <= 0 {
+		count = 1
+	}
+	if count > len(options) {
+		count = len(options)
+	}
+
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	chosen := options[:count]
+
+	output := &RandomChoiceOutput{Success: true, Chosen: chosen, Text: "选中了：" + strings.Join(chosen, "、")}
+	LogToolCall("randomChoice", input, output, nil)
+	return output, nil
+}
+
+func NewRandomChoiceTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"randomChoice",
+		"从几个选项里随机选，结果是真随机。群友让你帮忙随机决定（吃什么、谁去、选哪个）时使用，不要自己假装随机。",
+		randomChoiceFunc,
+	)
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// Builder 工具构造函数
+type Builder func() (tool.BaseTool, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Builder)
+)
+
+// Register 注册自定义工具，name 需与工具的 Info().Name 一致。
+// 二次开发时在自己的包里通过 init() 注册，再在 main 中匿名导入该包即可，无需修改 agent.initTools；
+// 重复注册同名工具会 panic
+func Register(name string, build Builder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if build == nil {
+		panic("tools: Register 的构造函数为 nil: " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("tools: 工具 %s 重复注册", name))
+	}
+	registry[name] = build
+}
+
+// Registered 返回所有已注册的自定义工具构造函数，按名称排序
+func Registered() []Builder {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	builders := make([]Builder, 0, len(names))
+	for _, name := range names {
+		builders = append(builders, registry[name])
+	}
+	return builders
+}
@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mumu-bot/internal/memory"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// maxReminderMinutes 提醒最长延迟（7 天）
+const maxReminderMinutes = 7 * 24 * 60
+
+// ==================== 设置提醒工具 ====================
+
+// SetReminderInput 设置提醒的输入参数
+type SetReminderInput struct {
+	// UserID 要提醒的群友QQ号
+	UserID int64 `json:"user_id" jsonschema:"description=要提醒的群友QQ号"`
+	// Nickname 要提醒的群友昵称
+	Nickname string `json:"nickname,omitempty" jsonschema:"description=要提醒的群友昵称"`
+	// Minutes 多少分钟后提醒
+	Minutes int `json:"minutes" jsonschema:"description=多少分钟后提醒，最长7天（10080分钟）"`
+	// Content 提醒内容
+	Content string `json:"content" jsonschema:"description=提醒的事情，例如：去开会、记得吃药"`
+	// CreatorID 发起提醒的群友QQ号（可选）
+	CreatorID int64 `json:"creator_id,omitempty" jsonschema:"description=让你设置提醒的群友QQ号，你自己主动设置时不填"`
+}
+
+// SetReminderOutput 设置提醒的输出
+type SetReminderOutput struct {
+	Success  bool   `json:"success"`
+	RemindAt string `json:"remind_at,omitempty"`
+	Message  string `json:"message"`
+}
+
+// setReminderFunc 设置提醒的实际实现
+func setReminderFunc(ctx context.Context, input *SetReminderInput) (*SetReminderOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &SetReminderOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	if input.UserID == 0 {
+		return &SetReminderOutput{Success: false, Message: "用户 ID 不能为空"}, nil
+	}
+	if input.Content == "" {
+		return &SetReminderOutput{Success: false, Message: "提醒内容不能为空"}, nil
+	}
+	if input.Minutes <= 0 || input.Minutes > maxReminderMinutes {
+		return &SetReminderOutput{Success: false, Message: "提醒时间必须在 1 到 10080 分钟之间"}, nil
+	}
+
+	remindAt := time.Now().Add(time.Duration(input.Minutes) * time.Minute)
+	r := &memory.Reminder{
+		GroupID:   tc.GroupID,
+		UserID:    input.UserID,
+		Nickname:  input.Nickname,
+		CreatorID: input.CreatorID,
+		Content:   input.Content,
+		RemindAt:  remindAt,
+	}
+	if err := tc.MemoryMgr.CreateReminder(r); err != nil {
+		output := &SetReminderOutput{Success: false, Message: err.Error()}
+		LogToolCall("setReminder", input, output, err)
+		return output, nil
+	}
+
+	output := &SetReminderOutput{
+		Success:  true,
+		RemindAt: remindAt.Format("2006-01-02 15:04"),
+		Message:  fmt.Sprintf("提醒已设置，到时间后会叫你去提醒 TA（提醒ID: %d）", r.ID),
+	}
+	LogToolCall("setReminder", input, output, nil)
+	return output, nil
+}
+
+// NewSetReminderTool 创建设置提醒工具
+func NewSetReminderTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"setReminder",
+		`设置一个定时提醒，到时间后你会在群里 @ 对应的群友提醒 TA。
+- 当有群友让你"X分钟后提醒我……"、"明天早上叫我……"时使用
+- 需要自己把时间换算成分钟数，不确定当前时间时先用 getCurrentTime
+- 设置成功后记得用 speak 答应一声`,
+		setReminderFunc,
+	)
+}
+
+// ==================== 查看提醒工具 ====================
+
+// ListRemindersInput 查看提醒的输入参数
+type ListRemindersInput struct {
+	// UserID 只看提醒某个群友的（可选）
+	UserID int64 `json:"user_id,omitempty" jsonschema:"description=只看提醒这个群友的，不填则列出本群全部"`
+}
+
+// ReminderItem 提醒条目
+type ReminderItem struct {
+	ID        uint   `json:"id"`
+	UserID    int64  `json:"user_id"`
+	Nickname  string `json:"nickname,omitempty"`
+	CreatorID int64  `json:"creator_id,omitempty"`
+	Content   string `json:"content"`
+	RemindAt  string `json:"remind_at"`
+}
+
+// ListRemindersOutput 查看提醒的输出
+type ListRemindersOutput struct {
+	Success   bool           `json:"success"`
+	Reminders []ReminderItem `json:"reminders,omitempty"`
+	Message   string         `json:"message"`
+}
+
+// listRemindersFunc 查看提醒的实际实现
+func listRemindersFunc(ctx context.Context, input *ListRemindersInput) (*ListRemindersOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &ListRemindersOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+
+	reminders, err := tc.MemoryMgr.GetPendingReminders(tc.GroupID, input.UserID, 20)
+	if err != nil {
+		output := &ListRemindersOutput{Success: false, Message: err.Error()}
+		LogToolCall("listReminders", input, output, err)
+		return output, nil
+	}
+
+	items := make([]ReminderItem, 0, len(reminders))
+	for _, r := range reminders {
+		items = append(items, ReminderItem{
+			ID:        r.ID,
+			UserID:    r.UserID,
+			Nickname:  r.Nickname,
+			CreatorID: r.CreatorID,
+			Content:   r.Content,
+			RemindAt:  r.RemindAt.Format("2006-01-02 15:04"),
+		})
+	}
+	output := &ListRemindersOutput{
+		Success:   true,
+		Reminders: items,
+		Message:   fmt.Sprintf("还有 %d 条提醒没到时间", len(items)),
+	}
+	LogToolCall("listReminders", input, output, nil)
+	return output, nil
+}
+
+// NewListRemindersTool 创建查看提醒工具
+func NewListRemindersTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"listReminders",
+		"查看本群还没到时间的提醒。群友问\"我让你提醒我什么来着\"或者想取消提醒时先用它查到提醒ID。",
+		listRemindersFunc,
+	)
+}
+
+// ==================== 取消提醒工具 ====================
+
+// CancelReminderInput 取消提醒的输入参数
+type CancelReminderInput struct {
+	// ReminderID 要取消的提醒ID
+	ReminderID uint `json:"reminder_id" jsonschema:"description=要取消的提醒ID（从listReminders或setReminder的结果获取）"`
+}
+
+// CancelReminderOutput 取消提醒的输出
+type CancelReminderOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// cancelReminderFunc 取消提醒的实际实现
+func cancelReminderFunc(ctx context.Context, input *CancelReminderInput) (*CancelReminderOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &CancelReminderOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.ReminderID == 0 {
+		return &CancelReminderOutput{Success: false, Message: "提醒 ID 不能为空"}, nil
+	}
+
+	ok, err := tc.MemoryMgr.CancelReminder(tc.GroupID, input.ReminderID)
+	if err != nil {
+		output := &CancelReminderOutput{Success: false, Message: err.Error()}
+		LogToolCall("cancelReminder", input, output, err)
+		return output, nil
+	}
+	if !ok {
+		output := &CancelReminderOutput{Success: false, Message: "没有找到这条提醒，可能已经提醒过了"}
+		LogToolCall("cancelReminder", input, output, nil)
+		return output, nil
+	}
+
+	output := &CancelReminderOutput{Success: true, Message: "提醒已取消"}
+	LogToolCall("cancelReminder", input, output, nil)
+	return output, nil
+}
+
+// NewCancelReminderTool 创建取消提醒工具
+func NewCancelReminderTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"cancelReminder",
+		"取消一条还没到时间的提醒。群友说\"不用提醒我了\"时使用，取消后记得用 speak 告诉 TA。",
+		cancelReminderFunc,
+	)
+}
@@ -2,24 +2,37 @@ package tools
 
 import (
 	"context"
+	"encoding/base64"
 	"mumu-bot/internal/config"
+	"mumu-bot/internal/llm"
+	"mumu-bot/internal/memory"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+	"go.uber.org/zap"
 )
 
 // ==================== 搜索表情包工具 ====================
 
 type SearchStickersInput struct {
 	Keyword string `json:"keyword" jsonschema:"description=按描述关键词搜索，如：猫、开心、无语等"`
+	Emotion string `json:"emotion,omitempty" jsonschema:"enum=开心,enum=难过,enum=生气,enum=惊讶,enum=无语,enum=害怕,enum=得意,enum=害羞,enum=疑惑,enum=其他,description=按情绪筛选（可选）"`
+	Subject string `json:"subject,omitempty" jsonschema:"description=按画面主体筛选，如猫、熊猫头（可选）"`
+	HasText *bool  `json:"has_text,omitempty" jsonschema:"description=是否带字（可选，不填不筛选）"`
 	Limit   int    `json:"limit,omitempty" jsonschema:"description=返回数量，默认10"`
 }
 
 type StickerSummary struct {
 	ID          uint   `json:"id"`
 	Description string `json:"description"`
+	Emotion     string `json:"emotion,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Text        string `json:"text,omitempty"`
 	UseCount    int    `json:"use_count"`
 }
 
@@ -40,7 +53,8 @@ func searchStickersFunc(ctx context.Context, input *SearchStickersInput) (*Searc
 		limit = 10
 	}
 
-	stickers, err := tc.MemoryMgr.SearchStickers(input.Keyword, limit)
+	filter := memory.StickerFilter{GroupID: tc.GroupID, Emotion: input.Emotion, Subject: input.Subject, HasText: input.HasText}
+	stickers, err := tc.MemoryMgr.SearchStickers(input.Keyword, filter, limit)
 	if err != nil {
 		output := &SearchStickersOutput{Success: false, Message: "搜索失败: " + err.Error()}
 		LogToolCall("searchStickers", input, output, err)
@@ -58,6 +72,9 @@ func searchStickersFunc(ctx context.Context, input *SearchStickersInput) (*Searc
 		results = append(results, StickerSummary{
 			ID:          s.ID,
 			Description: s.Description,
+			Emotion:     s.Emotion,
+			Subject:     s.Subject,
+			Text:        s.Text,
 			UseCount:    s.UseCount,
 		})
 	}
@@ -70,7 +87,7 @@ func searchStickersFunc(ctx context.Context, input *SearchStickersInput) (*Searc
 func NewSearchStickersTool() (tool.InvokableTool, error) {
 	return utils.InferTool(
 		"searchStickers",
-		"通过关键词搜索已保存的表情包，关键词用空格隔开。",
+		"通过关键词搜索已保存的表情包，关键词用空格隔开。可以用 emotion、subject、has_text 按标签筛选，比如想找一张带字的无语表情。",
 		searchStickersFunc,
 	)
 }
@@ -154,3 +171,193 @@ func NewSendStickerTool() (tool.InvokableTool, error) {
 		sendStickerFunc,
 	)
 }
+
+// ==================== 删除表情包工具 ====================
+
+type DeleteStickerInput struct {
+	StickerID uint   `json:"sticker_id" jsonschema:"description=要删除的表情包ID（从searchStickers获取）"`
+	Reason    string `json:"reason,omitempty" jsonschema:"description=删除原因，例如：描述不对、内容不合适"`
+}
+
+type DeleteStickerOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func deleteStickerFunc(ctx context.Context, input *DeleteStickerInput) (*DeleteStickerOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &DeleteStickerOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.StickerID == 0 {
+		return &DeleteStickerOutput{Success: false, Message: "表情包 ID 不能为空"}, nil
+	}
+
+	sticker, err := tc.MemoryMgr.DeleteSticker(input.StickerID, tc.GroupID)
+	if err != nil {
+		output := &DeleteStickerOutput{Success: false, Message: "表情包不存在"}
+		LogToolCall("deleteSticker", input, output, err)
+		return output, nil
+	}
+
+	zap.L().Info("删除表情包", zap.Uint("id", sticker.ID), zap.String("desc", sticker.Description), zap.String("reason", input.Reason))
+	output := &DeleteStickerOutput{Success: true, Message: "表情包已删除"}
+	LogToolCall("deleteSticker", input, output, nil)
+	return output, nil
+}
+
+func NewDeleteStickerTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"deleteSticker",
+		"删除一个收藏的表情包。发现表情包内容不合适、或者描述完全对不上没法用时使用。",
+		deleteStickerFunc,
+	)
+}
+
+// ==================== 重新描述表情包工具 ====================
+
+type RelabelStickerInput struct {
+	StickerID   uint   `json:"sticker_id" jsonschema:"description=要重新描述的表情包ID（从searchStickers获取）"`
+	Description string `json:"description,omitempty" jsonschema:"description=新的描述。不填则重新调用视觉模型识别"`
+	Emotion     string `json:"emotion,omitempty" jsonschema:"enum=开心,enum=难过,enum=生气,enum=惊讶,enum=无语,enum=害怕,enum=得意,enum=害羞,enum=疑惑,enum=其他,description=情绪标签（可选，填了description时生效）"`
+	Subject     string `json:"subject,omitempty" jsonschema:"description=画面主体（可选，填了description时生效）"`
+	Text        string `json:"text,omitempty" jsonschema:"description=图上的文字（可选，填了description时生效）"`
+}
+
+type RelabelStickerOutput struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+	Description string `json:"description,omitempty"`
+}
+
+func relabelStickerFunc(ctx context.Context, input *RelabelStickerInput) (*RelabelStickerOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &RelabelStickerOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.StickerID == 0 {
+		return &RelabelStickerOutput{Success: false, Message: "表情包 ID 不能为空"}, nil
+	}
+
+	sticker, err := tc.MemoryMgr.GetStickerByID(input.StickerID)
+	if err != nil {
+		output := &RelabelStickerOutput{Success: false, Message: "表情包不存在"}
+		LogToolCall("relabelSticker", input, output, err)
+		return output, nil
+	}
+
+	labels := &memory.Sticker{
+		Description: strings.TrimSpace(input.Description),
+		Emotion:     sticker.Emotion,
+		Subject:     sticker.Subject,
+		HasText:     sticker.HasText,
+		Text:        sticker.Text,
+	}
+	if labels.Description != "" {
+		// 直接使用给出的描述，标签只覆盖填了的部分
+		if slices.Contains(llm.StickerEmotions, input.Emotion) {
+			labels.Emotion = input.Emotion
+		}
+		if input.Subject != "" {
+			labels.Subject = input.Subject
+		}
+		if input.Text != "" {
+			labels.HasText, labels.Text = true, input.Text
+		}
+	} else {
+		// 重新调用视觉模型识别本地文件
+		if tc.Vision == nil {
+			return &RelabelStickerOutput{Success: false, Message: "视觉模型未启用，请直接给出新描述"}, nil
+		}
+		storagePath := config.Get().Sticker.StoragePath
+		if storagePath == "" {
+			storagePath = "./stickers"
+		}
+		data, err := os.ReadFile(filepath.Join(storagePath, sticker.FileName))
+		if err != nil {
+			output := &RelabelStickerOutput{Success: false, Message: "表情包文件不存在"}
+			LogToolCall("relabelSticker", input, output, err)
+			return output, nil
+		}
+		dataURL := "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+		info, err := tc.Vision.DescribeSticker(ctx, dataURL)
+		if err != nil {
+			output := &RelabelStickerOutput{Success: false, Message: "识别失败: " + err.Error()}
+			LogToolCall("relabelSticker", input, output, err)
+			return output, nil
+		}
+		labels.Description = info.Description
+		labels.Emotion, labels.Subject, labels.HasText, labels.Text = info.Emotion, info.Subject, info.HasText, info.Text
+	}
+
+	if _, err := tc.MemoryMgr.UpdateStickerLabels(sticker.ID, tc.GroupID, labels); err != nil {
+		output := &RelabelStickerOutput{Success: false, Message: "表情包不存在"}
+		LogToolCall("relabelSticker", input, output, err)
+		return output, nil
+	}
+
+	output := &RelabelStickerOutput{Success: true, Message: "表情包描述已更新", Description: labels.Description}
+	LogToolCall("relabelSticker", input, output, nil)
+	return output, nil
+}
+
+func NewRelabelStickerTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"relabelSticker",
+		"重新描述一个表情包，让之后更容易搜到。发现描述和实际内容对不上时使用：可以直接给出新描述，也可以不填描述让视觉模型重新识别。",
+		relabelStickerFunc,
+	)
+}
+
+// ==================== 收藏消息中的表情包工具 ====================
+
+type SaveStickerFromMessageInput struct {
+	MessageID int64 `json:"message_id" jsonschema:"description=包含表情包或图片的消息ID"`
+}
+
+type SavedSticker struct {
+	ID          uint   `json:"id"`
+	Description string `json:"description"`
+}
+
+type SaveStickerFromMessageOutput struct {
+	Success    bool           `json:"success"`
+	Message    string         `json:"message"`
+	Saved      []SavedSticker `json:"saved,omitempty"`
+	Duplicates int            `json:"duplicates,omitempty"` // 之前已经收藏过的数量
+}
+
+func saveStickerFromMessageFunc(ctx context.Context, input *SaveStickerFromMessageInput) (*SaveStickerFromMessageOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.SaveStickerCallback == nil {
+		return &SaveStickerFromMessageOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.MessageID == 0 {
+		return &SaveStickerFromMessageOutput{Success: false, Message: "消息 ID 不能为空"}, nil
+	}
+
+	stickers, duplicates, err := tc.SaveStickerCallback(tc.GroupID, input.MessageID)
+	if err != nil {
+		output := &SaveStickerFromMessageOutput{Success: false, Message: err.Error()}
+		LogToolCall("saveStickerFromMessage", input, output, err)
+		return output, nil
+	}
+
+	output := &SaveStickerFromMessageOutput{Success: true, Message: "已收藏", Duplicates: duplicates}
+	for _, s := range stickers {
+		output.Saved = append(output.Saved, SavedSticker{ID: s.ID, Description: s.Description})
+	}
+	if len(stickers) == 0 {
+		output.Message = "之前已经收藏过了"
+	}
+	LogToolCall("saveStickerFromMessage", input, output, nil)
+	return output, nil
+}
+
+func NewSaveStickerFromMessageTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"saveStickerFromMessage",
+		"收藏指定消息里的表情包或图片，之后可以用 searchStickers 找到。群友让你\"存一下这个表情\"、或者你很喜欢某个表情包时使用。",
+		saveStickerFromMessageFunc,
+	)
+}
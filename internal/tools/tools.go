@@ -2,10 +2,16 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mumu-bot/internal/config"
+	"mumu-bot/internal/game"
+	"mumu-bot/internal/llm"
 	"mumu-bot/internal/memory"
 	"mumu-bot/internal/onebot"
+	"mumu-bot/internal/weather"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -16,16 +22,62 @@ import (
 	"go.uber.org/zap"
 )
 
-// SpeakCallback 发言回调函数类型，返回消息ID
-type SpeakCallback func(groupID int64, content string, replyTo int64, mentions []int64) int64
+// ErrReplyTargetInvalid 回复目标消息不存在或过旧，已降级为普通发言
+// 发言回调在降级发送成功时会同时返回消息ID和该错误
+var ErrReplyTargetInvalid = errors.New("要回复的消息不存在或太旧了，已改为直接发言，不要再回复这条消息")
+
+// SpeakCallback 发言回调函数类型，返回消息ID；返回错误时错误信息会反馈给 LLM
+type SpeakCallback func(groupID int64, content string, replyTo int64, mentions []int64) (int64, error)
+
+// VoiceCallback 语音发言回调函数类型，把文字合成语音发送，返回消息ID
+type VoiceCallback func(groupID int64, content string) (int64, error)
+
+// PrivateCallback 私聊发送回调函数类型，groupID 为发起私聊时所在的群，返回消息ID
+type PrivateCallback func(groupID, userID int64, content string) (int64, error)
+
+// ForwardCallback 合并转发发送回调函数类型，返回消息ID
+type ForwardCallback func(groupID int64, nodes []onebot.ForwardMessage) (int64, error)
+
+// CreatePollCallback 发起投票回调函数类型，发送投票消息并保存
+type CreatePollCallback func(groupID int64, question string, options []string, duration time.Duration) (*memory.Poll, error)
+
+// ClosePollCallback 结束投票回调函数类型，统计并公布结果，返回结果文本
+type ClosePollCallback func(p *memory.Poll) (string, error)
+
+// SaveStickerCallback 收藏指定消息中图片的回调函数类型，返回新收藏的表情包和已收藏过的数量
+type SaveStickerCallback func(groupID, messageID int64) ([]memory.Sticker, int, error)
+
+// ForwardToGroupCallback 跨群转发回调函数类型，把当前群的一条消息转发到目标群
+type ForwardToGroupCallback func(fromGroupID, toGroupID, messageID int64) error
+
+// SummarizeCallback 长内容总结回调函数类型，focus 为可选的关注点
+type SummarizeCallback func(ctx context.Context, groupID int64, content, focus string) (string, error)
+
+// DrawCallback 画图回调函数类型，根据提示词生成图片并发送，返回消息ID
+type DrawCallback func(groupID int64, prompt string) (int64, error)
 
 // ToolContext 工具执行上下文
 type ToolContext struct {
 	GroupID       int64
 	MemoryMgr     *memory.Manager
 	Bot           *onebot.Client
-	SpeakCallback SpeakCallback // 发言回调
-	StopThinking  func()        // 停止思考回调（用于 stayQuiet 强制停止）
+	Games         *game.Manager     // 小游戏管理器
+	Vision        *llm.VisionClient // 视觉模型（可能为 nil）
+	Weather       *weather.Client   // 天气查询（可能为 nil）
+	SpeakCallback SpeakCallback     // 发言回调
+	VoiceCallback VoiceCallback     // 语音发言回调
+	DrawCallback  DrawCallback      // 画图回调
+
+	PrivateCallback PrivateCallback // 私聊发送回调
+	ForwardCallback ForwardCallback // 合并转发发送回调
+
+	ForwardToGroupCallback ForwardToGroupCallback // 跨群转发回调
+	SummarizeCallback      SummarizeCallback      // 长内容总结回调
+
+	CreatePollCallback  CreatePollCallback  // 发起投票回调
+	ClosePollCallback   ClosePollCallback   // 结束投票回调
+	SaveStickerCallback SaveStickerCallback // 收藏消息中表情包的回调
+	StopThinking        func()              // 停止思考回调（用于 stayQuiet 强制停止）
 }
 
 // ctxKey 上下文键类型
@@ -125,6 +177,9 @@ type GetGroupInfoOutput struct {
 	GroupName      string `json:"group_name,omitempty"`
 	MemberCount    int    `json:"member_count,omitempty"`
 	MaxMemberCount int    `json:"max_member_count,omitempty"`
+	Atmosphere     string `json:"atmosphere,omitempty"`
+	HotTopics      string `json:"hot_topics,omitempty"`
+	Rules          string `json:"rules,omitempty"`
 }
 
 // getGroupInfoFunc 获取群信息的实际实现
@@ -155,6 +210,11 @@ func getGroupInfoFunc(ctx context.Context, input *GetGroupInfoInput) (*GetGroupI
 		MemberCount:    info.MemberCount,
 		MaxMemberCount: info.MaxMemberCount,
 	}
+	if gi, err := tc.MemoryMgr.GetGroupInfo(tc.GroupID); err == nil {
+		output.Atmosphere = gi.Atmosphere
+		output.HotTopics = gi.HotTopics
+		output.Rules = gi.Rules
+	}
 	LogToolCall("getGroupInfo", input, output, nil)
 	return output, nil
 }
@@ -168,6 +228,67 @@ func NewGetGroupInfoTool() (tool.InvokableTool, error) {
 	)
 }
 
+// ==================== 更新群信息工具 ====================
+
+// UpdateGroupInfoInput 更新群信息的输入参数
+type UpdateGroupInfoInput struct {
+	// Atmosphere 群氛围
+	Atmosphere string `json:"atmosphere,omitempty" jsonschema:"description=群氛围，如：轻松爱玩梗、技术讨论为主、比较安静（100字以内）"`
+	// HotTopics 最近的热点话题
+	HotTopics string `json:"hot_topics,omitempty" jsonschema:"description=最近群里反复聊的热点话题（100字以内）"`
+	// Rules 群规或约定
+	Rules string `json:"rules,omitempty" jsonschema:"description=群规或大家约定俗成的规矩"`
+}
+
+// UpdateGroupInfoOutput 更新群信息的输出
+type UpdateGroupInfoOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// updateGroupInfoFunc 更新群信息的实际实现
+func updateGroupInfoFunc(ctx context.Context, input *UpdateGroupInfoInput) (*UpdateGroupInfoOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &UpdateGroupInfoOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.Atmosphere == "" && input.HotTopics == "" && input.Rules == "" {
+		return &UpdateGroupInfoOutput{Success: false, Message: "没有需要更新的内容"}, nil
+	}
+
+	info, err := tc.MemoryMgr.GetGroupInfo(tc.GroupID)
+	if err != nil {
+		info = &memory.GroupInfo{GroupID: tc.GroupID}
+	}
+	if input.Atmosphere != "" {
+		info.Atmosphere = input.Atmosphere
+	}
+	if input.HotTopics != "" {
+		info.HotTopics = input.HotTopics
+	}
+	if input.Rules != "" {
+		info.Rules = input.Rules
+	}
+	if err := tc.MemoryMgr.SaveGroupInfo(info); err != nil {
+		output := &UpdateGroupInfoOutput{Success: false, Message: err.Error()}
+		LogToolCall("updateGroupInfo", input, output, err)
+		return output, nil
+	}
+
+	output := &UpdateGroupInfoOutput{Success: true, Message: "已更新对这个群的了解"}
+	LogToolCall("updateGroupInfo", input, output, nil)
+	return output, nil
+}
+
+// NewUpdateGroupInfoTool 创建更新群信息工具
+func NewUpdateGroupInfoTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"updateGroupInfo",
+		"更新你对当前群的整体印象：群氛围、最近的热点话题、群规。只在有明显变化时使用，不填的字段保持不变。",
+		updateGroupInfoFunc,
+	)
+}
+
 // ==================== 获取群成员详情工具 ====================
 
 // GetGroupMemberDetailInput 获取群成员详情的输入参数
@@ -240,6 +361,97 @@ func NewGetGroupMemberDetailTool() (tool.InvokableTool, error) {
 	)
 }
 
+// ==================== 列出群成员工具 ====================
+
+type ListGroupMembersInput struct {
+	Role     string `json:"role,omitempty" jsonschema:"enum=owner,enum=admin,enum=member,description=按角色过滤（可选）：owner群主、admin管理员、member普通成员"`
+	Page     int    `json:"page,omitempty" jsonschema:"description=页码，从1开始，默认1"`
+	PageSize int    `json:"page_size,omitempty" jsonschema:"description=每页人数，默认20，最多50"`
+}
+
+type GroupMemberBrief struct {
+	UserID       int64  `json:"user_id"`
+	Nickname     string `json:"nickname,omitempty"`
+	Card         string `json:"card,omitempty"`
+	Role         string `json:"role,omitempty"`
+	LastSentTime string `json:"last_sent_time,omitempty"`
+}
+
+type ListGroupMembersOutput struct {
+	Success bool               `json:"success"`
+	Total   int                `json:"total"`
+	Members []GroupMemberBrief `json:"members,omitempty"`
+	Message string             `json:"message,omitempty"`
+}
+
+// listGroupMembersFunc 列出群成员，按最后发言时间倒序
+func listGroupMembersFunc(ctx context.Context, input *ListGroupMembersInput) (*ListGroupMembersOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &ListGroupMembersOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Bot == nil {
+		return &ListGroupMembersOutput{Success: false, Message: "Bot 未连接"}, nil
+	}
+
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 50 {
+		pageSize = 50
+	}
+
+	members, err := tc.Bot.GetGroupMemberList(tc.GroupID, false)
+	if err != nil {
+		output := &ListGroupMembersOutput{Success: false, Message: err.Error()}
+		LogToolCall("listGroupMembers", input, output, err)
+		return output, nil
+	}
+
+	filtered := make([]*onebot.GroupMemberInfo, 0, len(members))
+	for _, mb := range members {
+		if input.Role == "" || mb.Role == input.Role {
+			filtered = append(filtered, mb)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].LastSentTime > filtered[j].LastSentTime
+	})
+
+	output := &ListGroupMembersOutput{Success: true, Total: len(filtered)}
+	// 先按页数比较再相乘，避免页码过大时溢出
+	if page-1 < (len(filtered)+pageSize-1)/pageSize {
+		start := (page - 1) * pageSize
+		end := min(start+pageSize, len(filtered))
+		for _, mb := range filtered[start:end] {
+			brief := GroupMemberBrief{UserID: mb.UserID, Nickname: mb.Nickname, Card: mb.Card, Role: mb.Role}
+			if mb.LastSentTime > 0 {
+				brief.LastSentTime = time.Unix(mb.LastSentTime, 0).Format("2006-01-02 15:04")
+			}
+			output.Members = append(output.Members, brief)
+		}
+	}
+	if len(output.Members) == 0 {
+		output.Message = "这一页没有成员了"
+	}
+	LogToolCall("listGroupMembers", input, output, nil)
+	return output, nil
+}
+
+// NewListGroupMembersTool 创建列出群成员工具
+func NewListGroupMembersTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"listGroupMembers",
+		"列出群成员，按最后发言时间从近到远排序，可按角色过滤、分页。想知道群里有哪些管理员、最近谁比较活跃时使用。",
+		listGroupMembersFunc,
+	)
+}
+
 // ==================== 获取短期记忆工具 ====================
 
 type GetRecentMessagesInput struct {
@@ -292,6 +504,142 @@ func NewGetRecentMessagesTool() (tool.InvokableTool, error) {
 	)
 }
 
+// ==================== 拉取群历史消息工具 ====================
+
+// maxGroupHistory 单次最多拉取的历史消息数
+const maxGroupHistory = 50
+
+type GetGroupHistoryInput struct {
+	Count       int   `json:"count,omitempty" jsonschema:"description=拉取多少条，默认20，最多50"`
+	BeforeMsgID int64 `json:"before_message_id,omitempty" jsonschema:"description=从这条消息往前拉取（翻页时填上一次结果里最早的消息ID），不填则从最新的消息开始"`
+}
+
+type GetGroupHistoryOutput struct {
+	Success  bool                     `json:"success"`
+	Messages []map[string]interface{} `json:"messages,omitempty"`
+	Message  string                   `json:"message,omitempty"`
+}
+
+func getGroupHistoryFunc(ctx context.Context, input *GetGroupHistoryInput) (*GetGroupHistoryOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &GetGroupHistoryOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Bot == nil {
+		return &GetGroupHistoryOutput{Success: false, Message: "Bot 未连接"}, nil
+	}
+
+	count := input.Count
+	if count <= 0 {
+		count = 20
+	}
+	count = min(count, maxGroupHistory)
+
+	messages, err := tc.Bot.GetGroupMsgHistory(tc.GroupID, input.BeforeMsgID, count)
+	if err != nil {
+		output := &GetGroupHistoryOutput{Success: false, Message: "获取群历史消息失败: " + err.Error()}
+		LogToolCall("getGroupHistory", input, output, err)
+		return output, nil
+	}
+
+	results := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		results = append(results, map[string]interface{}{
+			"message_id": m.MessageID,
+			"user_id":    m.UserID,
+			"nickname":   m.Nickname,
+			"content":    m.Content,
+			"time":       m.Time.Format("01-02 15:04:05"),
+		})
+	}
+
+	output := &GetGroupHistoryOutput{Success: true, Messages: results}
+	LogToolCall("getGroupHistory", input, output, nil)
+	return output, nil
+}
+
+func NewGetGroupHistoryTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"getGroupHistory",
+		"从 QQ 拉取群里的历史消息（包括你离线期间漏掉的）。getRecentMessages 查不到想要的内容、或者怀疑漏了消息时使用。",
+		getGroupHistoryFunc,
+	)
+}
+
+// ==================== 查询消息详情工具 ====================
+
+type GetMessageDetailInput struct {
+	MessageID int64 `json:"message_id" jsonschema:"description=要查询的消息ID"`
+}
+
+type GetMessageDetailOutput struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message,omitempty"`
+	MessageID   int64  `json:"message_id,omitempty"`
+	UserID      int64  `json:"user_id,omitempty"`
+	Nickname    string `json:"nickname,omitempty"`
+	Time        string `json:"time,omitempty"`
+	Content     string `json:"content,omitempty"`      // 消息内容（本地记录优先，含图片描述等）
+	RawContent  string `json:"raw_content,omitempty"`  // QQ 上的原始文本，与 content 不同时才返回
+	IsMentioned bool   `json:"is_mentioned,omitempty"` // 是否 @ 了你
+	Source      string `json:"source,omitempty"`       // 数据来源：local、qq 或 both
+}
+
+// getMessageDetailFunc 整合本地消息记录和 OneBot get_msg 查询单条消息
+func getMessageDetailFunc(ctx context.Context, input *GetMessageDetailInput) (*GetMessageDetailOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &GetMessageDetailOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if input.MessageID == 0 {
+		return &GetMessageDetailOutput{Success: false, Message: "消息 ID 不能为空"}, nil
+	}
+
+	output := &GetMessageDetailOutput{Success: true, MessageID: input.MessageID}
+
+	// 只返回本群的消息
+	if log, err := tc.MemoryMgr.GetMessageLogByID(fmt.Sprintf("%d", input.MessageID)); err == nil && log.GroupID == tc.GroupID {
+		output.UserID = log.UserID
+		output.Nickname = log.Nickname
+		output.Time = log.CreatedAt.Format("2006-01-02 15:04:05")
+		output.Content = log.Content
+		output.IsMentioned = log.IsMentioned
+		output.Source = "local"
+	}
+
+	if tc.Bot != nil {
+		if hm, err := tc.Bot.GetMessageDetail(input.MessageID); err == nil && hm.GroupID == tc.GroupID {
+			if output.Source == "" {
+				output.UserID = hm.UserID
+				output.Nickname = hm.Nickname
+				output.Time = hm.Time.Format("2006-01-02 15:04:05")
+				output.Content = hm.Content
+				output.Source = "qq"
+			} else {
+				if hm.Content != output.Content {
+					output.RawContent = hm.Content
+				}
+				output.Source = "both"
+			}
+		}
+	}
+
+	if output.Source == "" {
+		output = &GetMessageDetailOutput{Success: false, Message: "找不到这条消息（可能太久远或不是本群的消息）"}
+	}
+	LogToolCall("getMessageDetail", input, output, nil)
+	return output, nil
+}
+
+// NewGetMessageDetailTool 创建查询消息详情工具
+func NewGetMessageDetailTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"getMessageDetail",
+		"按消息ID查询某条消息的完整内容、发送者和时间。需要确认某条消息到底是谁说的、说了什么时使用。",
+		getMessageDetailFunc,
+	)
+}
+
 // ==================== 获取群公告工具 ====================
 
 type GetGroupNoticesInput struct {
@@ -546,6 +894,113 @@ func NewGetForwardMessageDetailTool() (tool.InvokableTool, error) {
 	)
 }
 
+// ==================== 长内容总结工具 ====================
+
+// 长内容总结的长度限制（字符）
+const (
+	summarizeMinLength = 300   // 短于该长度直接返回原文
+	summarizeMaxLength = 12000 // 超出部分截断后再总结
+)
+
+// SummarizeContentInput 长内容总结的输入参数
+type SummarizeContentInput struct {
+	// MessageID 要总结的消息ID
+	MessageID int64 `json:"message_id" jsonschema:"description=要总结的消息ID（长文或合并转发）"`
+	// Focus 关注点
+	Focus string `json:"focus,omitempty" jsonschema:"description=可选，想重点了解的内容，如'大家最后的结论'"`
+}
+
+// SummarizeContentOutput 长内容总结的输出
+type SummarizeContentOutput struct {
+	Success   bool   `json:"success"`
+	Summary   string `json:"summary,omitempty"`
+	Length    int    `json:"length,omitempty"`    // 原文长度（字符）
+	Truncated bool   `json:"truncated,omitempty"` // 原文过长，只总结了前面部分
+	Message   string `json:"message,omitempty"`
+}
+
+// messageFullContent 获取本群一条消息的完整内容，合并转发会展开成逐条对话
+func messageFullContent(tc *ToolContext, messageID int64) (string, bool) {
+	if log, err := tc.MemoryMgr.GetMessageLogByID(fmt.Sprintf("%d", messageID)); err == nil && log.GroupID == tc.GroupID {
+		var forwards []onebot.ForwardMessage
+		if log.Forwards != "" && sonic.UnmarshalString(log.Forwards, &forwards) == nil && len(forwards) > 0 {
+			return formatForwards(forwards), true
+		}
+		return log.Content, true
+	}
+	if tc.Bot != nil {
+		if hm, err := tc.Bot.GetMessageDetail(messageID); err == nil && hm.GroupID == tc.GroupID {
+			var forwards []onebot.ForwardMessage
+			for _, id := range hm.ForwardIDs {
+				if nodes, err := tc.Bot.GetForwardMsg(id); err == nil {
+					forwards = append(forwards, nodes...)
+				}
+			}
+			if len(forwards) > 0 {
+				return formatForwards(forwards), true
+			}
+			return hm.Content, true
+		}
+	}
+	return "", false
+}
+
+// formatForwards 把合并转发展开成逐条对话
+func formatForwards(forwards []onebot.ForwardMessage) string {
+	var b strings.Builder
+	for _, f := range forwards {
+		b.WriteString(f.Nickname + "：" + f.Content + "\n")
+	}
+	return b.String()
+}
+
+// summarizeContentFunc 长内容总结的实际实现
+func summarizeContentFunc(ctx context.Context, input *SummarizeContentInput) (*SummarizeContentOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil || tc.SummarizeCallback == nil {
+		return &SummarizeContentOutput{Success: false, Message: "总结功能未启用"}, nil
+	}
+	if input.MessageID == 0 {
+		return &SummarizeContentOutput{Success: false, Message: "消息 ID 不能为空"}, nil
+	}
+
+	content, ok := messageFullContent(tc, input.MessageID)
+	if !ok {
+		output := &SummarizeContentOutput{Success: false, Message: "找不到这条消息（可能太久远或不是本群的消息）"}
+		LogToolCall("summarizeContent", input, output, nil)
+		return output, nil
+	}
+	runes := []rune(strings.TrimSpace(content))
+	output := &SummarizeContentOutput{Success: true, Length: len(runes)}
+	if len(runes) < summarizeMinLength {
+		output.Summary, output.Message = string(runes), "内容不长，直接给你原文"
+		LogToolCall("summarizeContent", input, output, nil)
+		return output, nil
+	}
+	if len(runes) > summarizeMaxLength {
+		runes, output.Truncated = runes[:summarizeMaxLength], true
+	}
+
+	summary, err := tc.SummarizeCallback(ctx, tc.GroupID, string(runes), input.Focus)
+	if err != nil {
+		output = &SummarizeContentOutput{Success: false, Message: "总结失败: " + err.Error()}
+		LogToolCall("summarizeContent", input, output, err)
+		return output, nil
+	}
+	output.Summary = summary
+	LogToolCall("summarizeContent", input, output, nil)
+	return output, nil
+}
+
+// NewSummarizeContentTool 创建长内容总结工具
+func NewSummarizeContentTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"summarizeContent",
+		"总结一条长消息或合并转发的完整内容。聊天记录里的长文、合并转发只显示了一部分，想知道讲了什么时使用。",
+		summarizeContentFunc,
+	)
+}
+
 // ==================== 网页浏览工具 ====================
 
 // httpRequestToolWrapper 包装 HTTP 请求工具以添加日志记录
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"mumu-bot/internal/weather"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ==================== 天气查询工具 ====================
+
+type GetWeatherInput struct {
+	City string `json:"city,omitempty" jsonschema:"description=城市名，如杭州、北京朝阳。不填查询默认城市"`
+	Days int    `json:"days,omitempty" jsonschema:"description=预报天数（含今天），1~3，默认3"`
+}
+
+type GetWeatherOutput struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message,omitempty"`
+	Report  *weather.Report `json:"report,omitempty"`
+}
+
+func getWeatherFunc(ctx context.Context, input *GetWeatherInput) (*GetWeatherOutput, error) {
+	tc := GetToolContext(ctx)
+	if tc == nil {
+		return &GetWeatherOutput{Success: false, Message: "工具上下文未初始化"}, nil
+	}
+	if tc.Weather == nil {
+		return &GetWeatherOutput{Success: false, Message: "天气查询未启用"}, nil
+	}
+
+	days := input.Days
+	if days <= 0 || days > 3 {
+		days = 3
+	}
+
+	report, err := tc.Weather.Query(ctx, input.City, days)
+	if err != nil {
+		output := &GetWeatherOutput{Success: false, Message: "查询失败: " + err.Error()}
+		LogToolCall("getWeather", input, output, err)
+		return output, nil
+	}
+
+	output := &GetWeatherOutput{Success: true, Report: report}
+	LogToolCall("getWeather", input, output, nil)
+	return output, nil
+}
+
+func NewGetWeatherTool() (tool.InvokableTool, error) {
+	return utils.InferTool(
+		"getWeather",
+		"查询城市的实时天气和未来几天的预报。群友问天气、要不要带伞、冷不冷时使用，不要凭空编天气。",
+		getWeatherFunc,
+	)
+}
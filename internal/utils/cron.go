@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule 解析后的 cron 表达式（分 时 日 月 周），精确到分钟
+type CronSchedule struct {
+	fields [5]map[int]bool
+	domAny bool // 日字段为 *
+	dowAny bool // 周字段为 *
+}
+
+// cronRanges 各字段的取值范围
+var cronRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCron 解析标准 5 段 cron 表达式，支持 *、列表（,）、范围（-）和步长（/）
+// 周字段中 0 和 7 都表示周日
+func ParseCron(expr string) (*CronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron 表达式需要 5 段（分 时 日 月 周）: %q", expr)
+	}
+
+	s := &CronSchedule{domAny: parts[2] == "*", dowAny: parts[4] == "*"}
+	for i, part := range parts {
+		set, err := parseCronField(part, cronRanges[i][0], cronRanges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron 表达式第 %d 段无效: %w", i+1, err)
+		}
+		s.fields[i] = set
+	}
+	if s.fields[4][7] {
+		s.fields[4][0] = true
+	}
+	return s, nil
+}
+
+// parseCronField 解析单个字段为取值集合
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if rng, stepStr, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("无效的步长 %q", stepStr)
+			}
+			item, step = rng, n
+		}
+
+		lo, hi := min, max
+		if item != "*" {
+			if a, b, ok := strings.Cut(item, "-"); ok {
+				var err1, err2 error
+				lo, err1 = strconv.Atoi(a)
+				hi, err2 = strconv.Atoi(b)
+				if err1 != nil || err2 != nil {
+					return nil, fmt.Errorf("无效的范围 %q", item)
+				}
+			} else {
+				n, err := strconv.Atoi(item)
+				if err != nil {
+					return nil, fmt.Errorf("无效的值 %q", item)
+				}
+				lo, hi = n, n
+				if step > 1 {
+					hi = max
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("取值超出范围 %d-%d: %q", min, max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// Match 判断某个时刻（精确到分钟）是否满足表达式
+// 与标准 cron 一致：日和周都不是 * 时，满足其一即可
+func (s *CronSchedule) Match(t time.Time) bool {
+	if !s.fields[0][t.Minute()] || !s.fields[1][t.Hour()] || !s.fields[3][int(t.Month())] {
+		return false
+	}
+	dom := s.fields[2][t.Day()]
+	dow := s.fields[4][int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -1,5 +1,13 @@
 package utils
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 // ClampFloat64 将浮点数限制在指定范围内
 func ClampFloat64(value, min, max float64) float64 {
 	if value < min {
@@ -10,3 +18,250 @@ func ClampFloat64(value, min, max float64) float64 {
 	}
 	return value
 }
+
+// ==================== 表达式求值 ====================
+
+// maxExprLen 表达式最大长度
+const maxExprLen = 500
+
+// maxExprDepth 最大嵌套深度，避免恶意输入耗尽栈
+const maxExprDepth = 50
+
+// exprFuncs 表达式中可用的函数
+var exprFuncs = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"log2":  math.Log2,
+	"exp":   math.Exp,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+// exprConsts 表达式中可用的常量
+var exprConsts = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// EvalExpr 计算数学表达式，只支持数字、+ - * / % ^、括号、常量 pi/e 和少量数学函数
+// 自己解析求值，不执行任何代码
+func EvalExpr(expr string) (float64, error) {
+	if len([]rune(expr)) > maxExprLen {
+		return 0, fmt.Errorf("表达式太长")
+	}
+	expr = strings.NewReplacer("×", "*", "÷", "/", "（", "(", "）", ")", "＋", "+", "－", "-", "**", "^").Replace(expr)
+	p := &exprParser{src: []rune(expr)}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return 0, fmt.Errorf("无法识别的字符: %q", p.src[p.pos])
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("结果不是有限数（可能除以了零或超出定义域）")
+	}
+	return v, nil
+}
+
+// exprParser 递归下降解析器
+// expr   = term { (+|-) term }
+// term   = unary { (*|/|%) unary }
+// unary  = (+|-) unary | power
+// power  = atom [ ^ unary ]（右结合）
+// atom   = number | const | func ( expr ) | ( expr )
+type exprParser struct {
+	src   []rune
+	pos   int
+	depth int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek 跳过空白后返回下一个字符，结束时返回 0
+func (p *exprParser) peek() rune {
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) parseExpr() (float64, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return 0, fmt.Errorf("表达式嵌套太深")
+	}
+
+	v, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v += r
+		case '-':
+			p.pos++
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) parseTerm() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return v, nil
+		}
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			v *= r
+		case '/':
+			if r == 0 {
+				return 0, fmt.Errorf("除数不能为零")
+			}
+			v /= r
+		case '%':
+			if r == 0 {
+				return 0, fmt.Errorf("除数不能为零")
+			}
+			v = math.Mod(v, r)
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return 0, fmt.Errorf("表达式嵌套太深")
+	}
+
+	switch p.peek() {
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	case '-':
+		p.pos++
+		v, err := p.parseUnary()
+		return -v, err
+	}
+	return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exp, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exp), nil
+}
+
+func (p *exprParser) parseAtom() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("缺少右括号")
+		}
+		p.pos++
+		return v, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// 科学计数法，如 1e5、2.5E-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			next := p.pos + 1
+			if next < len(p.src) && (p.src[next] == '+' || p.src[next] == '-') {
+				next++
+			}
+			if next < len(p.src) && p.src[next] >= '0' && p.src[next] <= '9' {
+				p.pos = next
+				for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+					p.pos++
+				}
+			}
+		}
+		v, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的数字: %s", string(p.src[start:p.pos]))
+		}
+		return v, nil
+	case unicode.IsLetter(c):
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsLetter(p.src[p.pos]) || unicode.IsDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		name := strings.ToLower(string(p.src[start:p.pos]))
+		if v, ok := exprConsts[name]; ok {
+			return v, nil
+		}
+		fn, ok := exprFuncs[name]
+		if !ok {
+			return 0, fmt.Errorf("不支持的函数或常量: %s", name)
+		}
+		if p.peek() != '(' {
+			return 0, fmt.Errorf("函数 %s 后面需要括号", name)
+		}
+		arg, err := p.parseAtom()
+		if err != nil {
+			return 0, err
+		}
+		return fn(arg), nil
+	case c == 0:
+		return 0, fmt.Errorf("表达式不完整")
+	default:
+		return 0, fmt.Errorf("无法识别的字符: %q", c)
+	}
+}
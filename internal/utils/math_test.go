@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvalExpr(t *testing.T) {
+	cases := []struct {
+		expr string
+		want float64
+	}{
+		// 基本运算和优先级
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"24 / 4 / 2", 3},
+		{"2 * 3 % 4", 2},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"5.5 % 2", 1.5},
+		// 乘方右结合，优先级高于一元负号
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"(-2) ^ 2", 4},
+		{"2 ^ -1", 0.5},
+		{"2 ** 10", 1024},
+		// 一元运算符
+		{"--3", 3},
+		{"+-3", -3},
+		{"3 - -3", 6},
+		// 函数和常量
+		{"sqrt(16)", 4},
+		{"abs(-3.5)", 3.5},
+		{"log(1000)", 3},
+		{"log2(8)", 3},
+		{"ln(e)", 1},
+		{"SQRT(9) + Abs(-1)", 4},
+		{"floor(2.7) + ceil(2.1) + round(2.5)", 8},
+		{"cos(0)", 1},
+		{"2 * pi", 2 * math.Pi},
+		// 科学计数法
+		{"1e3", 1000},
+		{"2.5E-3", 0.0025},
+		{"1.5e+2 + 1", 151},
+		{".5 + .25", 0.75},
+		// 全角符号
+		{"（1＋2）×3", 9},
+		{"8 ÷ 2 － 1", 3},
+	}
+	for _, tc := range cases {
+		got, err := EvalExpr(tc.expr)
+		if err != nil {
+			t.Errorf("EvalExpr(%q) 返回错误: %v", tc.expr, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("EvalExpr(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestEvalExprErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		expr string
+	}{
+		{"除以零", "1 / 0"},
+		{"对零取模", "5 % 0"},
+		{"除以结果为零的表达式", "1 / (2 - 2)"},
+		{"结果为无穷", "ln(0)"},
+		{"超出定义域", "sqrt(-1)"},
+		{"乘方溢出", "10 ^ 400"},
+		{"空表达式", ""},
+		{"表达式不完整", "1 +"},
+		{"缺少右括号", "(1 + 2"},
+		{"多余的右括号", "1 + 2)"},
+		{"未知函数", "foo(1)"},
+		{"函数缺少括号", "sqrt 4"},
+		{"无效数字", "1.2.3"},
+		{"非法字符", "1 + $"},
+		{"代码注入", "os.Exit(1)"},
+		{"嵌套太深", strings.Repeat("(", maxExprDepth) + "1" + strings.Repeat(")", maxExprDepth)},
+		{"一元运算符太多", strings.Repeat("-", maxExprDepth+1) + "1"},
+		{"表达式太长", strings.Repeat("1+", maxExprLen/2) + "1"},
+	}
+	for _, tc := range cases {
+		if v, err := EvalExpr(tc.expr); err == nil {
+			t.Errorf("%s: EvalExpr(%q) = %v, 应该返回错误", tc.name, tc.expr, v)
+		}
+	}
+}
+
+func TestEvalExprLimits(t *testing.T) {
+	// 未超过限制的长表达式和嵌套可以正常计算
+	long := strings.Repeat("1+", (maxExprLen-1)/2) + "1"
+	if v, err := EvalExpr(long); err != nil || v != float64((maxExprLen-1)/2+1) {
+		t.Errorf("长度 %d 的表达式 = %v, %v", len([]rune(long)), v, err)
+	}
+	nested := strings.Repeat("(", 10) + "2" + strings.Repeat(")", 10)
+	if v, err := EvalExpr(nested); err != nil || v != 2 {
+		t.Errorf("嵌套 10 层的表达式 = %v, %v", v, err)
+	}
+}
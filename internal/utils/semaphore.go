@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"container/list"
+	"sync"
+)
+
+// PrioritySemaphore 带优先级的计数信号量
+// 资源不足时排队等待，释放时优先唤醒高优先级的等待者，同优先级按先来后到
+type PrioritySemaphore struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	high    *list.List // 高优先级等待队列（元素为 chan struct{}）
+	low     *list.List // 普通优先级等待队列
+}
+
+// NewPrioritySemaphore 创建信号量，limit <= 0 表示不限制
+func NewPrioritySemaphore(limit int) *PrioritySemaphore {
+	return &PrioritySemaphore{
+		limit: limit,
+		high:  list.New(),
+		low:   list.New(),
+	}
+}
+
+// Acquire 获取一个名额，阻塞直到获取成功或 cancel 被关闭
+// 返回 false 表示等待被取消，此时无需调用 Release
+func (s *PrioritySemaphore) Acquire(highPriority bool, cancel <-chan struct{}) bool {
+	s.mu.Lock()
+	if s.limit <= 0 || (s.running < s.limit && s.high.Len() == 0 && (highPriority || s.low.Len() == 0)) {
+		s.running++
+		s.mu.Unlock()
+		return true
+	}
+
+	ready := make(chan struct{})
+	queue := s.low
+	if highPriority {
+		queue = s.high
+	}
+	elem := queue.PushBack(ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-cancel:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-ready:
+			// 取消的同时已被唤醒，把名额转交给下一个等待者
+			s.running--
+			s.wakeLocked()
+		default:
+			queue.Remove(elem)
+		}
+		return false
+	}
+}
+
+// Release 释放一个名额
+func (s *PrioritySemaphore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limit <= 0 {
+		s.running--
+		return
+	}
+	s.running--
+	s.wakeLocked()
+}
+
+// wakeLocked 在有空闲名额时唤醒等待者（调用方需持有锁）
+func (s *PrioritySemaphore) wakeLocked() {
+	for s.running < s.limit {
+		queue := s.high
+		if queue.Len() == 0 {
+			queue = s.low
+		}
+		front := queue.Front()
+		if front == nil {
+			return
+		}
+		queue.Remove(front)
+		s.running++
+		close(front.Value.(chan struct{}))
+	}
+}
+
+// Running 当前占用的名额数
+func (s *PrioritySemaphore) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Waiting 当前排队等待的数量
+func (s *PrioritySemaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.high.Len() + s.low.Len()
+}
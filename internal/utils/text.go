@@ -0,0 +1,61 @@
+package utils
+
+import "strings"
+
+// LevenshteinDistance 计算两个字符串的编辑距离（按 rune 计算，支持中文）
+func LevenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	// 只保留两行，降低内存占用
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// TextSimilarity 基于编辑距离的文本相似度，范围 [0, 1]，1 表示完全相同
+func TextSimilarity(a, b string) float64 {
+	la, lb := len([]rune(a)), len([]rune(b))
+	maxLen := max(la, lb)
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(LevenshteinDistance(a, b))/float64(maxLen)
+}
+
+// ExtractJSON 从 LLM 输出中提取 JSON 片段（去掉 ```json 代码块等包裹）
+// 返回第一个 '[' 或 '{' 到与之对应类型的最后一个 ']' 或 '}' 之间的内容，找不到时原样返回
+func ExtractJSON(text string) string {
+	start := strings.IndexAny(text, "[{")
+	if start < 0 {
+		return text
+	}
+	closing := "]"
+	if text[start] == '{' {
+		closing = "}"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return text
+	}
+	return text[start : end+1]
+}
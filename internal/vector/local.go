@@ -0,0 +1,229 @@
+package vector
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ Store = (*LocalStore)(nil)
+
+// localVectorRecord 进程内向量索引的持久化记录
+type localVectorRecord struct {
+	MemoryID  uint   `gorm:"primarykey;autoIncrement:false"`
+	GroupID   int64  `gorm:"index"`
+	MemType   string `gorm:"type:varchar(64)"`
+	Embedding []byte // float32 小端序
+}
+
+func (localVectorRecord) TableName() string { return "memory_embeddings" }
+
+// localVector 内存中的向量（已归一化，余弦相似度即点积）
+type localVector struct {
+	groupID int64
+	memType string
+	vec     []float32
+}
+
+// LocalStore 进程内的简易向量索引：向量持久化在数据库中，启动时全部加载到内存做暴力余弦检索
+// 适合中小数据量，在没有部署向量数据库时提供语义检索兜底
+type LocalStore struct {
+	db      *gorm.DB
+	mu      sync.RWMutex
+	vectors map[uint]*localVector
+}
+
+// NewLocalStore 创建进程内向量索引并加载已有向量
+func NewLocalStore(db *gorm.DB) (*LocalStore, error) {
+	if err := db.AutoMigrate(&localVectorRecord{}); err != nil {
+		return nil, fmt.Errorf("创建向量表失败: %w", err)
+	}
+
+	s := &LocalStore{db: db, vectors: make(map[uint]*localVector)}
+	var batch []localVectorRecord
+	err := db.FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for _, r := range batch {
+			s.vectors[r.MemoryID] = &localVector{
+				groupID: r.GroupID,
+				memType: r.MemType,
+				vec:     DecodeVector(r.Embedding),
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("加载向量失败: %w", err)
+	}
+	return s, nil
+}
+
+// Len 已加载的向量数
+func (s *LocalStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.vectors)
+}
+
+// Insert 插入向量（同 ID 覆盖）
+func (s *LocalStore) Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) error {
+	return s.InsertBatch(ctx, []MemoryVector{{
+		MemoryID:  memoryID,
+		GroupID:   groupID,
+		MemType:   memType,
+		Embedding: ToFloat32(embedding),
+	}})
+}
+
+// InsertBatch 批量插入向量（同 ID 覆盖）
+func (s *LocalStore) InsertBatch(ctx context.Context, vectors []MemoryVector) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	records := make([]localVectorRecord, 0, len(vectors))
+	for _, v := range vectors {
+		records = append(records, localVectorRecord{
+			MemoryID:  v.MemoryID,
+			GroupID:   v.GroupID,
+			MemType:   v.MemType,
+			Embedding: EncodeVector(v.Embedding),
+		})
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&records).Error; err != nil {
+		return fmt.Errorf("插入向量失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range vectors {
+		s.vectors[v.MemoryID] = &localVector{
+			groupID: v.GroupID,
+			memType: v.MemType,
+			vec:     Normalize(v.Embedding),
+		}
+	}
+	return nil
+}
+
+// Search 暴力计算余弦相似度
+func (s *LocalStore) Search(ctx context.Context, embedding []float64, groupID int64, memType string, topK int, threshold float64) ([]SearchResult, error) {
+	query := Normalize(ToFloat32(embedding))
+
+	s.mu.RLock()
+	var results []SearchResult
+	for id, v := range s.vectors {
+		if groupID != 0 && v.groupID != groupID {
+			continue
+		}
+		if memType != "" && v.memType != memType {
+			continue
+		}
+		if len(v.vec) != len(query) {
+			continue
+		}
+		var score float32
+		for i := range query {
+			score += query[i] * v.vec[i]
+		}
+		if float64(score) >= threshold {
+			results = append(results, SearchResult{MemoryID: id, Score: score})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// Delete 删除向量
+func (s *LocalStore) Delete(ctx context.Context, memoryIDs []uint) error {
+	if len(memoryIDs) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Where("memory_id IN ?", memoryIDs).Delete(&localVectorRecord{}).Error; err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range memoryIDs {
+		delete(s.vectors, id)
+	}
+	return nil
+}
+
+// DeleteByGroup 按群删除向量
+func (s *LocalStore) DeleteByGroup(ctx context.Context, groupID int64) error {
+	if err := s.db.WithContext(ctx).Where("group_id = ?", groupID).Delete(&localVectorRecord{}).Error; err != nil {
+		return fmt.Errorf("按群删除向量失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, v := range s.vectors {
+		if v.groupID == groupID {
+			delete(s.vectors, id)
+		}
+	}
+	return nil
+}
+
+// Reset 清空全部向量
+func (s *LocalStore) Reset(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&localVectorRecord{}).Error; err != nil {
+		return fmt.Errorf("清空向量失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors = make(map[uint]*localVector)
+	return nil
+}
+
+// Close 与记忆共用数据库连接，由记忆管理器关闭
+func (s *LocalStore) Close() error {
+	return nil
+}
+
+// Normalize 归一化向量
+func Normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(vec))
+	if norm == 0 {
+		return out
+	}
+	for i, v := range vec {
+		out[i] = v / norm
+	}
+	return out
+}
+
+// EncodeVector 编码向量为字节
+func EncodeVector(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// DecodeVector 解码字节为归一化向量
+func DecodeVector(buf []byte) []float32 {
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return Normalize(vec)
+}
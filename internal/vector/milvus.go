@@ -19,6 +19,8 @@ type MilvusConfig struct {
 	MetricType     string `yaml:"metric_type"` // IP, L2, COSINE
 }
 
+var _ Store = (*MilvusClient)(nil)
+
 // MilvusClient Milvus 向量存储客户端
 type MilvusClient struct {
 	client         *milvusclient.Client
@@ -26,15 +28,6 @@ type MilvusClient struct {
 	collectionName string
 }
 
-// MemoryVector 记忆向量结构
-type MemoryVector struct {
-	ID        int64     `json:"id"`
-	MemoryID  uint      `json:"memory_id"`
-	GroupID   int64     `json:"group_id"`
-	MemType   string    `json:"mem_type"`
-	Embedding []float32 `json:"embedding"`
-}
-
 // NewMilvusClient 创建 Milvus 客户端
 func NewMilvusClient(cfg *MilvusConfig) (*MilvusClient, error) {
 	if cfg.Address == "" {
@@ -144,12 +137,8 @@ func (c *MilvusClient) initCollection(ctx context.Context) error {
 }
 
 // Insert 插入向量
-func (c *MilvusClient) Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) (int64, error) {
-	// 转换 float64 到 float32
-	emb32 := make([]float32, len(embedding))
-	for i, v := range embedding {
-		emb32[i] = float32(v)
-	}
+func (c *MilvusClient) Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) error {
+	emb32 := ToFloat32(embedding)
 
 	// 准备数据
 	memoryIDCol := column.NewColumnInt64("memory_id", []int64{int64(memoryID)})
@@ -158,33 +147,44 @@ func (c *MilvusClient) Insert(ctx context.Context, memoryID uint, groupID int64,
 	embeddingCol := column.NewColumnFloatVector("embedding", c.cfg.VectorDim, [][]float32{emb32})
 
 	// 插入
-	result, err := c.client.Insert(ctx, milvusclient.NewColumnBasedInsertOption(c.collectionName, memoryIDCol, groupIDCol, memTypeCol, embeddingCol))
-	if err != nil {
-		return 0, fmt.Errorf("插入向量失败: %w", err)
+	if _, err := c.client.Insert(ctx, milvusclient.NewColumnBasedInsertOption(c.collectionName, memoryIDCol, groupIDCol, memTypeCol, embeddingCol)); err != nil {
+		return fmt.Errorf("插入向量失败: %w", err)
 	}
+	return nil
+}
 
-	// 返回插入的 ID
-	if result.IDs != nil {
-		if ids, ok := result.IDs.(*column.ColumnInt64); ok && ids.Len() > 0 {
-			return ids.Data()[0], nil
-		}
+// InsertBatch 批量插入向量
+func (c *MilvusClient) InsertBatch(ctx context.Context, vectors []MemoryVector) error {
+	if len(vectors) == 0 {
+		return nil
 	}
-	return 0, nil
-}
 
-// SearchResult 搜索结果
-type SearchResult struct {
-	MemoryID uint    `json:"memory_id"`
-	Score    float32 `json:"score"`
+	memoryIDs := make([]int64, 0, len(vectors))
+	groupIDs := make([]int64, 0, len(vectors))
+	memTypes := make([]string, 0, len(vectors))
+	embeddings := make([][]float32, 0, len(vectors))
+	for _, v := range vectors {
+		memoryIDs = append(memoryIDs, int64(v.MemoryID))
+		groupIDs = append(groupIDs, v.GroupID)
+		memTypes = append(memTypes, v.MemType)
+		embeddings = append(embeddings, v.Embedding)
+	}
+
+	_, err := c.client.Insert(ctx, milvusclient.NewColumnBasedInsertOption(c.collectionName,
+		column.NewColumnInt64("memory_id", memoryIDs),
+		column.NewColumnInt64("group_id", groupIDs),
+		column.NewColumnVarChar("mem_type", memTypes),
+		column.NewColumnFloatVector("embedding", c.cfg.VectorDim, embeddings),
+	))
+	if err != nil {
+		return fmt.Errorf("批量插入向量失败: %w", err)
+	}
+	return nil
 }
 
 // Search 向量搜索
 func (c *MilvusClient) Search(ctx context.Context, embedding []float64, groupID int64, memType string, topK int, threshold float64) ([]SearchResult, error) {
-	// 转换 float64 到 float32
-	emb32 := make([]float32, len(embedding))
-	for i, v := range embedding {
-		emb32[i] = float32(v)
-	}
+	emb32 := ToFloat32(embedding)
 
 	// 构建过滤条件
 	var filterParts []string
@@ -274,6 +274,14 @@ func (c *MilvusClient) DeleteByGroup(ctx context.Context, groupID int64) error {
 	return nil
 }
 
+// Reset 删除并重建集合（更换 embedding 模型、维度变化时使用）
+func (c *MilvusClient) Reset(ctx context.Context) error {
+	if err := c.client.DropCollection(ctx, milvusclient.NewDropCollectionOption(c.collectionName)); err != nil {
+		return fmt.Errorf("删除集合失败: %w", err)
+	}
+	return c.initCollection(ctx)
+}
+
 // Close 关闭连接
 func (c *MilvusClient) Close() error {
 	return c.client.Close(context.Background())
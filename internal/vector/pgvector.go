@@ -0,0 +1,181 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var _ Store = (*PGVectorStore)(nil)
+
+// PGVectorConfig pgvector 配置
+type PGVectorConfig struct {
+	TableName string `yaml:"table_name"`
+	VectorDim int    `yaml:"vector_dim"`
+}
+
+// PGVectorStore 基于 PostgreSQL pgvector 扩展的向量存储，与记忆共用同一个数据库
+type PGVectorStore struct {
+	db  *gorm.DB
+	cfg *PGVectorConfig
+}
+
+// NewPGVectorStore 创建 pgvector 向量存储（需要 PostgreSQL 已安装 vector 扩展）
+func NewPGVectorStore(db *gorm.DB, cfg *PGVectorConfig) (*PGVectorStore, error) {
+	if db.Dialector.Name() != "postgres" {
+		return nil, fmt.Errorf("pgvector 需要使用 postgres 数据库驱动")
+	}
+	if cfg.TableName == "" {
+		cfg.TableName = "memory_vectors"
+	}
+	if cfg.VectorDim == 0 {
+		cfg.VectorDim = 1024
+	}
+
+	s := &PGVectorStore{db: db, cfg: cfg}
+	if err := s.initTable(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// initTable 启用扩展并创建向量表和索引
+func (s *PGVectorStore) initTable(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		return fmt.Errorf("启用 vector 扩展失败: %w", err)
+	}
+	createTable := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	memory_id BIGINT PRIMARY KEY,
+	group_id BIGINT NOT NULL,
+	mem_type VARCHAR(64) NOT NULL,
+	embedding vector(%d) NOT NULL
+)`, s.cfg.TableName, s.cfg.VectorDim)
+	if err := db.Exec(createTable).Error; err != nil {
+		return fmt.Errorf("创建向量表失败: %w", err)
+	}
+	createIndex := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_embedding ON %s USING hnsw (embedding vector_cosine_ops)",
+		s.cfg.TableName, s.cfg.TableName)
+	if err := db.Exec(createIndex).Error; err != nil {
+		return fmt.Errorf("创建向量索引失败: %w", err)
+	}
+	return nil
+}
+
+// Insert 插入向量（同 ID 覆盖）
+func (s *PGVectorStore) Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) error {
+	return s.InsertBatch(ctx, []MemoryVector{{
+		MemoryID:  memoryID,
+		GroupID:   groupID,
+		MemType:   memType,
+		Embedding: ToFloat32(embedding),
+	}})
+}
+
+// InsertBatch 批量插入向量（同 ID 覆盖）
+func (s *PGVectorStore) InsertBatch(ctx context.Context, vectors []MemoryVector) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(vectors))
+	args := make([]any, 0, len(vectors)*4)
+	for _, v := range vectors {
+		placeholders = append(placeholders, "(?, ?, ?, ?::vector)")
+		args = append(args, v.MemoryID, v.GroupID, v.MemType, vectorLiteral(v.Embedding))
+	}
+	sql := fmt.Sprintf(`INSERT INTO %s (memory_id, group_id, mem_type, embedding) VALUES %s
+ON CONFLICT (memory_id) DO UPDATE SET group_id = EXCLUDED.group_id, mem_type = EXCLUDED.mem_type, embedding = EXCLUDED.embedding`,
+		s.cfg.TableName, strings.Join(placeholders, ", "))
+	if err := s.db.WithContext(ctx).Exec(sql, args...).Error; err != nil {
+		return fmt.Errorf("插入向量失败: %w", err)
+	}
+	return nil
+}
+
+// Search 向量搜索（余弦相似度）
+func (s *PGVectorStore) Search(ctx context.Context, embedding []float64, groupID int64, memType string, topK int, threshold float64) ([]SearchResult, error) {
+	query := vectorLiteral(ToFloat32(embedding))
+	var conds []string
+	args := []any{query}
+	if groupID != 0 {
+		conds = append(conds, "group_id = ?")
+		args = append(args, groupID)
+	}
+	if memType != "" {
+		conds = append(conds, "mem_type = ?")
+		args = append(args, memType)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, query, topK)
+
+	// <=> 是余弦距离，相似度 = 1 - 距离；按距离排序才能用上 HNSW 索引
+	sql := fmt.Sprintf("SELECT memory_id, 1 - (embedding <=> ?::vector) AS score FROM %s %s ORDER BY embedding <=> ?::vector LIMIT ?",
+		s.cfg.TableName, where)
+
+	var rows []SearchResult
+	if err := s.db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("向量搜索失败: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(rows))
+	for _, r := range rows {
+		if float64(r.Score) >= threshold {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// Delete 删除向量
+func (s *PGVectorStore) Delete(ctx context.Context, memoryIDs []uint) error {
+	if len(memoryIDs) == 0 {
+		return nil
+	}
+	sql := fmt.Sprintf("DELETE FROM %s WHERE memory_id IN ?", s.cfg.TableName)
+	if err := s.db.WithContext(ctx).Exec(sql, memoryIDs).Error; err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteByGroup 按群删除向量
+func (s *PGVectorStore) DeleteByGroup(ctx context.Context, groupID int64) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE group_id = ?", s.cfg.TableName)
+	if err := s.db.WithContext(ctx).Exec(sql, groupID).Error; err != nil {
+		return fmt.Errorf("按群删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// Reset 删除并重建向量表
+func (s *PGVectorStore) Reset(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Exec("DROP TABLE IF EXISTS " + s.cfg.TableName).Error; err != nil {
+		return fmt.Errorf("删除向量表失败: %w", err)
+	}
+	return s.initTable(ctx)
+}
+
+// Close 与记忆共用数据库连接，由记忆管理器关闭
+func (s *PGVectorStore) Close() error {
+	return nil
+}
+
+// vectorLiteral 生成 pgvector 的文本表示，如 [0.1,0.2]
+func vectorLiteral(embedding []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range embedding {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
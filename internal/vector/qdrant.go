@@ -0,0 +1,233 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+var _ Store = (*QdrantClient)(nil)
+
+// QdrantConfig Qdrant 配置
+type QdrantConfig struct {
+	Address        string `yaml:"address"` // REST 地址，如 http://localhost:6333
+	APIKey         string `yaml:"api_key"`
+	CollectionName string `yaml:"collection_name"`
+	VectorDim      int    `yaml:"vector_dim"`
+	Distance       string `yaml:"distance"` // Cosine, Dot, Euclid
+}
+
+// QdrantClient Qdrant 向量存储客户端（REST API），以记忆 ID 作为点 ID
+type QdrantClient struct {
+	cfg    *QdrantConfig
+	client *http.Client
+}
+
+// NewQdrantClient 创建 Qdrant 客户端
+func NewQdrantClient(cfg *QdrantConfig) (*QdrantClient, error) {
+	if cfg.Address == "" {
+		cfg.Address = "http://localhost:6333"
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	if cfg.CollectionName == "" {
+		cfg.CollectionName = "mumu_memories"
+	}
+	if cfg.VectorDim == 0 {
+		cfg.VectorDim = 1024
+	}
+	if cfg.Distance == "" {
+		cfg.Distance = "Cosine"
+	}
+
+	qc := &QdrantClient{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	if err := qc.initCollection(context.Background()); err != nil {
+		return nil, err
+	}
+	return qc, nil
+}
+
+// initCollection 集合不存在时创建
+func (c *QdrantClient) initCollection(ctx context.Context) error {
+	status, err := c.do(ctx, http.MethodGet, "/collections/"+c.cfg.CollectionName, nil, nil)
+	if err == nil {
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return fmt.Errorf("检查集合存在失败: %w", err)
+	}
+
+	body := map[string]any{
+		"vectors": map[string]any{
+			"size":     c.cfg.VectorDim,
+			"distance": c.cfg.Distance,
+		},
+	}
+	if _, err := c.do(ctx, http.MethodPut, "/collections/"+c.cfg.CollectionName, body, nil); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	// 为过滤字段建立索引
+	for field, schema := range map[string]string{"group_id": "integer", "mem_type": "keyword"} {
+		index := map[string]any{"field_name": field, "field_schema": schema}
+		if _, err := c.do(ctx, http.MethodPut, "/collections/"+c.cfg.CollectionName+"/index?wait=true", index, nil); err != nil {
+			return fmt.Errorf("创建索引失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Insert 插入向量（同 ID 覆盖）
+func (c *QdrantClient) Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) error {
+	return c.InsertBatch(ctx, []MemoryVector{{
+		MemoryID:  memoryID,
+		GroupID:   groupID,
+		MemType:   memType,
+		Embedding: ToFloat32(embedding),
+	}})
+}
+
+// InsertBatch 批量插入向量（同 ID 覆盖）
+func (c *QdrantClient) InsertBatch(ctx context.Context, vectors []MemoryVector) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	points := make([]map[string]any, 0, len(vectors))
+	for _, v := range vectors {
+		points = append(points, map[string]any{
+			"id":     v.MemoryID,
+			"vector": v.Embedding,
+			"payload": map[string]any{
+				"memory_id": v.MemoryID,
+				"group_id":  v.GroupID,
+				"mem_type":  v.MemType,
+			},
+		})
+	}
+	if _, err := c.do(ctx, http.MethodPut, "/collections/"+c.cfg.CollectionName+"/points?wait=true", map[string]any{"points": points}, nil); err != nil {
+		return fmt.Errorf("插入向量失败: %w", err)
+	}
+	return nil
+}
+
+// Search 向量搜索
+func (c *QdrantClient) Search(ctx context.Context, embedding []float64, groupID int64, memType string, topK int, threshold float64) ([]SearchResult, error) {
+	var must []map[string]any
+	if groupID != 0 {
+		must = append(must, map[string]any{"key": "group_id", "match": map[string]any{"value": groupID}})
+	}
+	if memType != "" {
+		must = append(must, map[string]any{"key": "mem_type", "match": map[string]any{"value": memType}})
+	}
+
+	body := map[string]any{
+		"vector":          ToFloat32(embedding),
+		"limit":           topK,
+		"score_threshold": threshold,
+	}
+	if len(must) > 0 {
+		body["filter"] = map[string]any{"must": must}
+	}
+
+	var resp struct {
+		Result []struct {
+			ID    uint    `json:"id"`
+			Score float32 `json:"score"`
+		} `json:"result"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/collections/"+c.cfg.CollectionName+"/points/search", body, &resp); err != nil {
+		return nil, fmt.Errorf("向量搜索失败: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Result))
+	for _, r := range resp.Result {
+		results = append(results, SearchResult{MemoryID: r.ID, Score: r.Score})
+	}
+	return results, nil
+}
+
+// Delete 删除向量
+func (c *QdrantClient) Delete(ctx context.Context, memoryIDs []uint) error {
+	if len(memoryIDs) == 0 {
+		return nil
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/collections/"+c.cfg.CollectionName+"/points/delete?wait=true", map[string]any{"points": memoryIDs}, nil); err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteByGroup 按群删除向量
+func (c *QdrantClient) DeleteByGroup(ctx context.Context, groupID int64) error {
+	body := map[string]any{
+		"filter": map[string]any{
+			"must": []map[string]any{{"key": "group_id", "match": map[string]any{"value": groupID}}},
+		},
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/collections/"+c.cfg.CollectionName+"/points/delete?wait=true", body, nil); err != nil {
+		return fmt.Errorf("按群删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// Reset 删除并重建集合
+func (c *QdrantClient) Reset(ctx context.Context) error {
+	if _, err := c.do(ctx, http.MethodDelete, "/collections/"+c.cfg.CollectionName, nil, nil); err != nil {
+		return fmt.Errorf("删除集合失败: %w", err)
+	}
+	return c.initCollection(ctx)
+}
+
+// Close 关闭连接
+func (c *QdrantClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// do 发送 REST 请求，out 不为空时解析响应；返回 HTTP 状态码
+func (c *QdrantClient) do(ctx context.Context, method, path string, body, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := sonic.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.Address+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("api-key", c.cfg.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := sonic.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
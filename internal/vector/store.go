@@ -0,0 +1,45 @@
+package vector
+
+import "context"
+
+// Store 向量存储接口，记忆 ID 作为向量的唯一标识
+type Store interface {
+	// Insert 插入单条记忆向量
+	Insert(ctx context.Context, memoryID uint, groupID int64, memType string, embedding []float64) error
+	// InsertBatch 批量插入记忆向量
+	InsertBatch(ctx context.Context, vectors []MemoryVector) error
+	// Search 搜索相似向量，groupID 为 0、memType 为空时不过滤，只返回相似度不低于 threshold 的结果
+	Search(ctx context.Context, embedding []float64, groupID int64, memType string, topK int, threshold float64) ([]SearchResult, error)
+	// Delete 按记忆 ID 删除向量
+	Delete(ctx context.Context, memoryIDs []uint) error
+	// DeleteByGroup 删除某个群的全部向量
+	DeleteByGroup(ctx context.Context, groupID int64) error
+	// Reset 清空并重建存储
+	Reset(ctx context.Context) error
+	// Close 关闭连接
+	Close() error
+}
+
+// MemoryVector 记忆向量结构
+type MemoryVector struct {
+	ID        int64     `json:"id"`
+	MemoryID  uint      `json:"memory_id"`
+	GroupID   int64     `json:"group_id"`
+	MemType   string    `json:"mem_type"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	MemoryID uint    `json:"memory_id"`
+	Score    float32 `json:"score"`
+}
+
+// ToFloat32 转换 float64 向量到 float32
+func ToFloat32(embedding []float64) []float32 {
+	emb32 := make([]float32, len(embedding))
+	for i, v := range embedding {
+		emb32[i] = float32(v)
+	}
+	return emb32
+}
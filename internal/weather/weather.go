@@ -0,0 +1,313 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mumu-bot/internal/config"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// Current 实时天气
+type Current struct {
+	Text      string  `json:"text"`       // 天气现象，如晴、小雨
+	Temp      float64 `json:"temp"`       // 温度（℃）
+	FeelsLike float64 `json:"feels_like"` // 体感温度（℃）
+	Humidity  int     `json:"humidity"`   // 相对湿度（%）
+	Wind      string  `json:"wind,omitempty"`
+}
+
+// Day 逐日预报
+type Day struct {
+	Date      string  `json:"date"` // 2006-01-02
+	TextDay   string  `json:"text_day"`
+	TextNight string  `json:"text_night,omitempty"`
+	TempMin   float64 `json:"temp_min"`
+	TempMax   float64 `json:"temp_max"`
+	Precip    string  `json:"precip,omitempty"` // 降水量或降水概率
+}
+
+// Report 一次天气查询的结果
+type Report struct {
+	City  string   `json:"city"`
+	Now   *Current `json:"now,omitempty"`
+	Daily []Day    `json:"daily,omitempty"`
+}
+
+// Client 天气查询客户端，支持和风天气和 OpenWeather
+type Client struct {
+	cfg  *config.WeatherConfig
+	http *http.Client
+}
+
+// NewClient 创建天气查询客户端，未启用时返回 nil
+func NewClient(cfg *config.WeatherConfig) (*Client, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("未配置天气 API Key")
+	}
+	switch cfg.Provider {
+	case "", "qweather", "openweather":
+	default:
+		return nil, fmt.Errorf("不支持的天气服务: %s", cfg.Provider)
+	}
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Query 查询城市的实时天气和未来 days 天预报（含今天）
+func (c *Client) Query(ctx context.Context, city string, days int) (*Report, error) {
+	if city == "" {
+		city = c.cfg.DefaultCity
+	}
+	if city == "" {
+		return nil, fmt.Errorf("没有指定城市")
+	}
+	if c.cfg.Provider == "openweather" {
+		return c.queryOpenWeather(ctx, city, days)
+	}
+	return c.queryQWeather(ctx, city, days)
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func (c *Client) getJSON(ctx context.Context, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// *url.Error 的文本包含完整 URL（含 API Key），只保留底层错误
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("请求天气服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("天气服务返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 200)])))
+	}
+	return sonic.Unmarshal(body, out)
+}
+
+// ==================== 和风天气 ====================
+
+// qweatherURL 拼接和风天气接口地址，配置了专属 API Host 时地理和天气接口都走它
+func (c *Client) qweatherURL(path string, params url.Values) string {
+	params.Set("key", c.cfg.APIKey)
+	if c.cfg.APIHost != "" {
+		host := strings.TrimSuffix(strings.TrimPrefix(c.cfg.APIHost, "https://"), "/")
+		if strings.HasPrefix(path, "/v2/city") {
+			path = "/geo" + path
+		}
+		return "https://" + host + path + "?" + params.Encode()
+	}
+	if strings.HasPrefix(path, "/v2/city") {
+		return "https://geoapi.qweather.com" + path + "?" + params.Encode()
+	}
+	return "https://devapi.qweather.com" + path + "?" + params.Encode()
+}
+
+func (c *Client) queryQWeather(ctx context.Context, city string, days int) (*Report, error) {
+	var geo struct {
+		Code     string `json:"code"`
+		Location []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			Adm1 string `json:"adm1"`
+		} `json:"location"`
+	}
+	if err := c.getJSON(ctx, c.qweatherURL("/v2/city/lookup", url.Values{"location": {city}, "number": {"1"}}), &geo); err != nil {
+		return nil, err
+	}
+	if geo.Code != "200" || len(geo.Location) == 0 {
+		return nil, fmt.Errorf("找不到城市: %s", city)
+	}
+	loc := geo.Location[0]
+	report := &Report{City: loc.Name}
+	if loc.Adm1 != "" && loc.Adm1 != loc.Name {
+		report.City = loc.Adm1 + loc.Name
+	}
+
+	var now struct {
+		Code string `json:"code"`
+		Now  struct {
+			Text      string `json:"text"`
+			Temp      string `json:"temp"`
+			FeelsLike string `json:"feelsLike"`
+			Humidity  string `json:"humidity"`
+			WindDir   string `json:"windDir"`
+			WindScale string `json:"windScale"`
+		} `json:"now"`
+	}
+	if err := c.getJSON(ctx, c.qweatherURL("/v7/weather/now", url.Values{"location": {loc.ID}}), &now); err == nil && now.Code == "200" {
+		report.Now = &Current{
+			Text:      now.Now.Text,
+			Temp:      parseFloat(now.Now.Temp),
+			FeelsLike: parseFloat(now.Now.FeelsLike),
+			Humidity:  int(parseFloat(now.Now.Humidity)),
+			Wind:      now.Now.WindDir + now.Now.WindScale + "级",
+		}
+	}
+
+	var daily struct {
+		Code  string `json:"code"`
+		Daily []struct {
+			FxDate    string `json:"fxDate"`
+			TextDay   string `json:"textDay"`
+			TextNight string `json:"textNight"`
+			TempMax   string `json:"tempMax"`
+			TempMin   string `json:"tempMin"`
+			Precip    string `json:"precip"`
+		} `json:"daily"`
+	}
+	if err := c.getJSON(ctx, c.qweatherURL("/v7/weather/3d", url.Values{"location": {loc.ID}}), &daily); err != nil {
+		return nil, err
+	}
+	if daily.Code != "200" {
+		return nil, fmt.Errorf("和风天气返回错误码 %s", daily.Code)
+	}
+	for i, d := range daily.Daily {
+		if i >= days {
+			break
+		}
+		report.Daily = append(report.Daily, Day{
+			Date:      d.FxDate,
+			TextDay:   d.TextDay,
+			TextNight: d.TextNight,
+			TempMin:   parseFloat(d.TempMin),
+			TempMax:   parseFloat(d.TempMax),
+			Precip:    d.Precip + "mm",
+		})
+	}
+	return report, nil
+}
+
+// ==================== OpenWeather ====================
+
+func (c *Client) openWeatherURL(path, city string) string {
+	params := url.Values{
+		"q":     {city},
+		"appid": {c.cfg.APIKey},
+		"units": {"metric"},
+		"lang":  {"zh_cn"},
+	}
+	return "https://api.openweathermap.org/data/2.5" + path + "?" + params.Encode()
+}
+
+type openWeatherDesc struct {
+	Description string `json:"description"`
+}
+
+func (c *Client) queryOpenWeather(ctx context.Context, city string, days int) (*Report, error) {
+	var now struct {
+		Name    string            `json:"name"`
+		Weather []openWeatherDesc `json:"weather"`
+		Main    struct {
+			Temp      float64 `json:"temp"`
+			FeelsLike float64 `json:"feels_like"`
+			Humidity  int     `json:"humidity"`
+		} `json:"main"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+		} `json:"wind"`
+	}
+	if err := c.getJSON(ctx, c.openWeatherURL("/weather", city), &now); err != nil {
+		return nil, err
+	}
+	report := &Report{City: now.Name, Now: &Current{
+		Temp:      now.Main.Temp,
+		FeelsLike: now.Main.FeelsLike,
+		Humidity:  now.Main.Humidity,
+		Wind:      fmt.Sprintf("风速%.1fm/s", now.Wind.Speed),
+	}}
+	if len(now.Weather) > 0 {
+		report.Now.Text = now.Weather[0].Description
+	}
+
+	// 5 天 / 3 小时预报，按当地日期汇总成逐日预报
+	var forecast struct {
+		List []struct {
+			Dt   int64 `json:"dt"`
+			Main struct {
+				TempMin float64 `json:"temp_min"`
+				TempMax float64 `json:"temp_max"`
+			} `json:"main"`
+			Weather []openWeatherDesc `json:"weather"`
+			Pop     float64           `json:"pop"`
+		} `json:"list"`
+		City struct {
+			Timezone int `json:"timezone"`
+		} `json:"city"`
+	}
+	if err := c.getJSON(ctx, c.openWeatherURL("/forecast", city), &forecast); err != nil {
+		return nil, err
+	}
+	zone := time.FixedZone("", forecast.City.Timezone)
+
+	type dayAgg struct {
+		day   Day
+		pop   float64
+		descs map[string]int
+	}
+	aggs := make(map[string]*dayAgg)
+	for _, item := range forecast.List {
+		date := time.Unix(item.Dt, 0).In(zone).Format("2006-01-02")
+		agg, ok := aggs[date]
+		if !ok {
+			agg = &dayAgg{day: Day{Date: date, TempMin: item.Main.TempMin, TempMax: item.Main.TempMax}, descs: make(map[string]int)}
+			aggs[date] = agg
+		}
+		agg.day.TempMin = min(agg.day.TempMin, item.Main.TempMin)
+		agg.day.TempMax = max(agg.day.TempMax, item.Main.TempMax)
+		agg.pop = max(agg.pop, item.Pop)
+		if len(item.Weather) > 0 {
+			agg.descs[item.Weather[0].Description]++
+		}
+	}
+
+	dates := make([]string, 0, len(aggs))
+	for date := range aggs {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for i, date := range dates {
+		if i >= days {
+			break
+		}
+		agg := aggs[date]
+		best := 0
+		for desc, n := range agg.descs {
+			if n > best || (n == best && desc < agg.day.TextDay) {
+				best, agg.day.TextDay = n, desc
+			}
+		}
+		agg.day.Precip = fmt.Sprintf("降水概率%.0f%%", agg.pop*100)
+		report.Daily = append(report.Daily, agg.day)
+	}
+	return report, nil
+}
+
+// parseFloat 解析接口返回的数字字符串，失败时返回 0
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -18,13 +18,19 @@ import (
 )
 
 func main() {
+	configPath := "config/config.yaml"
+
+	// 命令行子命令（导出/导入等），执行完直接退出
+	if code := runCLI(configPath, os.Args[1:]); code >= 0 {
+		os.Exit(code)
+	}
+
 	fmt.Println("=================================")
 	fmt.Println("    沐沐 - 赛博QQ群友 v2.0")
 	fmt.Println("      (powered by Eino)")
 	fmt.Println("=================================")
 
 	// 加载配置
-	configPath := "config/config.yaml"
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		fmt.Printf("加载配置失败: %v\n", err)
@@ -91,7 +97,7 @@ func main() {
 	amuAgent.Start()
 
 	// 启动HTTP服务（用于健康检查等）
-	httpServer := server.NewServer(cfg, memoryMgr)
+	httpServer := server.NewServer(cfg, memoryMgr, amuAgent)
 	go httpServer.Start()
 
 	// 等待退出信号